@@ -83,6 +83,12 @@ func processQueryType(rh *RequestHandler, qTypeStr string) map[string]interface{
 		answer, hError = rh.ProcessIsAuthorized()
 	case GetSeed:
 		answer, hError = rh.ProcessGetSeed()
+	case GetEvents:
+		answer, hError = rh.ProcessGetEvents()
+	case DeployContract:
+		answer, hError = rh.ProcessDeployContract()
+	case GetABI:
+		answer, hError = rh.ProcessGetABI()
 	default:
 		msg := fmt.Sprintf("Wrong query parameter 'query_type' = '%s'", qTypeStr)
 		answer = writeError(msg, BadRequest)
@@ -94,6 +100,9 @@ func processQueryType(rh *RequestHandler, qTypeStr string) map[string]interface{
 		log.Errorf("[QID=%s] %s\n", rh.qid, errMsg)
 		answer = writeError(errMsg, HandlerError)
 	}
+	if usage := rh.usageAnswer(); usage != nil {
+		answer["usage"] = usage
+	}
 
 	return answer
 }
@@ -161,7 +170,14 @@ func wrapAPIV1Handler(runner *Runner, rootDomainReference core.RecordRef) func(w
 			log.Errorf("[QID=] Can't parse input request: %s, error: %s\n", req.RequestURI, err)
 			return
 		}
-		rh := NewRequestHandler(params, runner.messageBus, runner.netCoordinator, rootDomainReference, sm)
+		ctx := req.Context()
+		if runner.cfg.CallTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(runner.cfg.CallTimeout)*time.Millisecond)
+			defer cancel()
+		}
+		rh := NewRequestHandler(ctx, params, runner.messageBus, runner.artifactManager, runner.netCoordinator, rootDomainReference, sm)
+		rh.deployers = runner.cfg.Deployers
 
 		answer = processQueryType(rh, params.QType)
 	}
@@ -169,12 +185,13 @@ func wrapAPIV1Handler(runner *Runner, rootDomainReference core.RecordRef) func(w
 
 // Runner implements Component for API
 type Runner struct {
-	messageBus     core.MessageBus
-	server         *http.Server
-	cfg            *configuration.APIRunner
-	netCoordinator core.NetworkCoordinator
-	keyCache       map[string]string
-	cacheLock      *sync.RWMutex
+	messageBus      core.MessageBus
+	artifactManager core.ArtifactManager
+	server          *http.Server
+	cfg             *configuration.APIRunner
+	netCoordinator  core.NetworkCoordinator
+	keyCache        map[string]string
+	cacheLock       *sync.RWMutex
 }
 
 // NewRunner is C-tor for API Runner
@@ -212,6 +229,9 @@ func (ar *Runner) reloadMessageBus(c core.Components) {
 func (ar *Runner) Start(c core.Components) error {
 
 	ar.reloadMessageBus(c)
+	if c.Ledger != nil {
+		ar.artifactManager = c.Ledger.GetArtifactManager()
+	}
 
 	rootDomainReference := c.Bootstrapper.GetRootDomainRef()
 	ar.netCoordinator = c.NetworkCoordinator
@@ -243,16 +263,19 @@ func (ar *Runner) Stop() error {
 	return nil
 }
 
-func (ar *Runner) getMemberPubKey(ref string) string { //nolint
+func (ar *Runner) getMemberPubKey(ref string) (string, error) { //nolint
 	ar.cacheLock.RLock()
 	key, ok := ar.keyCache[ref]
 	ar.cacheLock.RUnlock()
 	if ok {
-		return key
+		return key, nil
+	}
+	key, err := member.GetObject(core.NewRefFromBase58(ref)).GetPublicKey()
+	if err != nil {
+		return "", errors.Wrap(err, "[ getMemberPubKey ]")
 	}
-	key = member.GetObject(core.NewRefFromBase58(ref)).GetPublicKey()
 	ar.cacheLock.Lock()
 	ar.keyCache[ref] = key
 	ar.cacheLock.Unlock()
-	return key
+	return key, nil
 }
@@ -18,17 +18,30 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/insolar/insolar/api/seedmanager"
+	"github.com/insolar/insolar/application/client/rootdomain"
 	"github.com/insolar/insolar/bootstrap"
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+	"github.com/insolar/insolar/logicrunner/goplugin/goplugintestutils"
+	"github.com/insolar/insolar/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ecdsahelper "github.com/insolar/insolar/cryptohelpers/ecdsa"
 )
 
 const HOST = "http://localhost:19191"
@@ -104,6 +117,42 @@ func TestSerialization(t *testing.T) {
 	assert.Equal(t, c, rowResp[2].(string))
 }
 
+type testCaller struct {
+	method string
+	result []byte
+}
+
+func (c *testCaller) CallMethod(ref core.RecordRef, immutable bool, method string, args core.Arguments) ([]byte, error) {
+	c.method = method
+	return c.result, nil
+}
+
+func TestRootDomainClient(t *testing.T) {
+	caller := &testCaller{}
+	rd := rootdomain.NewRootDomain(caller, testutils.RandomRef())
+
+	var err error
+	caller.result, err = core.MarshalArgs("ref", &foundation.Error{S: "callee failed"})
+	assert.NoError(t, err)
+	_, err = rd.CreateMember("name", "key")
+	assert.EqualError(t, err, "callee failed")
+	assert.Equal(t, "CreateMember", caller.method)
+
+	caller.result, err = core.MarshalArgs("ref", nil)
+	assert.NoError(t, err)
+	ref, err := rd.CreateMember("name", "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "ref", ref)
+
+	caller.result, err = core.MarshalArgs("key", core.RoleVirtual, "")
+	assert.NoError(t, err)
+	key, role, errS, err := rd.Authorize()
+	assert.NoError(t, err)
+	assert.Equal(t, "key", key)
+	assert.Equal(t, core.RoleVirtual, role)
+	assert.Equal(t, "", errS)
+}
+
 func TestNewApiRunnerNilConfig(t *testing.T) {
 	_, err := NewRunner(nil)
 	assert.EqualError(t, err, "[ NewAPIRunner ] config is nil")
@@ -122,3 +171,150 @@ func TestNewApiRunnerNoRequiredParams(t *testing.T) {
 	_, err = NewRunner(&cfg)
 	assert.NoError(t, err)
 }
+
+func TestDeployContract(t *testing.T) {
+	am := goplugintestutils.NewTestArtifactManager()
+	sm := seedmanager.New()
+	var sg seedmanager.SeedGenerator
+
+	newDeployer := func() (*ecdsa.PrivateKey, string) {
+		key, err := ecdsahelper.GeneratePrivateKey()
+		assert.NoError(t, err)
+		pubKey, err := ecdsahelper.ExportPublicKey(&key.PublicKey)
+		assert.NoError(t, err)
+		return key, pubKey
+	}
+	owner, ownerPubKey := newDeployer()
+	other, otherPubKey := newDeployer()
+
+	sign := func(params *Params, key *ecdsa.PrivateKey, pubKey string) *Params {
+		seed, err := sg.Next()
+		assert.NoError(t, err)
+		sm.Add(*seed)
+		params.Seed = seed[:]
+		params.PublicKey = pubKey
+		data := DeploySignData(params)
+		params.Signature, err = ecdsahelper.Sign(data, key)
+		assert.NoError(t, err)
+		return params
+	}
+	deploy := func(params *Params) (map[string]interface{}, error) {
+		rh := NewRequestHandler(context.Background(), params, nil, am, nil, core.RecordRef{}, sm)
+		rh.deployers = []string{ownerPubKey, otherPubKey}
+		return rh.ProcessDeployContract()
+	}
+
+	_, err := deploy(&Params{})
+	assert.EqualError(t, err, "field 'code' is required")
+
+	_, err = deploy(&Params{Code: []byte("v1")})
+	assert.EqualError(t, err, "[ ProcessDeployContract ] public key is not allowed to deploy contracts")
+
+	params := sign(&Params{Code: []byte("v1")}, owner, ownerPubKey)
+	params.Code = []byte("evil")
+	_, err = deploy(params)
+	assert.EqualError(t, err, "[ ProcessDeployContract ] bad signature")
+
+	params = sign(&Params{Code: []byte("v1")}, owner, ownerPubKey)
+	res, err := deploy(params)
+	assert.NoError(t, err)
+	_, err = deploy(params)
+	assert.EqualError(t, err, "[ ProcessDeployContract ] seed is expired or unknown")
+
+	class := core.NewRefFromBase58(res[REFERENCE].(string))
+	classDesc, err := am.GetClass(class, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ownerPubKey, classDesc.Owner())
+	code, err := classDesc.CodeDescriptor().Code()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), code)
+
+	_, err = deploy(sign(&Params{Code: []byte("evil"), Reference: class.String()}, other, otherPubKey))
+	assert.EqualError(t, err, "[ ProcessDeployContract ] class is owned by another deployer")
+
+	res, err = deploy(sign(&Params{Code: []byte("v2"), Reference: class.String()}, owner, ownerPubKey))
+	assert.NoError(t, err)
+	assert.Equal(t, class.String(), res[REFERENCE])
+	classDesc, err = am.GetClass(class, nil)
+	assert.NoError(t, err)
+	code, err = classDesc.CodeDescriptor().Code()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v2"), code)
+	assert.Equal(t, res["code"], classDesc.CodeDescriptor().Ref().String())
+
+	_, err = deploy(sign(&Params{Code: []byte("v3"), ABI: []byte("{"), Reference: class.String()}, owner, ownerPubKey))
+	assert.EqualError(t, err, "field 'abi' should be JSON")
+	res, err = deploy(sign(&Params{Code: []byte("v3"), ABI: []byte(`{"contract":"A"}`), Reference: class.String()}, owner, ownerPubKey))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res["type"])
+	assert.Equal(t, []byte(`{"contract":"A"}`), am.ClassTypes[class])
+
+	_, err = deploy(&Params{Code: []byte("v4"), Migration: []byte("m4")})
+	assert.EqualError(t, err, "field 'migration' is allowed only with 'reference' of the class to update")
+
+	params = sign(&Params{Code: []byte("v4"), Migration: []byte("m4"), Reference: class.String()}, owner, ownerPubKey)
+	params.Migration = []byte("evil")
+	_, err = deploy(params)
+	assert.EqualError(t, err, "[ ProcessDeployContract ] bad signature")
+
+	res, err = deploy(sign(&Params{Code: []byte("v4"), Migration: []byte("m4"), Reference: class.String()}, owner, ownerPubKey))
+	assert.NoError(t, err)
+	classDesc, err = am.GetClass(class, nil)
+	assert.NoError(t, err)
+	require.Len(t, classDesc.Migrations(), 1)
+	assert.Equal(t, res["migration"], classDesc.Migrations()[0].String())
+	migration, err := am.GetCode(classDesc.Migrations()[0])
+	assert.NoError(t, err)
+	code, err = migration.Code()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("m4"), code)
+}
+
+type testMessageBus struct {
+	core.MessageBus
+	sent  core.Message
+	reply core.Reply
+}
+
+func (mb *testMessageBus) SendWithOptions(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error) {
+	mb.sent = msg
+	return mb.reply, nil
+}
+
+func TestGetABI(t *testing.T) {
+	class := testutils.RandomRef()
+	typeRef := testutils.RandomRef()
+	mb := &testMessageBus{reply: &reply.ClassType{Ref: typeRef, TypeDeclaration: []byte(`{"contract":"A"}`)}}
+	rh := NewRequestHandler(context.Background(), &Params{Reference: class.String()}, mb, nil, nil, core.RecordRef{}, nil)
+
+	res, err := rh.ProcessGetABI()
+	assert.NoError(t, err)
+	assert.Equal(t, &message.GetClassType{Class: class}, mb.sent)
+	assert.Equal(t, typeRef.String(), res["type"])
+	data, err := json.Marshal(res)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"abi":{"contract":"A"}`)
+
+	mb.reply = &reply.Error{ErrType: reply.ErrDeactivated}
+	_, err = rh.ProcessGetABI()
+	assert.Error(t, err)
+}
+
+func TestCallUsage(t *testing.T) {
+	mb := &testMessageBus{reply: &reply.CallMethod{
+		Usage: core.CallUsage{Time: time.Millisecond, Memory: 1024, Calls: 1},
+	}}
+	rh := NewRequestHandler(context.Background(), &Params{}, mb, nil, nil, core.RecordRef{}, nil)
+	assert.Nil(t, rh.usageAnswer())
+
+	for i := 0; i < 2; i++ {
+		_, err := rh.CallMethod(testutils.RandomRef(), false, "Method", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"calls":        2,
+		"time":         "2ms",
+		"memory":       uint64(2048),
+		"nested_calls": 2,
+	}, rh.usageAnswer())
+}
@@ -16,6 +16,10 @@
 
 package api
 
+import (
+	"github.com/insolar/insolar/cryptohelpers/hash"
+)
+
 // QueryType represents type of query
 type QueryType int
 
@@ -30,6 +34,9 @@ const (
 	RegisterNode
 	IsAuth
 	GetSeed
+	GetEvents
+	DeployContract
+	GetABI
 )
 
 // QTypeFromString converts string representation to enum
@@ -51,6 +58,12 @@ func QTypeFromString(strQType string) QueryType {
 		return IsAuth
 	case "get_seed":
 		return GetSeed
+	case "get_events":
+		return GetEvents
+	case "deploy_contract":
+		return DeployContract
+	case "get_abi":
+		return GetABI
 	}
 
 	return UNDEFINED
@@ -70,4 +83,22 @@ type Params struct {
 	Amount    uint   `json:"amount"`
 	PublicKey string `json:"public_key"`
 	Role      string `json:"role"`
+	FromEvent string `json:"from_event"`
+	Limit     int    `json:"limit"`
+	Code      []byte `json:"code"`
+	ABI       []byte `json:"abi"`
+	Migration []byte `json:"migration"` // plugin converting objects of the previous class state
+	Seed      []byte `json:"seed"`
+	Signature []byte `json:"signature"`
+}
+
+// DeploySignData returns data deployer of a contract signs, it binds seed with the plugin, the ABI, the migration
+// and the class being updated
+func DeploySignData(params *Params) []byte {
+	var data []byte
+	data = append(data, params.Seed...)
+	data = append(data, hash.SHA3Bytes256(params.Code)...)
+	data = append(data, hash.SHA3Bytes256(params.ABI)...)
+	data = append(data, hash.SHA3Bytes256(params.Migration)...)
+	return append(data, params.Reference...)
 }
@@ -17,16 +17,20 @@
 package api
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
-	"fmt"
 	"reflect"
 
 	"github.com/insolar/insolar/api/seedmanager"
+	"github.com/insolar/insolar/application/client/rootdomain"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
+	"github.com/jbenet/go-base58"
 	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/ugorji/go/codec"
 
 	ecdsahelper "github.com/insolar/insolar/cryptohelpers/ecdsa"
 )
@@ -36,92 +40,85 @@ const (
 	SEED      = "seed"
 )
 
-func extractStringResponse(data []byte) (*string, error) {
-	var typeHolder string
-	refOrig, err := core.UnMarshalResponse(data, []interface{}{typeHolder})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ extractStringResponse ]")
-	}
-
-	reference, ok := refOrig[0].(string)
-	if !ok {
-		msg := fmt.Sprintf("Can't cast response to string. orig: %T", refOrig)
-		return nil, errors.New(msg)
-	}
-
-	return &reference, nil
-}
-
-func extractAuthorizeResponse(data []byte) (string, core.NodeRole, error) {
-	var pubKey string
-	var role core.NodeRole
-	var fErr string
-	_, err := core.UnMarshalResponse(data, []interface{}{&pubKey, &role, &fErr})
-	if err != nil {
-		return "", core.RoleUnknown, errors.Wrap(err, "[ extractAuthorizeResponse ]")
-	}
-
-	if len(fErr) != 0 {
-		return "", core.RoleUnknown, errors.New("[ extractAuthorizeResponse ] " + fErr)
-	}
-
-	return pubKey, role, nil
-}
+// defaultEventsLimit is the page size of get_events query when limit is not set
+const defaultEventsLimit = 100
 
 // RequestHandler encapsulate processing of request
 type RequestHandler struct {
-	qid                 string
-	params              *Params
-	messageBus          core.MessageBus
-	rootDomainReference core.RecordRef
-	seedManager         *seedmanager.SeedManager
-	seedGenerator       seedmanager.SeedGenerator
-	netCoordinator      core.NetworkCoordinator
-}
-
-// NewRequestHandler creates new query handler
-func NewRequestHandler(params *Params, messageBus core.MessageBus, nc core.NetworkCoordinator, rootDomainReference core.RecordRef, smanager *seedmanager.SeedManager) *RequestHandler {
-	return &RequestHandler{
-		qid:                 params.QID,
-		params:              params,
-		messageBus:          messageBus,
-		rootDomainReference: rootDomainReference,
-		seedManager:         smanager,
-		netCoordinator:      nc,
-	}
+	ctx             context.Context
+	qid             string
+	params          *Params
+	messageBus      core.MessageBus
+	artifactManager core.ArtifactManager
+	seedManager     *seedmanager.SeedManager
+	seedGenerator   seedmanager.SeedGenerator
+	netCoordinator  core.NetworkCoordinator
+	rootDomain      *rootdomain.RootDomain
+	deployers       []string
+
+	// calls is a count of contract calls made by the handler, usage is a sum of resources used by them
+	calls int
+	usage core.CallUsage
 }
 
-func (rh *RequestHandler) sendRequest(method string, argsIn []interface{}) (core.Reply, error) {
-	args, err := core.MarshalArgs(argsIn...)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ SendRequest ]")
+// usageAnswer returns resources used by contract calls made by the handler, nil if no contracts were called
+func (rh *RequestHandler) usageAnswer() map[string]interface{} {
+	if rh.calls == 0 {
+		return nil
 	}
-
-	routResult, err := rh.routeCall(rh.rootDomainReference, method, args)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ SendRequest ]")
+	return map[string]interface{}{
+		"calls":        rh.calls,
+		"time":         rh.usage.Time.String(),
+		"memory":       rh.usage.Memory,
+		"nested_calls": rh.usage.Calls,
 	}
+}
 
-	return routResult, nil
+// NewRequestHandler creates new query handler
+func NewRequestHandler(ctx context.Context, params *Params, messageBus core.MessageBus, am core.ArtifactManager, nc core.NetworkCoordinator, rootDomainReference core.RecordRef, smanager *seedmanager.SeedManager) *RequestHandler {
+	rh := &RequestHandler{
+		ctx:             ctx,
+		qid:             params.QID,
+		params:          params,
+		messageBus:      messageBus,
+		artifactManager: am,
+		seedManager:     smanager,
+		netCoordinator:  nc,
+	}
+	rh.rootDomain = rootdomain.NewRootDomain(rh, rootDomainReference)
+	return rh
 }
 
-func (rh *RequestHandler) routeCall(ref core.RecordRef, method string, args core.Arguments) (core.Reply, error) {
+// CallMethod sends call of contract's method to the network, so the handler is a caller for contract clients
+func (rh *RequestHandler) CallMethod(ref core.RecordRef, immutable bool, method string, args core.Arguments) ([]byte, error) {
 	if rh.messageBus == nil {
-		return nil, errors.New("[ RouteCall ] message bus was not set during initialization")
+		return nil, errors.New("[ CallMethod ] message bus was not set during initialization")
 	}
 
 	e := &message.CallMethod{
 		ObjectRef: ref,
 		Method:    method,
 		Arguments: args,
+		Immutable: immutable,
 	}
 
-	res, err := rh.messageBus.Send(e)
+	res, err := rh.messageBus.SendWithOptions(rh.ctx, e, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "[ RouteCall ] couldn't send message")
+		return nil, errors.Wrap(err, "[ CallMethod ] couldn't send message")
 	}
 
-	return res, nil
+	switch r := res.(type) {
+	case *reply.CallMethod:
+		rh.calls++
+		rh.usage.Time += r.Usage.Time
+		rh.usage.Memory += r.Usage.Memory
+		rh.usage.Calls += r.Usage.Calls
+		return r.Result, nil
+	case *reply.Error:
+		return nil, errors.Wrap(r.Error(), "[ CallMethod ]")
+	default:
+		return nil, errors.Errorf("[ CallMethod ] unexpected reply %T", res)
+	}
 }
 
 // ProcessCreateMember processes CreateMember query type
@@ -135,12 +132,7 @@ func (rh *RequestHandler) ProcessCreateMember() (map[string]interface{}, error)
 		return nil, errors.New("field 'public_key' is required")
 	}
 
-	routResult, err := rh.sendRequest("CreateMember", []interface{}{rh.params.Name, rh.params.PublicKey})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessCreateMember ]")
-	}
-
-	memberRef, err := extractStringResponse(routResult.(*reply.CallMethod).Result)
+	memberRef, err := rh.rootDomain.CreateMember(rh.params.Name, rh.params.PublicKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessCreateMember ]")
 	}
@@ -150,22 +142,6 @@ func (rh *RequestHandler) ProcessCreateMember() (map[string]interface{}, error)
 	return result, nil
 }
 
-func extractGetBalanceResponse(data []byte) (uint, error) {
-	var typeHolder uint
-	dataUnmarsh, err := core.UnMarshalResponse(data, []interface{}{typeHolder})
-	if err != nil {
-		return 0, errors.Wrap(err, "[ extractGetBalanceResponse ]")
-	}
-
-	balance, ok := dataUnmarsh[0].(uint)
-	if !ok {
-		msg := fmt.Sprintf("Can't cast response to uint. orig: %s", reflect.TypeOf(dataUnmarsh[0]).String())
-		return 0, errors.New(msg)
-	}
-
-	return balance, nil
-}
-
 // ProcessGetBalance processes get_balance query type
 func (rh *RequestHandler) ProcessGetBalance() (map[string]interface{}, error) {
 	result := make(map[string]interface{})
@@ -175,12 +151,7 @@ func (rh *RequestHandler) ProcessGetBalance() (map[string]interface{}, error) {
 		return nil, errors.New("field 'reference' is required")
 	}
 
-	routResult, err := rh.sendRequest("GetBalance", []interface{}{rh.params.Reference})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessGetBalance ]")
-	}
-
-	amount, err := extractGetBalanceResponse(routResult.(*reply.CallMethod).Result)
+	amount, err := rh.rootDomain.GetBalance(rh.params.Reference)
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessGetBalance ]")
 	}
@@ -190,22 +161,6 @@ func (rh *RequestHandler) ProcessGetBalance() (map[string]interface{}, error) {
 	return result, nil
 }
 
-func extractBoolResponse(data []byte) (bool, error) {
-	var typeHolder bool
-	dataUnmarsh, err := core.UnMarshalResponse(data, []interface{}{typeHolder})
-	if err != nil {
-		return false, errors.Wrap(err, "[ extractBoolResponse ]")
-	}
-
-	isSent, ok := dataUnmarsh[0].(bool)
-	if !ok {
-		msg := fmt.Sprintf("Can't cast response to bool. orig: %T", dataUnmarsh)
-		return false, errors.New(msg)
-	}
-
-	return isSent, nil
-}
-
 // ProcessSendMoney processes send_money query type
 func (rh *RequestHandler) ProcessSendMoney() (map[string]interface{}, error) {
 	result := make(map[string]interface{})
@@ -221,13 +176,7 @@ func (rh *RequestHandler) ProcessSendMoney() (map[string]interface{}, error) {
 		return nil, errors.New("field 'amount' is required")
 	}
 
-	routResult, err := rh.sendRequest("SendMoney", []interface{}{rh.params.From, rh.params.To, rh.params.Amount})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessSendMoney ]")
-	}
-
-	isSent, err := extractBoolResponse(routResult.(*reply.CallMethod).Result)
-
+	isSent, err := rh.rootDomain.SendMoney(rh.params.From, rh.params.To, rh.params.Amount)
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessSendMoney ]")
 	}
@@ -237,42 +186,20 @@ func (rh *RequestHandler) ProcessSendMoney() (map[string]interface{}, error) {
 	return result, nil
 }
 
-func extractDumpAllUsersResponse(data []byte) ([]byte, error) {
-	var typeHolder []byte
-	dataUnmarsh, err := core.UnMarshalResponse(data, []interface{}{typeHolder})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ extractDumpAllUsersResponse ]")
-	}
-
-	dumpJSON, ok := dataUnmarsh[0].([]byte)
-	if !ok {
-		msg := fmt.Sprintf("Can't cast response to []byte. orig: %s", reflect.TypeOf(dataUnmarsh[0]))
-		return nil, errors.New(msg)
-	}
-
-	return dumpJSON, nil
-}
-
 // ProcessDumpUsers processes Dump users query type
 func (rh *RequestHandler) ProcessDumpUsers(all bool) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
 	var err error
-	var routResult core.Reply
+	var serJSONDump []byte
 	if all {
-		routResult, err = rh.sendRequest("DumpAllUsers", []interface{}{})
+		serJSONDump, err = rh.rootDomain.DumpAllUsers()
 	} else {
 		if len(rh.params.Reference) == 0 {
 			return nil, errors.New("field 'reference' is required")
 		}
-		routResult, err = rh.sendRequest("DumpUserInfo", []interface{}{rh.params.Reference})
-	}
-
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessDumpUsers ]")
+		serJSONDump, err = rh.rootDomain.DumpUserInfo(rh.params.Reference)
 	}
-
-	serJSONDump, err := extractDumpAllUsersResponse(routResult.(*reply.CallMethod).Result)
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessDumpUsers ]")
 	}
@@ -298,12 +225,7 @@ func (rh *RequestHandler) ProcessRegisterNode() (map[string]interface{}, error)
 		return nil, errors.New("field 'role' is required")
 	}
 
-	routResult, err := rh.sendRequest("RegisterNode", []interface{}{rh.params.PublicKey, rh.params.Role})
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessRegisterNode ]")
-	}
-
-	nodeRef, err := extractStringResponse(routResult.(*reply.CallMethod).Result)
+	nodeRef, err := rh.rootDomain.RegisterNode(rh.params.PublicKey, rh.params.Role)
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessRegisterNode ]")
 	}
@@ -319,14 +241,12 @@ func (rh *RequestHandler) ProcessIsAuthorized() (map[string]interface{}, error)
 
 	// Check calling smart contract
 	result := make(map[string]interface{})
-	routResult, err := rh.sendRequest("Authorize", []interface{}{})
+	pubKey, role, errS, err := rh.rootDomain.Authorize()
 	if err != nil {
 		return nil, errors.Wrap(err, "[ ProcessIsAuthorized ]")
 	}
-
-	pubKey, role, err := extractAuthorizeResponse(routResult.(*reply.CallMethod).Result)
-	if err != nil {
-		return nil, errors.Wrap(err, "[ ProcessIsAuthorized ]")
+	if len(errS) != 0 {
+		return nil, errors.New("[ ProcessIsAuthorized ] " + errS)
 	}
 	result["public_key"] = pubKey
 	result["role"] = role
@@ -379,3 +299,226 @@ func (rh *RequestHandler) ProcessGetSeed() (map[string]interface{}, error) {
 
 	return result, nil
 }
+
+func decodeEventPayload(data []byte) (interface{}, error) {
+	ch := new(codec.CborHandle)
+	ch.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	var payload interface{}
+	err := codec.NewDecoderBytes(data, ch).Decode(&payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ decodeEventPayload ]")
+	}
+	return payload, nil
+}
+
+// ProcessGetEvents processes get_events query type
+func (rh *RequestHandler) ProcessGetEvents() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	if len(rh.params.Reference) == 0 {
+		return nil, errors.New("field 'reference' is required")
+	}
+	if rh.messageBus == nil {
+		return nil, errors.New("[ ProcessGetEvents ] message bus was not set during initialization")
+	}
+
+	msg := &message.GetEvents{
+		Object: core.NewRefFromBase58(rh.params.Reference),
+		Amount: rh.params.Limit,
+	}
+	if msg.Amount <= 0 {
+		msg.Amount = defaultEventsLimit
+	}
+	if len(rh.params.FromEvent) != 0 {
+		var from core.RecordID
+		copy(from[:], base58.Decode(rh.params.FromEvent))
+		msg.FromEvent = &from
+	}
+
+	res, err := rh.messageBus.SendWithOptions(rh.ctx, msg, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessGetEvents ] couldn't send message")
+	}
+	var rep *reply.Events
+	switch r := res.(type) {
+	case *reply.Events:
+		rep = r
+	case *reply.Error:
+		return nil, errors.Wrap(r.Error(), "[ ProcessGetEvents ]")
+	default:
+		return nil, errors.Errorf("[ ProcessGetEvents ] unexpected reply %T", res)
+	}
+
+	events := make([]map[string]interface{}, 0, len(rep.Events))
+	for _, e := range rep.Events {
+		payload, err := decodeEventPayload(e.Payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "[ ProcessGetEvents ]")
+		}
+		events = append(events, map[string]interface{}{
+			"id":      base58.Encode(e.ID[:]),
+			"name":    e.Name,
+			"payload": payload,
+		})
+	}
+	result["events"] = events
+	if rep.NextFrom != nil {
+		result["next_from"] = base58.Encode(rep.NextFrom[:])
+	}
+
+	return result, nil
+}
+
+// checkDeploySignature checks that deploy request is signed by one of configured deployers
+// with a fresh seed, every seed can be used only once
+func (rh *RequestHandler) checkDeploySignature() error {
+	allowed := false
+	for _, deployer := range rh.deployers {
+		if deployer == rh.params.PublicKey {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errors.New("[ ProcessDeployContract ] public key is not allowed to deploy contracts")
+	}
+
+	if rh.seedManager == nil {
+		return errors.New("[ ProcessDeployContract ] seed manager was not set during initialization")
+	}
+	var seed seedmanager.Seed
+	if len(rh.params.Seed) != len(seed) {
+		return errors.New("[ ProcessDeployContract ] bad seed")
+	}
+	copy(seed[:], rh.params.Seed)
+	if !rh.seedManager.Pop(seed) {
+		return errors.New("[ ProcessDeployContract ] seed is expired or unknown")
+	}
+
+	data := DeploySignData(rh.params)
+	ok, err := ecdsahelper.Verify(data, rh.params.Signature, rh.params.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "[ ProcessDeployContract ] couldn't verify signature")
+	}
+	if !ok {
+		return errors.New("[ ProcessDeployContract ] bad signature")
+	}
+	return nil
+}
+
+// ProcessDeployContract processes deploy_contract query type: stores plugin code on ledger and activates
+// a new class with it, or updates the class from 'reference'. Class update may carry a plugin from 'migration'
+// which converts objects of the previous class state. ABI from 'abi' is declared as the class type.
+// Request should be signed by one of deployers from configuration, and only the deployer who activated the class
+// can update it. Plugins are stored as is, the node can't tell what code they are built from, so deployers are
+// trusted to check their contracts, e.g. `insgocc deploy` does it. Ledger accepts code and classes from the node
+// only if it is listed in ledger deployers.
+func (rh *RequestHandler) ProcessDeployContract() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	if len(rh.params.Code) == 0 {
+		return nil, errors.New("field 'code' is required")
+	}
+	if len(rh.params.ABI) != 0 && !json.Valid(rh.params.ABI) {
+		return nil, errors.New("field 'abi' should be JSON")
+	}
+	if len(rh.params.Migration) != 0 && len(rh.params.Reference) == 0 {
+		return nil, errors.New("field 'migration' is allowed only with 'reference' of the class to update")
+	}
+	am := rh.artifactManager
+	if am == nil {
+		return nil, errors.New("[ ProcessDeployContract ] artifact manager was not set during initialization")
+	}
+	if err := rh.checkDeploySignature(); err != nil {
+		return nil, err
+	}
+
+	if len(rh.params.Reference) != 0 {
+		classDesc, err := am.GetClass(core.NewRefFromBase58(rh.params.Reference), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't get class")
+		}
+		if classDesc.Owner() == "" || classDesc.Owner() != rh.params.PublicKey {
+			return nil, errors.New("[ ProcessDeployContract ] class is owned by another deployer")
+		}
+	}
+
+	nonce, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't generate request nonce")
+	}
+	request, err := am.RegisterRequest(&message.BootstrapRequest{Name: "DeployContract " + nonce.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't register request")
+	}
+	code, err := am.DeployCode(core.RecordRef{}, *request, rh.params.Code, core.MachineTypeGoPlugin)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't deploy code")
+	}
+
+	class := *request
+	if len(rh.params.Reference) == 0 {
+		_, err = am.ActivateClass(core.RecordRef{}, class, *code, rh.params.PublicKey)
+	} else {
+		var migrations []core.RecordRef
+		if len(rh.params.Migration) != 0 {
+			migration, err := am.DeployCode(core.RecordRef{}, *request, rh.params.Migration, core.MachineTypeGoPlugin)
+			if err != nil {
+				return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't deploy migration")
+			}
+			migrations = append(migrations, *migration)
+			result["migration"] = migration.String()
+		}
+		class = core.NewRefFromBase58(rh.params.Reference)
+		_, err = am.UpdateClass(core.RecordRef{}, *request, class, *code, migrations)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't save class")
+	}
+	if len(rh.params.ABI) != 0 {
+		typeRef, err := am.DeclareType(core.RecordRef{}, *request, class, rh.params.ABI)
+		if err != nil {
+			return nil, errors.Wrap(err, "[ ProcessDeployContract ] couldn't declare type")
+		}
+		result["type"] = typeRef.String()
+	}
+
+	result[REFERENCE] = class.String()
+	result["code"] = code.String()
+	return result, nil
+}
+
+// ProcessGetABI processes get_abi query type: returns ABI declared for the class from 'reference'
+func (rh *RequestHandler) ProcessGetABI() (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	if len(rh.params.Reference) == 0 {
+		return nil, errors.New("field 'reference' is required")
+	}
+	if rh.messageBus == nil {
+		return nil, errors.New("[ ProcessGetABI ] message bus was not set during initialization")
+	}
+
+	msg := &message.GetClassType{Class: core.NewRefFromBase58(rh.params.Reference)}
+	res, err := rh.messageBus.SendWithOptions(rh.ctx, msg, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "[ ProcessGetABI ] couldn't send message")
+	}
+	var rep *reply.ClassType
+	switch r := res.(type) {
+	case *reply.ClassType:
+		rep = r
+	case *reply.Error:
+		return nil, errors.Wrap(r.Error(), "[ ProcessGetABI ]")
+	default:
+		return nil, errors.Errorf("[ ProcessGetABI ] unexpected reply %T", res)
+	}
+
+	if !json.Valid(rep.TypeDeclaration) {
+		return nil, errors.New("[ ProcessGetABI ] type of the class is not a valid ABI")
+	}
+	result["abi"] = json.RawMessage(rep.TypeDeclaration)
+	result["type"] = rep.Ref.String()
+
+	return result, nil
+}
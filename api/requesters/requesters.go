@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/insolar/insolar/api"
 	"github.com/insolar/insolar/application/contract/member/signer"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/log"
@@ -156,3 +157,16 @@ func Send(url string, userCfg *UserConfigJSON, reqCfg *RequestConfigJSON) ([]byt
 
 	return response, nil
 }
+
+// SignDeploy signs deploy_contract request with the user's key, returns signature and public key of the user
+func SignDeploy(userCfg *UserConfigJSON, params *api.Params) ([]byte, string, error) {
+	signature, err := ecdsahelper.Sign(api.DeploySignData(params), userCfg.privateKeyObject)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "[ SignDeploy ] Problem with signing request")
+	}
+	pubKey, err := ecdsahelper.ExportPublicKey(&userCfg.privateKeyObject.PublicKey)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "[ SignDeploy ] Problem with exporting public key")
+	}
+	return signature, pubKey, nil
+}
@@ -67,6 +67,16 @@ func (sm *SeedManager) Exists(seed Seed) bool {
 	return ok && !sm.isExpired(expTime)
 }
 
+// Pop removes seed from the pool, returns true if the seed was in the pool and not expired
+func (sm *SeedManager) Pop(seed Seed) bool {
+	sm.mu.Lock()
+	expTime, ok := sm.seedPool[seed]
+	delete(sm.seedPool, seed)
+	sm.mu.Unlock()
+
+	return ok && !sm.isExpired(expTime)
+}
+
 func (sm *SeedManager) deleteExpired() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -0,0 +1,209 @@
+package rootdomain
+
+import (
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+// Caller sends call of contract's method to the network and returns serialized results
+type Caller interface {
+	CallMethod(ref core.RecordRef, immutable bool, method string, args core.Arguments) ([]byte, error)
+}
+
+// RootDomain is typed client of the contract's object
+type RootDomain struct {
+	Reference core.RecordRef
+	caller    Caller
+}
+
+// NewRootDomain returns client of the object calling it with the caller
+func NewRootDomain(caller Caller, ref core.RecordRef) *RootDomain {
+	return &RootDomain{Reference: ref, caller: caller}
+}
+
+// GetReference returns reference of the object
+func (c *RootDomain) GetReference() core.RecordRef {
+	return c.Reference
+}
+
+// RegisterNode calls RegisterNode method of the object
+func (c *RootDomain) RegisterNode(publicKey string, role string) (string, error) {
+	ret := [2]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs(publicKey, role)
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, false, "RegisterNode", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// Authorize calls Authorize method of the object
+func (c *RootDomain) Authorize() (string, core.NodeRole, string, error) {
+	ret := [3]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+	var ret1 core.NodeRole
+	ret[1] = &ret1
+	var ret2 string
+	ret[2] = &ret2
+
+	args, err := core.MarshalArgs()
+	if err != nil {
+		return ret0, ret1, ret2, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, false, "Authorize", args)
+	if err != nil {
+		return ret0, ret1, ret2, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	return ret0, ret1, ret2, err
+}
+
+// CreateMember calls CreateMember method of the object
+func (c *RootDomain) CreateMember(name string, key string) (string, error) {
+	ret := [2]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs(name, key)
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, false, "CreateMember", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// GetBalance calls GetBalance method of the object
+func (c *RootDomain) GetBalance(reference string) (uint, error) {
+	ret := [2]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs(reference)
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, true, "GetBalance", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// SendMoney calls SendMoney method of the object
+func (c *RootDomain) SendMoney(from string, to string, amount uint) (bool, error) {
+	ret := [2]interface{}{}
+	var ret0 bool
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs(from, to, amount)
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, false, "SendMoney", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// DumpUserInfo calls DumpUserInfo method of the object
+func (c *RootDomain) DumpUserInfo(reference string) ([]byte, error) {
+	ret := [2]interface{}{}
+	var ret0 []byte
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs(reference)
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, true, "DumpUserInfo", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// DumpAllUsers calls DumpAllUsers method of the object
+func (c *RootDomain) DumpAllUsers() ([]byte, error) {
+	ret := [2]interface{}{}
+	var ret0 []byte
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
+	args, err := core.MarshalArgs()
+	if err != nil {
+		return ret0, err
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, true, "DumpAllUsers", args)
+	if err != nil {
+		return ret0, err
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	if err == nil && ret1 != nil {
+		err = ret1
+	}
+	return ret0, err
+}
+
+// toError converts error returned by contract to error interface keeping nil as nil
+func toError(err *foundation.Error) error {
+	if err == nil {
+		return nil
+	}
+	return err
+}
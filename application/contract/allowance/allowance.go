@@ -30,6 +30,8 @@ type Allowance struct {
 	ExpireTime int64
 }
 
+// IsExpired checks if the allowance can't be taken anymore
+// ins:immutable
 func (a *Allowance) IsExpired() bool {
 	return a.GetContext().Time.After(time.Unix(a.ExpireTime, 0))
 }
@@ -43,6 +45,8 @@ func (a *Allowance) TakeAmount() uint {
 	return 0
 }
 
+// GetBalanceForOwner returns amount of the allowance if it isn't expired
+// ins:immutable
 func (a *Allowance) GetBalanceForOwner() uint {
 	if !a.IsExpired() {
 		return a.Amount
@@ -30,9 +30,14 @@ type Member struct {
 	PublicKey string
 }
 
+// GetName returns name of the member
+// ins:immutable
 func (m *Member) GetName() string {
 	return m.Name
 }
+
+// GetPublicKey returns public key of the member
+// ins:immutable
 func (m *Member) GetPublicKey() string {
 	return m.PublicKey
 }
@@ -66,7 +71,7 @@ func (m *Member) AuthorizedCall(ref core.RecordRef, delegate core.RecordRef, met
 	} else {
 		contract = ref
 	}
-	ret, err := proxyctx.Current.RouteCall(contract, true, method, params)
+	ret, err := proxyctx.Current.RouteCall(contract, true, false, method, params)
 	if err != nil {
 		return nil, &foundation.Error{S: err.Error()}
 	}
@@ -34,11 +34,14 @@ func NewNodeDomain() *NodeDomain {
 }
 
 // RegisterNode registers node in system
-func (nd *NodeDomain) RegisterNode(pk string, role string) core.RecordRef {
+func (nd *NodeDomain) RegisterNode(pk string, role string) (core.RecordRef, error) {
 	// TODO: what should be done when record already exists?
 	newRecord := noderecord.NewNodeRecord(pk, role)
-	record := newRecord.AsChild(nd.GetReference())
-	return record.GetReference()
+	record, err := newRecord.AsChild(nd.GetReference())
+	if err != nil {
+		return core.RecordRef{}, err
+	}
+	return record.GetReference(), nil
 }
 
 func (nd *NodeDomain) getNodeRecord(ref core.RecordRef) *noderecord.NodeRecord {
@@ -46,44 +49,36 @@ func (nd *NodeDomain) getNodeRecord(ref core.RecordRef) *noderecord.NodeRecord {
 }
 
 // RemoveNode deletes node from registry
-func (nd *NodeDomain) RemoveNode(nodeRef core.RecordRef) {
+func (nd *NodeDomain) RemoveNode(nodeRef core.RecordRef) error {
 	node := nd.getNodeRecord(nodeRef)
-	node.Destroy()
+	return node.Destroy()
 }
 
 // IsAuthorized checks is signature correct
-func (nd *NodeDomain) IsAuthorized(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) bool {
+// ins:immutable
+func (nd *NodeDomain) IsAuthorized(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) (bool, error) {
 	nodeR := nd.getNodeRecord(nodeRef)
-	ok, err := ecdsa.Verify(seed, signatureRaw, nodeR.GetPublicKey())
+	pubKey, err := nodeR.GetPublicKey()
 	if err != nil {
-		panic(err)
+		return false, err
 	}
-	return ok
+	return ecdsa.Verify(seed, signatureRaw, pubKey)
 }
 
 // Authorize checks node and returns node info
+// ins:immutable
 func (nd *NodeDomain) Authorize(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) (pubKey string, role core.NodeRole, errS string) {
-	// TODO: this should be removed when proxies stop panic
-	defer func() {
-		if r := recover(); r != nil {
-			pubKey = ""
-			role = core.RoleUnknown
-			err, ok := r.(error)
-			errTxt := ""
-			if ok {
-				errTxt = err.Error()
-			}
-			errS = "[ Authorize ] Recover after panic: " + errTxt
-		}
-	}()
 	nodeR := nd.getNodeRecord(nodeRef)
-	role, pubKey = nodeR.GetRoleAndPublicKey()
+	role, pubKey, err := nodeR.GetRoleAndPublicKey()
+	if err != nil {
+		return "", core.RoleUnknown, "[ Authorize ] Problem with getting node record: " + err.Error()
+	}
 	ok, err := ecdsa.Verify(seed, signatureRaw, pubKey)
 	if err != nil {
 		return "", core.RoleUnknown, "[ Authorize ] Problem with verifying of signature: " + err.Error()
 	}
 	if !ok {
-		return "", core.RoleUnknown, "[ Authorize ] Can't verify signature: " + err.Error()
+		return "", core.RoleUnknown, "[ Authorize ] Can't verify signature"
 	}
 
 	return pubKey, role, ""
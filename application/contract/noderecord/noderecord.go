@@ -45,16 +45,19 @@ func NewNodeRecord(pk string, roleS string) *NodeRecord {
 }
 
 // GetPublicKey returns public key
+// ins:immutable
 func (nr *NodeRecord) GetPublicKey() string {
 	return nr.PublicKey
 }
 
 // GetRole returns role
+// ins:immutable
 func (nr *NodeRecord) GetRole() core.NodeRole {
 	return nr.Role
 }
 
 // GetRoleAndPublicKey returns role-pubKey pair
+// ins:immutable
 func (nr *NodeRecord) GetRoleAndPublicKey() (core.NodeRole, string) {
 	return nr.Role, nr.PublicKey
 }
@@ -36,118 +36,165 @@ type RootDomain struct {
 }
 
 // RegisterNode processes register node request
-func (rd *RootDomain) RegisterNode(publicKey string, role string) string {
+func (rd *RootDomain) RegisterNode(publicKey string, role string) (string, error) {
 	domainRefs, err := rd.GetChildrenTyped(nodedomain.ClassReference)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	if len(domainRefs) == 0 {
-		panic("No NodeDomain references")
+		return "", &foundation.Error{S: "No NodeDomain references"}
 	}
 	nd := nodedomain.GetObject(domainRefs[0])
 
-	return nd.RegisterNode(publicKey, role).String()
+	nodeRef, err := nd.RegisterNode(publicKey, role)
+	if err != nil {
+		return "", err
+	}
+	return nodeRef.String(), nil
 }
 
-func makeSeed() []byte {
+func makeSeed() ([]byte, error) {
 	seed := make([]byte, 32)
 	_, err := rand.Read(seed)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return seed
+	return seed, nil
 }
 
 // Authorize checks is node authorized
 func (rd *RootDomain) Authorize() (string, core.NodeRole, string) {
 	privateKey, err := cryptoHelper.GeneratePrivateKey()
 	if err != nil {
-		panic(err)
+		return "", core.RoleUnknown, "[ Authorize ] Problem with key generating: " + err.Error()
 	}
 
 	// Make signature
-	seed := makeSeed()
+	seed, err := makeSeed()
+	if err != nil {
+		return "", core.RoleUnknown, "[ Authorize ] Problem with generating seed: " + err.Error()
+	}
 	signature, err := cryptoHelper.Sign(seed, privateKey)
 	if err != nil {
-		panic(err)
+		return "", core.RoleUnknown, "[ Authorize ] Problem with signing: " + err.Error()
 	}
 
 	// Register node
 	serPubKey, err := cryptoHelper.ExportPublicKey(&privateKey.PublicKey)
 	if err != nil {
-		panic(err)
+		return "", core.RoleUnknown, "[ Authorize ] Problem with exporting pubKey: " + err.Error()
+	}
+	nodeRef, err := rd.RegisterNode(serPubKey, "virtual")
+	if err != nil {
+		return "", core.RoleUnknown, "[ Authorize ] Problem with registering node: " + err.Error()
 	}
-	nodeRef := rd.RegisterNode(serPubKey, "virtual")
 
 	// Validate
 	domainRefs, err := rd.GetChildrenTyped(nodedomain.ClassReference)
 	if err != nil {
-		panic(err)
+		return "", core.RoleUnknown, "[ Authorize ] Problem with getting node domain: " + err.Error()
 	}
 	nd := nodedomain.GetObject(domainRefs[0])
 
-	return nd.Authorize(core.NewRefFromBase58(nodeRef), seed, signature)
+	pubKey, role, errS, err := nd.Authorize(core.NewRefFromBase58(nodeRef), seed, signature)
+	if err != nil {
+		return "", core.RoleUnknown, "[ Authorize ] Problem with calling node domain: " + err.Error()
+	}
+	return pubKey, role, errS
 }
 
 // CreateMember processes create member request
-func (rd *RootDomain) CreateMember(name string, key string) string {
+func (rd *RootDomain) CreateMember(name string, key string) (string, error) {
 	//if rd.GetContext().Caller != nil && *rd.GetContext().Caller == *rd.RootMember {
 	memberHolder := member.New(name, key)
-	m := memberHolder.AsChild(rd.GetReference())
+	m, err := memberHolder.AsChild(rd.GetReference())
+	if err != nil {
+		return "", err
+	}
 	wHolder := wallet.New(1000)
-	wHolder.AsDelegate(m.GetReference())
-	return m.GetReference().String()
+	_, err = wHolder.AsDelegate(m.GetReference())
+	if err != nil {
+		return "", err
+	}
+	return m.GetReference().String(), nil
 	//}
 	//return ""
 }
 
 // GetBalance processes get balance request
-func (rd *RootDomain) GetBalance(reference string) uint {
-	w := wallet.GetImplementationFrom(core.NewRefFromBase58(reference))
+// ins:immutable
+func (rd *RootDomain) GetBalance(reference string) (uint, error) {
+	w, err := wallet.GetImplementationFrom(core.NewRefFromBase58(reference))
+	if err != nil {
+		return 0, err
+	}
 	return w.GetTotalBalance()
 }
 
 // SendMoney processes send money request
-func (rd *RootDomain) SendMoney(from string, to string, amount uint) bool {
-	walletFrom := wallet.GetImplementationFrom(core.NewRefFromBase58(from))
+func (rd *RootDomain) SendMoney(from string, to string, amount uint) (bool, error) {
+	walletFrom, err := wallet.GetImplementationFrom(core.NewRefFromBase58(from))
+	if err != nil {
+		return false, err
+	}
 	v := core.NewRefFromBase58(to)
-	walletFrom.Transfer(amount, &v)
-	return true
+	err = walletFrom.Transfer(amount, &v)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (rd *RootDomain) getUserInfoMap(m *member.Member) map[string]interface{} {
-	w := wallet.GetImplementationFrom(m.GetReference())
+func (rd *RootDomain) getUserInfoMap(m *member.Member) (map[string]interface{}, error) {
+	w, err := wallet.GetImplementationFrom(m.GetReference())
+	if err != nil {
+		return nil, err
+	}
+	name, err := m.GetName()
+	if err != nil {
+		return nil, err
+	}
+	balance, err := w.GetTotalBalance()
+	if err != nil {
+		return nil, err
+	}
 	res := map[string]interface{}{
-		"member": m.GetName(),
-		"wallet": w.GetTotalBalance(),
+		"member": name,
+		"wallet": balance,
 	}
-	return res
+	return res, nil
 }
 
 // DumpUserInfo processes dump user info request
-func (rd *RootDomain) DumpUserInfo(reference string) []byte {
+// ins:immutable
+func (rd *RootDomain) DumpUserInfo(reference string) ([]byte, error) {
 	m := member.GetObject(core.NewRefFromBase58(reference))
-	res := rd.getUserInfoMap(m)
-	resJSON, _ := json.Marshal(res)
-	return resJSON
+	res, err := rd.getUserInfoMap(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(res)
 }
 
 // DumpAllUsers processes dump all users request
-func (rd *RootDomain) DumpAllUsers() []byte {
+// ins:immutable
+func (rd *RootDomain) DumpAllUsers() ([]byte, error) {
 	res := []map[string]interface{}{}
 	crefs, err := rd.GetChildrenTyped(member.ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	for _, cref := range crefs {
 		m := member.GetObject(cref)
-		userInfo := rd.getUserInfoMap(m)
+		userInfo, err := rd.getUserInfoMap(m)
+		if err != nil {
+			return nil, err
+		}
 		res = append(res, userInfo)
 	}
-	resJSON, _ := json.Marshal(res)
-	return resJSON
+	return json.Marshal(res)
 }
 
 // NewRootDomain creates new RootDomain
@@ -31,62 +31,104 @@ type Wallet struct {
 }
 
 // Allocate - returns reference to a new allowance
-func (w *Wallet) Allocate(amount uint, to *core.RecordRef) core.RecordRef {
+func (w *Wallet) Allocate(amount uint, to *core.RecordRef) (core.RecordRef, error) {
 	// TODO check balance is enough
-	w.Balance -= amount
 	ah := allowance.New(to, amount, w.GetContext().Time.Unix()+10)
-	a := ah.AsChild(w.GetReference())
-	return a.GetReference()
+	a, err := ah.AsChild(w.GetReference())
+	if err != nil {
+		return core.RecordRef{}, err
+	}
+	w.Balance -= amount
+	return a.GetReference(), nil
 }
 
-func (w *Wallet) Receive(amount uint, from *core.RecordRef) {
-	fromWallet := wallet.GetImplementationFrom(*from)
+func (w *Wallet) Receive(amount uint, from *core.RecordRef) error {
+	fromWallet, err := wallet.GetImplementationFrom(*from)
+	if err != nil {
+		return err
+	}
 
 	v := w.GetReference()
-	aRef := fromWallet.Allocate(amount, &v)
-	w.Balance += allowance.GetObject(aRef).TakeAmount()
+	aRef, err := fromWallet.Allocate(amount, &v)
+	if err != nil {
+		return err
+	}
+	taken, err := allowance.GetObject(aRef).TakeAmount()
+	if err != nil {
+		return err
+	}
+	w.Balance += taken
+	return nil
 }
 
-func (w *Wallet) Transfer(amount uint, to *core.RecordRef) {
-	w.Balance -= amount
-
-	toWallet := wallet.GetImplementationFrom(*to)
+func (w *Wallet) Transfer(amount uint, to *core.RecordRef) error {
+	toWallet, err := wallet.GetImplementationFrom(*to)
+	if err != nil {
+		return err
+	}
 	toWalletRef := toWallet.GetReference()
 
 	ah := allowance.New(&toWalletRef, amount, w.GetContext().Time.Unix()+10)
-	a := ah.AsChild(w.GetReference())
+	a, err := ah.AsChild(w.GetReference())
+	if err != nil {
+		return err
+	}
+	w.Balance -= amount
 
+	// allowance is still counted in balance of the wallet if it wasn't accepted
 	r := a.GetReference()
-	toWallet.Accept(&r)
+	err = toWallet.Accept(&r)
+	if err != nil {
+		return err
+	}
+
+	w.Emit("TransferCompleted", map[string]interface{}{"to": to.String(), "amount": amount})
+	return nil
 }
 
-func (w *Wallet) Accept(aRef *core.RecordRef) {
-	w.Balance += allowance.GetObject(*aRef).TakeAmount()
+func (w *Wallet) Accept(aRef *core.RecordRef) error {
+	taken, err := allowance.GetObject(*aRef).TakeAmount()
+	if err != nil {
+		return err
+	}
+	w.Balance += taken
+	return nil
 }
 
-func (w *Wallet) GetTotalBalance() uint {
+// GetTotalBalance returns balance of the wallet including allowances
+// ins:immutable
+func (w *Wallet) GetTotalBalance() (uint, error) {
 	var totalAllowanced uint
 	crefs, err := w.GetChildrenTyped(allowance.GetClass())
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 	for _, cref := range crefs {
 		a := allowance.GetObject(cref)
 
-		totalAllowanced += a.GetBalanceForOwner()
+		balance, err := a.GetBalanceForOwner()
+		if err != nil {
+			return 0, err
+		}
+		totalAllowanced += balance
 	}
-	return w.Balance + totalAllowanced
+	return w.Balance + totalAllowanced, nil
 }
 
-func (w *Wallet) ReturnAndDeleteExpiredAllowances() {
+func (w *Wallet) ReturnAndDeleteExpiredAllowances() error {
 	crefs, err := w.GetChildrenTyped(allowance.GetClass())
 	if err != nil {
-		panic(err)
+		return err
 	}
 	for _, cref := range crefs {
 		Allowance := allowance.GetObject(cref)
-		w.Balance += Allowance.DeleteExpiredAllowance()
+		amount, err := Allowance.DeleteExpiredAllowance()
+		if err != nil {
+			return err
+		}
+		w.Balance += amount
 	}
+	return nil
 }
 
 func New(balance uint) *Wallet {
@@ -17,24 +17,31 @@ type Allowance struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *Allowance {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*Allowance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Allowance{Reference: ref}
+	return &Allowance{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *Allowance {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*Allowance, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Allowance{Reference: ref}
+	return &Allowance{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -48,12 +55,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *Allowance {
+func GetImplementationFrom(object core.RecordRef) (*Allowance, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // New is constructor
@@ -66,7 +73,7 @@ func New(to *core.RecordRef, amount uint, expire int64) *ContractConstructorHold
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "New", argsSerialized: argsSerialized}
@@ -83,181 +90,157 @@ func (r *Allowance) GetClass() core.RecordRef {
 }
 
 // IsExpired is proxy generated method
-func (r *Allowance) IsExpired() bool {
+func (r *Allowance) IsExpired() (bool, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 bool
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "IsExpired", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "IsExpired", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 bool
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // IsExpiredNoWait is proxy generated method
-func (r *Allowance) IsExpiredNoWait() {
+func (r *Allowance) IsExpiredNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "IsExpired", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "IsExpired", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // TakeAmount is proxy generated method
-func (r *Allowance) TakeAmount() uint {
+func (r *Allowance) TakeAmount() (uint, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "TakeAmount", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "TakeAmount", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 uint
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // TakeAmountNoWait is proxy generated method
-func (r *Allowance) TakeAmountNoWait() {
+func (r *Allowance) TakeAmountNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "TakeAmount", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "TakeAmount", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetBalanceForOwner is proxy generated method
-func (r *Allowance) GetBalanceForOwner() uint {
+func (r *Allowance) GetBalanceForOwner() (uint, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetBalanceForOwner", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetBalanceForOwner", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 uint
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetBalanceForOwnerNoWait is proxy generated method
-func (r *Allowance) GetBalanceForOwnerNoWait() {
+func (r *Allowance) GetBalanceForOwnerNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetBalanceForOwner", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetBalanceForOwner", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DeleteExpiredAllowance is proxy generated method
-func (r *Allowance) DeleteExpiredAllowance() uint {
+func (r *Allowance) DeleteExpiredAllowance() (uint, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "DeleteExpiredAllowance", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "DeleteExpiredAllowance", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 uint
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DeleteExpiredAllowanceNoWait is proxy generated method
-func (r *Allowance) DeleteExpiredAllowanceNoWait() {
+func (r *Allowance) DeleteExpiredAllowanceNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "DeleteExpiredAllowance", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "DeleteExpiredAllowance", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
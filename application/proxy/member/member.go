@@ -18,24 +18,31 @@ type Member struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *Member {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*Member, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Member{Reference: ref}
+	return &Member{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *Member {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*Member, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Member{Reference: ref}
+	return &Member{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -49,12 +56,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *Member {
+func GetImplementationFrom(object core.RecordRef) (*Member, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // New is constructor
@@ -66,7 +73,7 @@ func New(name string, key string) *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "New", argsSerialized: argsSerialized}
@@ -83,97 +90,85 @@ func (r *Member) GetClass() core.RecordRef {
 }
 
 // GetName is proxy generated method
-func (r *Member) GetName() string {
+func (r *Member) GetName() (string, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetName", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetName", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 string
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetNameNoWait is proxy generated method
-func (r *Member) GetNameNoWait() {
+func (r *Member) GetNameNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetName", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetName", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetPublicKey is proxy generated method
-func (r *Member) GetPublicKey() string {
+func (r *Member) GetPublicKey() (string, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetPublicKey", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetPublicKey", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 string
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetPublicKeyNoWait is proxy generated method
-func (r *Member) GetPublicKeyNoWait() {
+func (r *Member) GetPublicKeyNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetPublicKey", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetPublicKey", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AuthorizedCall is proxy generated method
-func (r *Member) AuthorizedCall(ref core.RecordRef, delegate core.RecordRef, method string, params []byte, seed []byte, sign []byte) ([]byte, *foundation.Error) {
+func (r *Member) AuthorizedCall(ref core.RecordRef, delegate core.RecordRef, method string, params []byte, seed []byte, sign []byte) ([]byte, *foundation.Error, error) {
 	var args [6]interface{}
 	args[0] = ref
 	args[1] = delegate
@@ -184,32 +179,28 @@ func (r *Member) AuthorizedCall(ref core.RecordRef, delegate core.RecordRef, met
 
 	var argsSerialized []byte
 
-	err := proxyctx.Current.Serialize(args, &argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "AuthorizedCall", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
 	ret := [2]interface{}{}
 	var ret0 []byte
 	ret[0] = &ret0
 	var ret1 *foundation.Error
 	ret[1] = &ret1
 
-	err = proxyctx.Current.Deserialize(res, &ret)
+	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	return ret0, ret1
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "AuthorizedCall", argsSerialized)
+	if err != nil {
+		return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
+	}
+
+	err = proxyctx.Current.Deserialize(res, &ret)
+	return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AuthorizedCallNoWait is proxy generated method
-func (r *Member) AuthorizedCallNoWait(ref core.RecordRef, delegate core.RecordRef, method string, params []byte, seed []byte, sign []byte) {
+func (r *Member) AuthorizedCallNoWait(ref core.RecordRef, delegate core.RecordRef, method string, params []byte, seed []byte, sign []byte) error {
 	var args [6]interface{}
 	args[0] = ref
 	args[1] = delegate
@@ -222,11 +213,9 @@ func (r *Member) AuthorizedCallNoWait(ref core.RecordRef, delegate core.RecordRe
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "AuthorizedCall", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "AuthorizedCall", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
@@ -2,6 +2,7 @@ package nodedomain
 
 import (
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
 	"github.com/insolar/insolar/logicrunner/goplugin/proxyctx"
 )
 
@@ -17,24 +18,31 @@ type NodeDomain struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *NodeDomain {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*NodeDomain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &NodeDomain{Reference: ref}
+	return &NodeDomain{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *NodeDomain {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*NodeDomain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &NodeDomain{Reference: ref}
+	return &NodeDomain{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -48,12 +56,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *NodeDomain {
+func GetImplementationFrom(object core.RecordRef) (*NodeDomain, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // NewNodeDomain is constructor
@@ -63,7 +71,7 @@ func NewNodeDomain() *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "NewNodeDomain", argsSerialized: argsSerialized}
@@ -80,37 +88,38 @@ func (r *NodeDomain) GetClass() core.RecordRef {
 }
 
 // RegisterNode is proxy generated method
-func (r *NodeDomain) RegisterNode(pk string, role string) core.RecordRef {
+func (r *NodeDomain) RegisterNode(pk string, role string) (core.RecordRef, error) {
 	var args [2]interface{}
 	args[0] = pk
 	args[1] = role
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 core.RecordRef
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "RegisterNode", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "RegisterNode", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 core.RecordRef
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // RegisterNodeNoWait is proxy generated method
-func (r *NodeDomain) RegisterNodeNoWait(pk string, role string) {
+func (r *NodeDomain) RegisterNodeNoWait(pk string, role string) error {
 	var args [2]interface{}
 	args[0] = pk
 	args[1] = role
@@ -119,44 +128,43 @@ func (r *NodeDomain) RegisterNodeNoWait(pk string, role string) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "RegisterNode", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "RegisterNode", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // RemoveNode is proxy generated method
-func (r *NodeDomain) RemoveNode(nodeRef core.RecordRef) {
+func (r *NodeDomain) RemoveNode(nodeRef core.RecordRef) error {
 	var args [1]interface{}
 	args[0] = nodeRef
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 *foundation.Error
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "RemoveNode", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "RemoveNode", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret0 != nil {
+		err = ret0
 	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // RemoveNodeNoWait is proxy generated method
-func (r *NodeDomain) RemoveNodeNoWait(nodeRef core.RecordRef) {
+func (r *NodeDomain) RemoveNodeNoWait(nodeRef core.RecordRef) error {
 	var args [1]interface{}
 	args[0] = nodeRef
 
@@ -164,17 +172,15 @@ func (r *NodeDomain) RemoveNodeNoWait(nodeRef core.RecordRef) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "RemoveNode", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "RemoveNode", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // IsAuthorized is proxy generated method
-func (r *NodeDomain) IsAuthorized(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) bool {
+func (r *NodeDomain) IsAuthorized(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) (bool, error) {
 	var args [3]interface{}
 	args[0] = nodeRef
 	args[1] = seed
@@ -182,30 +188,31 @@ func (r *NodeDomain) IsAuthorized(nodeRef core.RecordRef, seed []byte, signature
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 bool
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "IsAuthorized", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "IsAuthorized", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 bool
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // IsAuthorizedNoWait is proxy generated method
-func (r *NodeDomain) IsAuthorizedNoWait(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) {
+func (r *NodeDomain) IsAuthorizedNoWait(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) error {
 	var args [3]interface{}
 	args[0] = nodeRef
 	args[1] = seed
@@ -215,17 +222,15 @@ func (r *NodeDomain) IsAuthorizedNoWait(nodeRef core.RecordRef, seed []byte, sig
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "IsAuthorized", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "IsAuthorized", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Authorize is proxy generated method
-func (r *NodeDomain) Authorize(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) (string, core.NodeRole, string) {
+func (r *NodeDomain) Authorize(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) (string, core.NodeRole, string, error) {
 	var args [3]interface{}
 	args[0] = nodeRef
 	args[1] = seed
@@ -233,16 +238,6 @@ func (r *NodeDomain) Authorize(nodeRef core.RecordRef, seed []byte, signatureRaw
 
 	var argsSerialized []byte
 
-	err := proxyctx.Current.Serialize(args, &argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Authorize", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
 	ret := [3]interface{}{}
 	var ret0 string
 	ret[0] = &ret0
@@ -251,16 +246,22 @@ func (r *NodeDomain) Authorize(nodeRef core.RecordRef, seed []byte, signatureRaw
 	var ret2 string
 	ret[2] = &ret2
 
-	err = proxyctx.Current.Deserialize(res, &ret)
+	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	return ret0, ret1, ret2
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "Authorize", argsSerialized)
+	if err != nil {
+		return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
+	}
+
+	err = proxyctx.Current.Deserialize(res, &ret)
+	return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AuthorizeNoWait is proxy generated method
-func (r *NodeDomain) AuthorizeNoWait(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) {
+func (r *NodeDomain) AuthorizeNoWait(nodeRef core.RecordRef, seed []byte, signatureRaw []byte) error {
 	var args [3]interface{}
 	args[0] = nodeRef
 	args[1] = seed
@@ -270,11 +271,9 @@ func (r *NodeDomain) AuthorizeNoWait(nodeRef core.RecordRef, seed []byte, signat
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Authorize", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "Authorize", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
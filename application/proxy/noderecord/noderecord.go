@@ -17,24 +17,31 @@ type NodeRecord struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *NodeRecord {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*NodeRecord, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &NodeRecord{Reference: ref}
+	return &NodeRecord{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *NodeRecord {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*NodeRecord, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &NodeRecord{Reference: ref}
+	return &NodeRecord{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -48,12 +55,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *NodeRecord {
+func GetImplementationFrom(object core.RecordRef) (*NodeRecord, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // NewNodeRecord is constructor
@@ -65,7 +72,7 @@ func NewNodeRecord(pk string, roleS string) *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "NewNodeRecord", argsSerialized: argsSerialized}
@@ -82,181 +89,157 @@ func (r *NodeRecord) GetClass() core.RecordRef {
 }
 
 // GetPublicKey is proxy generated method
-func (r *NodeRecord) GetPublicKey() string {
+func (r *NodeRecord) GetPublicKey() (string, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetPublicKey", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetPublicKey", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 string
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetPublicKeyNoWait is proxy generated method
-func (r *NodeRecord) GetPublicKeyNoWait() {
+func (r *NodeRecord) GetPublicKeyNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetPublicKey", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetPublicKey", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetRole is proxy generated method
-func (r *NodeRecord) GetRole() core.NodeRole {
+func (r *NodeRecord) GetRole() (core.NodeRole, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 core.NodeRole
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetRole", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetRole", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 core.NodeRole
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetRoleNoWait is proxy generated method
-func (r *NodeRecord) GetRoleNoWait() {
+func (r *NodeRecord) GetRoleNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetRole", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetRole", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetRoleAndPublicKey is proxy generated method
-func (r *NodeRecord) GetRoleAndPublicKey() (core.NodeRole, string) {
+func (r *NodeRecord) GetRoleAndPublicKey() (core.NodeRole, string, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
-	err := proxyctx.Current.Serialize(args, &argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetRoleAndPublicKey", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
 	ret := [2]interface{}{}
 	var ret0 core.NodeRole
 	ret[0] = &ret0
 	var ret1 string
 	ret[1] = &ret1
 
-	err = proxyctx.Current.Deserialize(res, &ret)
+	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	return ret0, ret1
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetRoleAndPublicKey", argsSerialized)
+	if err != nil {
+		return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
+	}
+
+	err = proxyctx.Current.Deserialize(res, &ret)
+	return ret0, ret1, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetRoleAndPublicKeyNoWait is proxy generated method
-func (r *NodeRecord) GetRoleAndPublicKeyNoWait() {
+func (r *NodeRecord) GetRoleAndPublicKeyNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetRoleAndPublicKey", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetRoleAndPublicKey", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Destroy is proxy generated method
-func (r *NodeRecord) Destroy() {
+func (r *NodeRecord) Destroy() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := []interface{}{}
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Destroy", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Destroy", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
-	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DestroyNoWait is proxy generated method
-func (r *NodeRecord) DestroyNoWait() {
+func (r *NodeRecord) DestroyNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Destroy", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Destroy", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
@@ -2,6 +2,7 @@ package rootdomain
 
 import (
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
 	"github.com/insolar/insolar/logicrunner/goplugin/proxyctx"
 )
 
@@ -17,24 +18,31 @@ type RootDomain struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *RootDomain {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*RootDomain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &RootDomain{Reference: ref}
+	return &RootDomain{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *RootDomain {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*RootDomain, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &RootDomain{Reference: ref}
+	return &RootDomain{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -48,12 +56,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *RootDomain {
+func GetImplementationFrom(object core.RecordRef) (*RootDomain, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // NewRootDomain is constructor
@@ -63,7 +71,7 @@ func NewRootDomain() *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "NewRootDomain", argsSerialized: argsSerialized}
@@ -80,37 +88,38 @@ func (r *RootDomain) GetClass() core.RecordRef {
 }
 
 // RegisterNode is proxy generated method
-func (r *RootDomain) RegisterNode(publicKey string, role string) string {
+func (r *RootDomain) RegisterNode(publicKey string, role string) (string, error) {
 	var args [2]interface{}
 	args[0] = publicKey
 	args[1] = role
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "RegisterNode", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "RegisterNode", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 string
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // RegisterNodeNoWait is proxy generated method
-func (r *RootDomain) RegisterNodeNoWait(publicKey string, role string) {
+func (r *RootDomain) RegisterNodeNoWait(publicKey string, role string) error {
 	var args [2]interface{}
 	args[0] = publicKey
 	args[1] = role
@@ -119,31 +128,19 @@ func (r *RootDomain) RegisterNodeNoWait(publicKey string, role string) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "RegisterNode", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "RegisterNode", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Authorize is proxy generated method
-func (r *RootDomain) Authorize() (string, core.NodeRole, string) {
+func (r *RootDomain) Authorize() (string, core.NodeRole, string, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
-	err := proxyctx.Current.Serialize(args, &argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Authorize", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
-
 	ret := [3]interface{}{}
 	var ret0 string
 	ret[0] = &ret0
@@ -152,63 +149,68 @@ func (r *RootDomain) Authorize() (string, core.NodeRole, string) {
 	var ret2 string
 	ret[2] = &ret2
 
-	err = proxyctx.Current.Deserialize(res, &ret)
+	err := proxyctx.Current.Serialize(args, &argsSerialized)
+	if err != nil {
+		return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
+	}
+
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Authorize", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	return ret0, ret1, ret2
+	err = proxyctx.Current.Deserialize(res, &ret)
+	return ret0, ret1, ret2, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AuthorizeNoWait is proxy generated method
-func (r *RootDomain) AuthorizeNoWait() {
+func (r *RootDomain) AuthorizeNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Authorize", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Authorize", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // CreateMember is proxy generated method
-func (r *RootDomain) CreateMember(name string, key string) string {
+func (r *RootDomain) CreateMember(name string, key string) (string, error) {
 	var args [2]interface{}
 	args[0] = name
 	args[1] = key
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 string
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "CreateMember", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "CreateMember", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 string
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // CreateMemberNoWait is proxy generated method
-func (r *RootDomain) CreateMemberNoWait(name string, key string) {
+func (r *RootDomain) CreateMemberNoWait(name string, key string) error {
 	var args [2]interface{}
 	args[0] = name
 	args[1] = key
@@ -217,46 +219,45 @@ func (r *RootDomain) CreateMemberNoWait(name string, key string) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "CreateMember", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "CreateMember", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetBalance is proxy generated method
-func (r *RootDomain) GetBalance(reference string) uint {
+func (r *RootDomain) GetBalance(reference string) (uint, error) {
 	var args [1]interface{}
 	args[0] = reference
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetBalance", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetBalance", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 uint
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetBalanceNoWait is proxy generated method
-func (r *RootDomain) GetBalanceNoWait(reference string) {
+func (r *RootDomain) GetBalanceNoWait(reference string) error {
 	var args [1]interface{}
 	args[0] = reference
 
@@ -264,17 +265,15 @@ func (r *RootDomain) GetBalanceNoWait(reference string) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetBalance", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetBalance", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // SendMoney is proxy generated method
-func (r *RootDomain) SendMoney(from string, to string, amount uint) bool {
+func (r *RootDomain) SendMoney(from string, to string, amount uint) (bool, error) {
 	var args [3]interface{}
 	args[0] = from
 	args[1] = to
@@ -282,30 +281,31 @@ func (r *RootDomain) SendMoney(from string, to string, amount uint) bool {
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 bool
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "SendMoney", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "SendMoney", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 bool
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // SendMoneyNoWait is proxy generated method
-func (r *RootDomain) SendMoneyNoWait(from string, to string, amount uint) {
+func (r *RootDomain) SendMoneyNoWait(from string, to string, amount uint) error {
 	var args [3]interface{}
 	args[0] = from
 	args[1] = to
@@ -315,46 +315,45 @@ func (r *RootDomain) SendMoneyNoWait(from string, to string, amount uint) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "SendMoney", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "SendMoney", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DumpUserInfo is proxy generated method
-func (r *RootDomain) DumpUserInfo(reference string) []byte {
+func (r *RootDomain) DumpUserInfo(reference string) ([]byte, error) {
 	var args [1]interface{}
 	args[0] = reference
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 []byte
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "DumpUserInfo", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "DumpUserInfo", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 []byte
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DumpUserInfoNoWait is proxy generated method
-func (r *RootDomain) DumpUserInfoNoWait(reference string) {
+func (r *RootDomain) DumpUserInfoNoWait(reference string) error {
 	var args [1]interface{}
 	args[0] = reference
 
@@ -362,56 +361,53 @@ func (r *RootDomain) DumpUserInfoNoWait(reference string) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "DumpUserInfo", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "DumpUserInfo", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DumpAllUsers is proxy generated method
-func (r *RootDomain) DumpAllUsers() []byte {
+func (r *RootDomain) DumpAllUsers() ([]byte, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 []byte
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "DumpAllUsers", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "DumpAllUsers", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 []byte
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // DumpAllUsersNoWait is proxy generated method
-func (r *RootDomain) DumpAllUsersNoWait() {
+func (r *RootDomain) DumpAllUsersNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "DumpAllUsers", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "DumpAllUsers", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
 	"github.com/insolar/insolar/logicrunner/goplugin/proxyctx"
 )
 
@@ -17,24 +18,31 @@ type Wallet struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized  []byte
+	err             error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *Wallet {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*Wallet, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Wallet{Reference: ref}
+	return &Wallet{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *Wallet {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*Wallet, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &Wallet{Reference: ref}
+	return &Wallet{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -48,12 +56,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *Wallet {
+func GetImplementationFrom(object core.RecordRef) (*Wallet, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 // New is constructor
@@ -64,7 +72,7 @@ func New(balance uint) *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "New", argsSerialized: argsSerialized}
@@ -81,37 +89,38 @@ func (r *Wallet) GetClass() core.RecordRef {
 }
 
 // Allocate is proxy generated method
-func (r *Wallet) Allocate(amount uint, to *core.RecordRef) core.RecordRef {
+func (r *Wallet) Allocate(amount uint, to *core.RecordRef) (core.RecordRef, error) {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = to
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 core.RecordRef
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Allocate", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Allocate", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 core.RecordRef
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AllocateNoWait is proxy generated method
-func (r *Wallet) AllocateNoWait(amount uint, to *core.RecordRef) {
+func (r *Wallet) AllocateNoWait(amount uint, to *core.RecordRef) error {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = to
@@ -120,45 +129,44 @@ func (r *Wallet) AllocateNoWait(amount uint, to *core.RecordRef) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Allocate", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Allocate", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Receive is proxy generated method
-func (r *Wallet) Receive(amount uint, from *core.RecordRef) {
+func (r *Wallet) Receive(amount uint, from *core.RecordRef) error {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = from
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 *foundation.Error
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Receive", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Receive", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret0 != nil {
+		err = ret0
 	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // ReceiveNoWait is proxy generated method
-func (r *Wallet) ReceiveNoWait(amount uint, from *core.RecordRef) {
+func (r *Wallet) ReceiveNoWait(amount uint, from *core.RecordRef) error {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = from
@@ -167,45 +175,44 @@ func (r *Wallet) ReceiveNoWait(amount uint, from *core.RecordRef) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Receive", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Receive", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Transfer is proxy generated method
-func (r *Wallet) Transfer(amount uint, to *core.RecordRef) {
+func (r *Wallet) Transfer(amount uint, to *core.RecordRef) error {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = to
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 *foundation.Error
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Transfer", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Transfer", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret0 != nil {
+		err = ret0
 	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // TransferNoWait is proxy generated method
-func (r *Wallet) TransferNoWait(amount uint, to *core.RecordRef) {
+func (r *Wallet) TransferNoWait(amount uint, to *core.RecordRef) error {
 	var args [2]interface{}
 	args[0] = amount
 	args[1] = to
@@ -214,44 +221,43 @@ func (r *Wallet) TransferNoWait(amount uint, to *core.RecordRef) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Transfer", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Transfer", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // Accept is proxy generated method
-func (r *Wallet) Accept(aRef *core.RecordRef) {
+func (r *Wallet) Accept(aRef *core.RecordRef) error {
 	var args [1]interface{}
 	args[0] = aRef
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 *foundation.Error
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "Accept", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "Accept", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret0 != nil {
+		err = ret0
 	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // AcceptNoWait is proxy generated method
-func (r *Wallet) AcceptNoWait(aRef *core.RecordRef) {
+func (r *Wallet) AcceptNoWait(aRef *core.RecordRef) error {
 	var args [1]interface{}
 	args[0] = aRef
 
@@ -259,99 +265,95 @@ func (r *Wallet) AcceptNoWait(aRef *core.RecordRef) {
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "Accept", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "Accept", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetTotalBalance is proxy generated method
-func (r *Wallet) GetTotalBalance() uint {
+func (r *Wallet) GetTotalBalance() (uint, error) {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [2]interface{}{}
+	var ret0 uint
+	ret[0] = &ret0
+	var ret1 *foundation.Error
+	ret[1] = &ret1
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "GetTotalBalance", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, true, "GetTotalBalance", argsSerialized)
 	if err != nil {
-		panic(err)
+		return ret0, proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := [1]interface{}{}
-	var ret0 uint
-	ret[0] = &ret0
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret1 != nil {
+		err = ret1
 	}
-
-	return ret0
+	return ret0, proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // GetTotalBalanceNoWait is proxy generated method
-func (r *Wallet) GetTotalBalanceNoWait() {
+func (r *Wallet) GetTotalBalanceNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "GetTotalBalance", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, true, "GetTotalBalance", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // ReturnAndDeleteExpiredAllowances is proxy generated method
-func (r *Wallet) ReturnAndDeleteExpiredAllowances() {
+func (r *Wallet) ReturnAndDeleteExpiredAllowances() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
+	ret := [1]interface{}{}
+	var ret0 *foundation.Error
+	ret[0] = &ret0
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "ReturnAndDeleteExpiredAllowances", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, false, "ReturnAndDeleteExpiredAllowances", argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	ret := []interface{}{}
-
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	if err == nil && ret0 != nil {
+		err = ret0
 	}
-
-	return
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 
 // ReturnAndDeleteExpiredAllowancesNoWait is proxy generated method
-func (r *Wallet) ReturnAndDeleteExpiredAllowancesNoWait() {
+func (r *Wallet) ReturnAndDeleteExpiredAllowancesNoWait() error {
 	var args [0]interface{}
 
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "ReturnAndDeleteExpiredAllowances", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, false, "ReturnAndDeleteExpiredAllowances", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
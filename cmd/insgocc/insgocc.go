@@ -17,6 +17,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -24,7 +26,10 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/insolar/insolar/api"
+	"github.com/insolar/insolar/api/requesters"
 	"github.com/insolar/insolar/logicrunner/goplugin/preprocessor"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -183,69 +188,277 @@ func main() {
 				fmt.Println("compile command should be followed by exactly one file name to compile")
 				os.Exit(1)
 			}
-			parsed, err := preprocessor.ParseFile(args[0])
+
+			_, err = compile(args[0], path.Join(dir, outdir))
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
+		},
+	}
+	cmdCompile.Flags().StringVarP(&outdir, "output-dir", "o", ".", "output dir (default .)")
 
-			// make temporary dir
-			tmpDir, err := ioutil.TempDir("", "test-")
-			if err != nil {
-				fmt.Println(err)
+	var apiURL, classRef, keyFile, migrationFile string
+	var cmdDeploy = &cobra.Command{
+		Use:   "deploy [flags] <file name to deploy>",
+		Short: "Compile contract and deploy it to the network",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				fmt.Println("deploy command should be followed by exactly one file name to deploy")
 				os.Exit(1)
 			}
-			defer os.RemoveAll(tmpDir) // nolint: errcheck
 
-			name := parsed.ContractName()
-
-			contract, err := os.Create(filepath.Join(tmpDir, name+".go"))
+			ref, err := deploy(args[0], migrationFile, apiURL, classRef, keyFile)
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
-			defer contract.Close()
-
-			parsed.ChangePackageToMain()
-			err = parsed.Write(contract)
+			fmt.Println(ref)
+		},
+	}
+	cmdDeploy.Flags().StringVarP(&apiURL, "api", "a", "http://localhost:19191/api/v1", "API endpoint of a node")
+	cmdDeploy.Flags().StringVarP(&classRef, "class", "c", "", "reference to class to update (new class is activated if empty)")
+	cmdDeploy.Flags().StringVarP(&keyFile, "key", "k", "", "path to user config with private key of the deployer")
+	cmdDeploy.Flags().StringVarP(&migrationFile, "migration", "m", "", "migration of objects of the class to update")
+
+	clientOut := newOutputFlag("-")
+	var cmdClient = &cobra.Command{
+		Use:   "client [flags] <file name to process>",
+		Short: "Generate typed client of the contract",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				fmt.Println("client command should be followed by exactly one file name to process")
+				os.Exit(1)
+			}
+			parsed, err := preprocessor.ParseFile(args[0])
 			if err != nil {
-				fmt.Println(err)
+				fmt.Println(errors.Wrap(err, "couldn't parse"))
 				os.Exit(1)
 			}
 
-			wrapper, err := os.Create(filepath.Join(tmpDir, name+".wrapper.go"))
+			err = parsed.WriteClient(clientOut.writer)
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
-			defer wrapper.Close()
+		},
+	}
+	cmdClient.Flags().VarP(clientOut, "output", "o", "output file (use - for STDOUT)")
 
-			err = parsed.WriteWrapper(wrapper)
+	abiOut := newOutputFlag("-")
+	var cmdABI = &cobra.Command{
+		Use:   "abi [flags] <file name to process>",
+		Short: "Generate contract's ABI",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				fmt.Println("abi command should be followed by exactly one file name to process")
+				os.Exit(1)
+			}
+			parsed, err := preprocessor.ParseFile(args[0])
 			if err != nil {
-				fmt.Println(err)
+				fmt.Println(errors.Wrap(err, "couldn't parse"))
 				os.Exit(1)
 			}
 
-			err = os.Chdir(tmpDir)
+			err = parsed.WriteABI(abiOut.writer)
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 			}
+		},
+	}
+	cmdABI.Flags().VarP(abiOut, "output", "o", "output file (use - for STDOUT)")
 
-			out, err := exec.Command("go", "build", "-buildmode=plugin", "-o", path.Join(dir, outdir, name+".so")).CombinedOutput()
+	var cmdCheck = &cobra.Command{
+		Use:   "check [flags] <file name to check>",
+		Short: "Check contract for unsafe and non-deterministic code",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				fmt.Println("check command should be followed by exactly one file name to check")
+				os.Exit(1)
+			}
+			parsed, err := preprocessor.ParseFile(args[0])
 			if err != nil {
-				fmt.Println(errors.Wrap(err, "can't build contract: "+string(out)))
+				fmt.Println(errors.Wrap(err, "couldn't parse"))
+				os.Exit(1)
+			}
+
+			diagnostics := parsed.Check()
+			for _, d := range diagnostics {
+				fmt.Println(d)
+			}
+			if len(diagnostics) > 0 {
 				os.Exit(1)
 			}
 		},
 	}
-	cmdCompile.Flags().StringVarP(&outdir, "output-dir", "o", ".", "output dir (default .)")
 
 	var rootCmd = &cobra.Command{Use: "insgocc"}
-	rootCmd.AddCommand(cmdProxy, cmdWrapper, cmdImports, cmdCompile)
+	rootCmd.AddCommand(cmdProxy, cmdWrapper, cmdImports, cmdCompile, cmdDeploy, cmdCheck, cmdClient, cmdABI)
 	err := rootCmd.Execute()
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// compile builds plugin of the contract into the directory, returns path to the plugin
+func compile(file string, outdir string) (string, error) {
+	parsed, err := preprocessor.ParseFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	// make temporary dir
+	tmpDir, err := ioutil.TempDir("", "test-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+
+	name := parsed.ContractName()
+
+	contract, err := os.Create(filepath.Join(tmpDir, name+".go"))
+	if err != nil {
+		return "", err
+	}
+	defer contract.Close()
+
+	parsed.ChangePackageToMain()
+	err = parsed.Write(contract)
+	if err != nil {
+		return "", err
+	}
+
+	wrapper, err := os.Create(filepath.Join(tmpDir, name+".wrapper.go"))
+	if err != nil {
+		return "", err
+	}
+	defer wrapper.Close()
+
+	err = parsed.WriteWrapper(wrapper)
+	if err != nil {
+		return "", err
+	}
+
+	plugin := path.Join(outdir, name+".so")
+	build := exec.Command("go", "build", "-buildmode=plugin", "-o", plugin)
+	build.Dir = tmpDir
+	out, err := build.CombinedOutput()
+	if err != nil {
+		return "", errors.Wrap(err, "can't build contract: "+string(out))
+	}
+	return plugin, nil
+}
+
+// build checks the contract for unsafe and non-deterministic code and compiles it into the directory, returns
+// plugin of the contract
+func build(file string, outdir string) ([]byte, error) {
+	parsed, err := preprocessor.ParseFile(file)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics := parsed.Check()
+	if len(diagnostics) > 0 {
+		messages := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			messages[i] = d.String()
+		}
+		return nil, errors.New("contract is not allowed: " + strings.Join(messages, "; "))
+	}
+
+	plugin, err := compile(file, outdir)
+	if err != nil {
+		return nil, err
+	}
+	code, err := ioutil.ReadFile(plugin)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't read plugin")
+	}
+	return code, nil
+}
+
+// deploy checks and compiles the contract and sends the plugin with contract's ABI to a node, migration of objects
+// is checked, compiled and sent with them if `migrationFile` is set. Node stores plugins as is, so the check here is
+// the only one. Request is signed with the key from `keyFile`, returns reference to the class
+func deploy(file string, migrationFile string, apiURL string, class string, keyFile string) (string, error) {
+	if migrationFile != "" && class == "" {
+		return "", errors.New("migration can be deployed only with update of the class")
+	}
+	userCfg, err := requesters.ReadUserConfigFromFile(keyFile)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't read deployer's key")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "deploy-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+
+	params := &api.Params{QType: "deploy_contract", Reference: class}
+	params.Code, err = build(file, tmpDir)
+	if err != nil {
+		return "", err
+	}
+	if migrationFile != "" {
+		migrationDir := filepath.Join(tmpDir, "migration")
+		err = os.Mkdir(migrationDir, 0755)
+		if err != nil {
+			return "", err
+		}
+		params.Migration, err = build(migrationFile, migrationDir)
+		if err != nil {
+			return "", errors.Wrap(err, "couldn't build migration")
+		}
+	}
+
+	parsed, err := preprocessor.ParseFile(file)
+	if err != nil {
+		return "", err
+	}
+	var abi bytes.Buffer
+	err = parsed.WriteABI(&abi)
+	if err != nil {
+		return "", err
+	}
+	params.ABI = abi.Bytes()
+
+	params.Seed, err = requesters.GetSeed(apiURL)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get seed")
+	}
+	params.Signature, params.PublicKey, err = requesters.SignDeploy(userCfg, params)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := requesters.GetResponseBody(apiURL, requesters.PostParams{
+		"query_type": params.QType,
+		"code":       params.Code,
+		"abi":        params.ABI,
+		"migration":  params.Migration,
+		"reference":  params.Reference,
+		"seed":       params.Seed,
+		"signature":  params.Signature,
+		"public_key": params.PublicKey,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't send contract")
+	}
+
+	var resp struct {
+		Reference string `json:"reference"`
+		Error     *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't parse response")
+	}
+	if resp.Error != nil {
+		return "", errors.New(resp.Error.Message)
+	}
+	return resp.Reference, nil
+}
@@ -17,8 +17,47 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStub(t *testing.T) {
 }
+
+const unsafeContract = `package main
+
+import (
+	"os"
+
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+type A struct {
+	foundation.BaseContract
+}
+
+func (a *A) Home() string {
+	return os.Getenv("HOME")
+}
+`
+
+func TestBuildChecksContract(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "insgocc-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+
+	file := filepath.Join(tmpDir, "contract.go")
+	err = ioutil.WriteFile(file, []byte(unsafeContract), 0644)
+	require.NoError(t, err)
+
+	_, err = build(file, tmpDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "import of \"os\" is not allowed")
+	_, err = os.Stat(filepath.Join(tmpDir, "main.so"))
+	assert.True(t, os.IsNotExist(err), "unsafe contract shouldn't be compiled")
+}
@@ -35,6 +35,7 @@ func main() {
 	path := pflag.StringP("directory", "d", "", "directory where to store code of go plugins")
 	rpcAddress := pflag.String("rpc", "localhost:7778", "address and port of RPC API")
 	rpcProtocol := pflag.String("rpc-proto", "tcp", "protocol of RPC API")
+	cacheSize := pflag.Int64("cache-size", 1<<30, "maximum size of code cache in bytes, zero disables the limit")
 	pflag.Parse()
 
 	err := log.SetLevel("Debug")
@@ -52,7 +53,11 @@ func main() {
 		*path = tmpDir
 	}
 
-	insider := ginsider.NewGoInsider(*path, *rpcProtocol, *rpcAddress)
+	insider, err := ginsider.NewGoInsider(*path, *rpcProtocol, *rpcAddress, *cacheSize)
+	if err != nil {
+		log.Fatal("Couldn't create GoInsider: ", err)
+		os.Exit(1)
+	}
 	proxyctx.Current = insider
 
 	err = rpc.Register(&ginsider.RPC{GI: insider})
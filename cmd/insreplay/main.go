@@ -0,0 +1,165 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger"
+	"github.com/insolar/insolar/log"
+	"github.com/insolar/insolar/logicrunner"
+	"github.com/insolar/insolar/messagebus"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	configPath := pflag.StringP("config", "c", "", "path to config file of the recorded node")
+	recordPath := pflag.StringP("record", "r", "", "path to file with recorded messages")
+	pflag.Parse()
+
+	if *recordPath == "" {
+		log.Fatal("record file is required")
+	}
+
+	cfgHolder := configuration.NewHolder()
+	var err error
+	if *configPath != "" {
+		err = cfgHolder.LoadFromFile(*configPath)
+	} else {
+		err = cfgHolder.Load()
+	}
+	if err != nil {
+		log.Warnln("failed to load configuration from file: ", err.Error())
+	}
+	cfg := cfgHolder.Configuration
+
+	f, err := os.Open(*recordPath)
+	if err != nil {
+		log.Fatalln("failed to open record file: ", err.Error())
+	}
+	records, err := messagebus.ReadRecords(f)
+	f.Close()
+	if err != nil {
+		log.Fatalln("failed to read records: ", err.Error())
+	}
+
+	// replay mutates storage, so it's done on a copy to keep data of the recorded node intact
+	dataDir, err := ioutil.TempDir("", "insreplay-")
+	if err != nil {
+		log.Fatalln("failed to create temporary data directory: ", err.Error())
+	}
+	divergences, err := replay(cfg, dataDir, records)
+	if rmErr := os.RemoveAll(dataDir); rmErr != nil {
+		log.Errorln("failed to remove temporary data directory: ", rmErr.Error())
+	}
+	if err != nil {
+		log.Fatalln("replay failed: ", err.Error())
+	}
+	for _, div := range divergences {
+		fmt.Printf(
+			"record %d (pulse %d, sender %s) diverged: recorded error %q, replayed error %q\n",
+			div.Index, div.Record.Pulse.PulseNumber, div.Record.Sender, div.Record.Error, div.Error,
+		)
+	}
+	fmt.Printf("replayed %d records, %d diverged\n", len(records), len(divergences))
+	if len(divergences) > 0 {
+		os.Exit(1)
+	}
+}
+
+// replay plays records on components of the recorded node started on a copy of its storage in dataDir.
+func replay(cfg configuration.Configuration, dataDir string, records []messagebus.Record) ([]messagebus.Divergence, error) {
+	err := copyDir(cfg.Ledger.Storage.DataDirectory, dataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy data directory")
+	}
+	cfg.Ledger.Storage.DataDirectory = dataDir
+
+	player := messagebus.NewPlayer(core.NewRefFromBase58(cfg.Node.Node.ID))
+	l, err := ledger.NewLedger(cfg.Ledger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Ledger")
+	}
+	lr, err := logicrunner.NewLogicRunner(&cfg.LogicRunner)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create LogicRunner")
+	}
+	components := []core.Component{player, l, lr}
+	c := core.Components{MessageBus: player, Ledger: l, LogicRunner: lr}
+	started := 0
+	defer func() {
+		for i := started - 1; i >= 0; i-- {
+			if stopErr := components[i].Stop(); stopErr != nil {
+				log.Errorln("failed to stop component: ", stopErr.Error())
+			}
+		}
+	}()
+	for _, component := range components {
+		err = component.Start(c)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start component")
+		}
+		started++
+	}
+
+	return player.Play(records)
+}
+
+// copyDir copies files of src directory to dst directory, missing src is treated as empty.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == src {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -22,17 +22,21 @@ import (
 
 // APIRunner holds configuration for api
 type APIRunner struct {
-	Port     uint
-	Location string
-	Info     string
+	Port        uint
+	Location    string
+	Info        string
+	CallTimeout int32    // ms
+	Deployers   []string // public keys of trusted deployers of contracts, deploy is disabled if empty
 }
 
 // NewAPIRunner creates new api config
 func NewAPIRunner() APIRunner {
 	return APIRunner{
-		Port:     19191,
-		Location: "/api/v1",
-		Info:     "/api/v1/info/",
+		Port:        19191,
+		Location:    "/api/v1",
+		Info:        "/api/v1/info/",
+		CallTimeout: 30000,
+		Deployers:   []string{},
 	}
 }
 
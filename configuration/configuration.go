@@ -32,6 +32,7 @@ type Configuration struct {
 	Node        NodeNetwork
 	Service     ServiceNetwork
 	Ledger      Ledger
+	MessageBus  MessageBus
 	Log         Log
 	Metrics     Metrics
 	LogicRunner LogicRunner
@@ -54,6 +55,7 @@ func NewConfiguration() Configuration {
 		Node:        NewNodeNetwork(),
 		Service:     NewServiceNetwork(),
 		Ledger:      NewLedger(),
+		MessageBus:  NewMessageBus(),
 		Log:         NewLog(),
 		Metrics:     NewMetrics(),
 		LogicRunner: NewLogicRunner(),
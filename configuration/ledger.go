@@ -41,6 +41,9 @@ type Ledger struct {
 	Storage Storage
 	// JetCoordinator defines jet coordinator configuration.
 	JetCoordinator JetCoordinator
+	// Deployers are references of nodes allowed to deploy code and classes without being their virtual executor,
+	// e.g. nodes serving deploy_contract API queries.
+	Deployers []string
 }
 
 // NewLedger creates new default Ledger configuration.
@@ -67,5 +70,7 @@ func NewLedger() Ledger {
 				int(core.RoleLightValidator):   3,
 			},
 		},
+
+		Deployers: []string{},
 	}
 }
@@ -27,6 +27,25 @@ type LogicRunner struct {
 	BuiltIn *BuiltIn
 	// GoPlugin - configuration of executor based on Go plugins
 	GoPlugin *GoPlugin
+	// QueueLength - maximum count of calls waiting for execution of the same object,
+	// calls are rejected when the queue is full, zero disables queueing
+	QueueLength int
+	// QueueTimeout - maximum time in milliseconds a call waits for execution of the object,
+	// zero disables the timeout
+	QueueTimeout int
+	// Limits - resources available to one contract call
+	Limits *Limits
+}
+
+// Limits of resources available to one contract call, zero disables a limit. Limits are checked while
+// the call is executed, runner executing the call beyond its limits is restarted.
+type Limits struct {
+	// CallTime - maximum execution time of a call in milliseconds
+	CallTime int32
+	// Memory - maximum count of bytes allocated by a call
+	Memory uint64
+	// CallDepth - maximum depth of nested calls
+	CallDepth int
 }
 
 // BuiltIn configuration, no options at the moment
@@ -39,6 +58,13 @@ type GoPlugin struct {
 	// RunnerProtocol - protocol (network) of above address,
 	// e.g. "tcp", "unix"... see `net.Dial`
 	RunnerProtocol string
+	// RunnerPath - path to `insgorund` binary, runners are spawned and restarted
+	// by the node if it's set, otherwise external runners are used
+	RunnerPath string
+	// RunnerCount - count of runners, each next runner listens on the next port
+	// or on the socket with index suffix, e.g. "runner.sock.1". Runner executes
+	// contract code of one call at a time, so its allocations are accounted to the call
+	RunnerCount int
 }
 
 // NewLogicRunner - returns default config of the logic runner
@@ -50,6 +76,14 @@ func NewLogicRunner() LogicRunner {
 		GoPlugin: &GoPlugin{
 			RunnerListen:   "127.0.0.1:7777",
 			RunnerProtocol: "tcp",
+			RunnerCount:    1,
+		},
+		QueueLength:  100,
+		QueueTimeout: 60000,
+		Limits: &Limits{
+			CallTime:  600000,
+			Memory:    512 * 1024 * 1024,
+			CallDepth: 32,
 		},
 	}
 }
@@ -0,0 +1,50 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package configuration
+
+// MessageBus holds configuration for message bus.
+type MessageBus struct {
+	// CascadeReplicationFactor is the number of children nodes of the each node of the cascade.
+	CascadeReplicationFactor uint
+	// CascadeQuorum is a percent of cascade nodes which should reply successfully for delivery to be successful.
+	CascadeQuorum int
+	// RecordFile is a path of the file delivered messages and their replies are recorded to. Empty path disables
+	// recording.
+	RecordFile string
+	// SendTimeout is a maximum time in milliseconds of a single delivery attempt of messages sent without options,
+	// zero disables the timeout.
+	SendTimeout int
+	// CallTimeout is SendTimeout for contract calls, they wait for execution of the call.
+	CallTimeout int
+	// SendRetries is a count of additional attempts after failed delivery of idempotent messages sent without
+	// options.
+	SendRetries int
+	// SendRetryDelay is a delay in milliseconds before the first retry, it is doubled before every next one.
+	SendRetryDelay int
+}
+
+// NewMessageBus creates new default MessageBus configuration.
+func NewMessageBus() MessageBus {
+	return MessageBus{
+		CascadeReplicationFactor: 2,
+		CascadeQuorum:            51,
+		SendTimeout:              10000,
+		CallTimeout:              720000,
+		SendRetries:              3,
+		SendRetryDelay:           100,
+	}
+}
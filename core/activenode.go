@@ -42,6 +42,6 @@ type ActiveNode struct {
 	State NodeState
 	// JetRoles is the set of candidate JetRoles for the node
 	Role NodeRole
-	// PublicKey is the public key of the node
+	// PublicKey is the PEM encoded public key of the node
 	PublicKey []byte
 }
@@ -23,4 +23,6 @@ var (
 	ErrUnknown = errors.New("unknown error")
 	// ErrDeactivated returned when requested object is deactivated.
 	ErrDeactivated = errors.New("object is deactivated")
+	// ErrUnauthorized returned when message sender is not authorized to perform the operation.
+	ErrUnauthorized = errors.New("sender is not authorized")
 )
@@ -61,6 +61,9 @@ type JetCoordinator interface {
 
 	// QueryRole returns node refs responsible for role bound operations for given object and pulse.
 	QueryRole(role JetRole, obj RecordRef, pulse PulseNumber) ([]RecordRef, error)
+
+	// QueryRoleNodes returns refs of all nodes acting in role on given pulse regardless of object affinity.
+	QueryRoleNodes(role JetRole, pulse PulseNumber) ([]RecordRef, error)
 }
 
 // ArtifactManager is a high level storage interface.
@@ -102,10 +105,11 @@ type ArtifactManager interface {
 	// During iteration children refs will be fetched from remote source (parent object).
 	GetChildren(parent RecordRef, pulse *PulseNumber) (RefIterator, error)
 
-	// DeclareType creates new type record in storage.
+	// DeclareType creates new type record in storage. Provided reference should be a reference to the head of the
+	// class the type describes. The type becomes the latest type of the class.
 	//
-	// Type is a contract interface. It contains one method signature.
-	DeclareType(domain, request RecordRef, typeDec []byte) (*RecordRef, error)
+	// Type is a contract interface. It contains signatures of the class methods and constructors.
+	DeclareType(domain, request, class RecordRef, typeDec []byte) (*RecordRef, error)
 
 	// DeployCode creates new code record in storage.
 	//
@@ -113,9 +117,10 @@ type ArtifactManager interface {
 	DeployCode(domain, request RecordRef, code []byte, machineType MachineType) (*RecordRef, error)
 
 	// ActivateClass creates activate class record in storage. Provided code reference will be used as a class code.
+	// Owner is the public key of the class deployer, it's empty for classes which can't be updated by users.
 	//
 	// Request reference will be this class'es identifier and referred as "class head".
-	ActivateClass(domain, request, code RecordRef) (*RecordID, error)
+	ActivateClass(domain, request, code RecordRef, owner string) (*RecordID, error)
 
 	// DeactivateClass creates deactivate record in storage. Provided reference should be a reference to the head of
 	// the class. If class is already deactivated, an error should be returned.
@@ -150,6 +155,23 @@ type ArtifactManager interface {
 	//
 	// Returned reference will be the latest object state (exact) reference.
 	UpdateObject(domain, request, obj RecordRef, memory []byte) (*RecordID, error)
+
+	// MigrateObject creates amend object record in storage like UpdateObject does. Provided memory is the object memory
+	// migrated to provided class state.
+	//
+	// Returned reference will be the latest object state (exact) reference.
+	MigrateObject(domain, request, obj RecordRef, classState RecordID, memory []byte) (*RecordID, error)
+
+	// EmitEvent creates event record in storage. Provided reference should be a reference to the head of the object
+	// which published the event. Payload is CBOR encoded.
+	EmitEvent(domain, request, obj RecordRef, name string, payload []byte) (*RecordID, error)
+}
+
+// Event is an event published by a contract.
+type Event struct {
+	ID      RecordID // Event record
+	Name    string
+	Payload []byte // CBOR encoded payload
 }
 
 // CodeDescriptor represents meta info required to fetch all code data.
@@ -174,6 +196,15 @@ type ClassDescriptor interface {
 
 	// CodeDescriptor returns descriptor for fetching class's code data.
 	CodeDescriptor() CodeDescriptor
+
+	// PrevStateID returns reference to class state amended by represented state, nil for the first state.
+	PrevStateID() *RecordID
+
+	// Migrations returns references to code migrating objects memory from the previous class state.
+	Migrations() []RecordRef
+
+	// Owner returns public key of the class deployer, empty if the class has no owner.
+	Owner() string
 }
 
 // ObjectDescriptor represents meta info required to fetch all object data.
@@ -187,6 +218,9 @@ type ObjectDescriptor interface {
 	// Memory fetches object memory from storage.
 	Memory() []byte
 
+	// ClassStateID returns reference to class state which object memory conforms to, nil if it's unknown.
+	ClassStateID() *RecordID
+
 	// ClassDescriptor returns descriptor for fetching object's class data.
 	ClassDescriptor(state *RecordRef) (ClassDescriptor, error)
 
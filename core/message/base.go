@@ -20,17 +20,32 @@ package message
 import (
 	"bytes"
 	"crypto/ecdsa"
-	"encoding/gob"
 	"io"
 	"io/ioutil"
 
 	ecdsa2 "github.com/insolar/insolar/cryptohelpers/ecdsa"
 	"github.com/insolar/insolar/log"
 	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
 
 	"github.com/insolar/insolar/core"
 )
 
+const (
+	// WireVersion is the version of message encoding written by this node.
+	WireVersion = 1
+	// MinWireVersion is the oldest version of message encoding this node decodes. Newer versions are decoded too:
+	// adjacent versions may only add or remove fields, unknown fields are skipped and missing ones are left empty.
+	MinWireVersion = 1
+)
+
+// cborH encodes messages as CBOR maps keyed by field names. Canonical mode keeps encoding stable for signing.
+var cborH = &codec.CborHandle{}
+
+func init() {
+	cborH.Canonical = true
+}
+
 // GetEmptyMessage constructs specified message
 func getEmptyMessage(mt core.MessageType) (core.Message, error) {
 	switch mt {
@@ -45,6 +60,10 @@ func getEmptyMessage(mt core.MessageType) (core.Message, error) {
 		return &ValidateCaseBind{}, nil
 	case core.TypeValidationResults:
 		return  &ValidationResults{}, nil
+	case core.TypeExecutorHandoff:
+		return &ExecutorHandoff{}, nil
+	case core.TypeExecutionCompleted:
+		return &ExecutionCompleted{}, nil
 
 	// Ledger
 	case core.TypeRequestCall:
@@ -81,6 +100,22 @@ func getEmptyMessage(mt core.MessageType) (core.Message, error) {
 		return &RegisterChild{}, nil
 	case core.TypeJetDrop:
 		return &JetDrop{}, nil
+	case core.TypeEmitEvent:
+		return &EmitEvent{}, nil
+	case core.TypeGetEvents:
+		return &GetEvents{}, nil
+	case core.TypeGetClassType:
+		return &GetClassType{}, nil
+	case core.TypeApplyJournal:
+		return &ApplyJournal{}, nil
+
+	// Bootstrap
+	case core.TypeBootstrapRequest:
+		return &BootstrapRequest{}, nil
+
+	// Bus
+	case core.TypeSignedMessage:
+		return &SignedMessage{}, nil
 	default:
 		return nil, errors.Errorf("unimplemented message type %d", mt)
 	}
@@ -100,32 +135,37 @@ func MustSerializeBytes(msg core.Message) []byte {
 }
 
 // Serialize returns io.Reader on buffer with encoded core.Message.
+//
+// Encoded message is a version byte, a message type byte and CBOR encoded message body.
 func Serialize(msg core.Message) (io.Reader, error) {
 	buff := &bytes.Buffer{}
-	_, err := buff.Write([]byte{byte(msg.Type())})
+	_, err := buff.Write([]byte{WireVersion, byte(msg.Type())})
 	if err != nil {
 		return nil, err
 	}
 
-	enc := gob.NewEncoder(buff)
+	enc := codec.NewEncoder(buff, cborH)
 	err = enc.Encode(msg)
 	return buff, err
 }
 
 // Deserialize returns decoded message.
 func Deserialize(buff io.Reader) (core.Message, error) {
-	b := make([]byte, 1)
-	_, err := buff.Read(b)
+	b := make([]byte, 2)
+	_, err := io.ReadFull(buff, b)
 	if err != nil {
 		return nil, errors.New("too short slice for deserialize message")
 	}
+	if b[0] < MinWireVersion {
+		return nil, errors.Errorf("unsupported message encoding version %d", b[0])
+	}
 
-	msg, err := getEmptyMessage(core.MessageType(b[0]))
+	msg, err := getEmptyMessage(core.MessageType(b[1]))
 	if err != nil {
 		return nil, err
 	}
-	enc := gob.NewDecoder(buff)
-	err = enc.Decode(msg)
+	dec := codec.NewDecoder(buff, cborH)
+	err = dec.Decode(msg)
 	return msg, err
 }
 
@@ -138,6 +178,15 @@ func ToBytes(msg core.Message) ([]byte, error) {
 	return ioutil.ReadAll(reqBuff)
 }
 
+// IsIdempotent returns true if message can be safely delivered several times, e.g. ledger reads.
+func IsIdempotent(msg core.Message) bool {
+	switch msg.(type) {
+	case *GetCode, *GetClass, *GetObject, *GetDelegate, *GetChildren, *GetEvents, *GetClassType:
+		return true
+	}
+	return false
+}
+
 // SignMessage tries to sign a core.Message.
 func SignMessage(msg core.Message, key *ecdsa.PrivateKey) error {
 	serialized, err := ToBytes(msg)
@@ -152,49 +201,17 @@ func SignMessage(msg core.Message, key *ecdsa.PrivateKey) error {
 	return nil
 }
 
-// SignIsCorrect checks if a sign is correct.
-func SignIsCorrect(msg core.Message, key *ecdsa.PrivateKey) bool {
-	sign := msg.GetSign()
-	msg.SetSign(make([]byte, 0))
-
+// SignIsCorrect checks if a sign of the message matches provided PEM encoded public key.
+func SignIsCorrect(msg core.Message, key string) bool {
 	serialized, err := ToBytes(msg)
 	if err != nil {
 		log.Error(err, "filed to serialize message")
 		return false
 	}
-	newSign, err := ecdsa2.Sign(serialized, key)
+	ok, err := ecdsa2.Verify(serialized, msg.GetSign(), key)
 	if err != nil {
-		log.Error(err, "failed to sign a message")
+		log.Error(err, "failed to verify a message sign")
 		return false
 	}
-	return bytes.Equal(sign, newSign)
-}
-
-func init() {
-	// Bootstrap
-	gob.Register(&BootstrapRequest{})
-	// Logicrunner
-	gob.Register(&CallConstructor{})
-	gob.Register(&CallMethod{})
-	gob.Register(&ExecutorResults{})
-	gob.Register(&ValidateCaseBind{})
-	gob.Register(&ValidationResults{})
-
-	// Ledger
-	gob.Register(&RequestCall{})
-	gob.Register(&GetCode{})
-	gob.Register(&GetClass{})
-	gob.Register(&GetObject{})
-	gob.Register(&GetDelegate{})
-	gob.Register(&DeclareType{})
-	gob.Register(&DeployCode{})
-	gob.Register(&ActivateClass{})
-	gob.Register(&DeactivateClass{})
-	gob.Register(&UpdateClass{})
-	gob.Register(&ActivateObject{})
-	gob.Register(&ActivateObjectDelegate{})
-	gob.Register(&DeactivateObject{})
-	gob.Register(&UpdateObject{})
-	gob.Register(&RegisterChild{})
-	gob.Register(&JetDrop{})
+	return ok
 }
@@ -0,0 +1,177 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package message
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/insolar/insolar/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func ref(b byte) core.RecordRef {
+	var r core.RecordRef
+	for i := range r {
+		r[i] = b
+	}
+	return r
+}
+
+func id(b byte) core.RecordID {
+	r := ref(b)
+	return r.GetRecordID()
+}
+
+// goldenMessages returns a message of every type with all fields filled.
+func goldenMessages() map[string]core.Message {
+	child := id(10)
+	state := ref(2)
+	pulse := core.PulseNumber(core.FirstPulseNumber)
+	return map[string]core.Message{
+		"CallMethod": &CallMethod{
+			BaseLogicMessage: BaseLogicMessage{Caller: ref(1), Nonce: 42, Depth: 1, Trace: core.CallTrace{{Object: ref(1)}}},
+			ReturnMode:       ReturnNoWait,
+			ObjectRef:        ref(2),
+			Method:           "Method",
+			Arguments:        core.Arguments{1, 2, 3},
+			Immutable:        true,
+		},
+		"CallConstructor": &CallConstructor{
+			BaseLogicMessage: BaseLogicMessage{Caller: ref(1), Nonce: 42, Depth: 1},
+			ParentRef:        ref(2),
+			SaveAs:           Delegate,
+			ClassRef:         ref(3),
+			Name:             "New",
+			Arguments:        core.Arguments{1, 2, 3},
+			PulseNum:         pulse,
+		},
+		"ExecutorResults": &ExecutorResults{
+			RecordRef:   ref(1),
+			CaseRecords: []core.CaseRecord{{Type: core.CaseRecordTypeResult, ReqSig: []byte{1}, Resp: []byte{2}}},
+			Pulse:       core.Pulse{PulseNumber: pulse, NextPulseNumber: pulse + 10, Entropy: core.Entropy{1, 2, 3}},
+		},
+		"ValidateCaseBind": &ValidateCaseBind{
+			RecordRef:   ref(1),
+			CaseRecords: []core.CaseRecord{{Type: core.CaseRecordTypeResult, ReqSig: []byte{1}, Resp: []byte{2}}},
+			Pulse:       core.Pulse{PulseNumber: pulse, NextPulseNumber: pulse + 10, Entropy: core.Entropy{1, 2, 3}},
+		},
+		"ValidationResults":  &ValidationResults{RecordRef: ref(1), PassedStepsCount: 3, Error: "failed"},
+		"ExecutorHandoff":    &ExecutorHandoff{RecordRef: ref(1), TraceID: []byte{1, 2, 3}},
+		"ExecutionCompleted": &ExecutionCompleted{RecordRef: ref(1)},
+		"ApplyJournal": &ApplyJournal{
+			RecordRef: ref(1),
+			Trace:     core.CallTrace{{Object: ref(2), Nonce: 41}, {Object: ref(3), Nonce: 42}},
+			Commit:    true,
+		},
+		"RequestCall": &RequestCall{Message: &CallMethod{ObjectRef: ref(2), Method: "Method"}},
+		"GetCode":     &GetCode{Code: ref(1)},
+		"GetClass":    &GetClass{Head: ref(1), State: &state},
+		"GetObject":   &GetObject{Head: ref(1), State: &state},
+		"GetDelegate": &GetDelegate{Head: ref(1), AsClass: ref(2)},
+		"GetChildren": &GetChildren{Parent: ref(1), FromChild: &child, FromPulse: &pulse, Amount: 10},
+		"GetEvents":   &GetEvents{Object: ref(1), FromEvent: &child, Amount: 10},
+		"EmitEvent": &EmitEvent{
+			Domain: ref(1), Request: ref(2), Object: ref(3), Name: "Event", Payload: []byte{1, 2, 3},
+		},
+		"DeclareType":  &DeclareType{Domain: ref(1), Request: ref(2), Class: ref(3), TypeDec: []byte{1, 2, 3}},
+		"GetClassType": &GetClassType{Class: ref(1)},
+		"DeployCode": &DeployCode{
+			Domain: ref(1), Request: ref(2), Code: []byte{1, 2, 3}, MachineType: core.MachineTypeGoPlugin,
+		},
+		"ActivateClass":   &ActivateClass{Domain: ref(1), Request: ref(2), Code: ref(3), Owner: "key"},
+		"DeactivateClass": &DeactivateClass{Domain: ref(1), Request: ref(2), Class: ref(3)},
+		"UpdateClass": &UpdateClass{
+			Domain: ref(1), Request: ref(2), Class: ref(3), Code: ref(4), Migrations: []core.RecordRef{ref(5)},
+		},
+		"ActivateObject": &ActivateObject{
+			Domain: ref(1), Request: ref(2), Class: ref(3), Parent: ref(4), Memory: []byte{1, 2, 3},
+		},
+		"ActivateObjectDelegate": &ActivateObjectDelegate{
+			Domain: ref(1), Request: ref(2), Class: ref(3), Parent: ref(4), Memory: []byte{1, 2, 3},
+		},
+		"DeactivateObject": &DeactivateObject{Domain: ref(1), Request: ref(2), Object: ref(3)},
+		"UpdateObject": &UpdateObject{
+			Domain: ref(1), Request: ref(2), Object: ref(3), Memory: []byte{1, 2, 3}, ClassState: &child,
+		},
+		"RegisterChild": &RegisterChild{Parent: ref(1), Child: ref(2)},
+		"JetDrop": &JetDrop{
+			Jet: ref(1), Drop: []byte{1, 2, 3}, Records: [][2][]byte{{{1}, {2}}},
+		},
+		"BootstrapRequest": &BootstrapRequest{Name: "name"},
+		"SignedMessage": &SignedMessage{
+			Msg: &GetCode{Code: ref(1)}, Sender: ref(2), Pulse: core.FirstPulseNumber, Nonce: 7, Signature: []byte{1, 2, 3},
+			serialized: MustSerializeBytes(&GetCode{Code: ref(1)}),
+		},
+	}
+}
+
+func TestSerialize_Golden(t *testing.T) {
+	types := map[core.MessageType]bool{}
+	for name, msg := range goldenMessages() {
+		types[msg.Type()] = true
+		t.Run(name, func(t *testing.T) {
+			b, err := ToBytes(msg)
+			require.NoError(t, err)
+			path := filepath.Join("testdata", name+".golden")
+			if *update {
+				err = ioutil.WriteFile(path, b, 0644)
+				require.NoError(t, err)
+			}
+
+			golden, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, golden, b, "encoding differs from golden file, run tests with -update if it is intended")
+			decoded, err := Deserialize(bytes.NewBuffer(golden))
+			require.NoError(t, err)
+			assert.Equal(t, msg, decoded)
+		})
+	}
+
+	for mt := core.TypeCallMethod; mt <= core.TypeGetClassType; mt++ {
+		assert.True(t, types[mt], "no golden file for %s", mt)
+	}
+}
+
+func TestDeserialize_Versions(t *testing.T) {
+	// Message of the next version with an added field.
+	type nextGetCode struct {
+		Code  core.RecordRef
+		Extra string
+	}
+	encode := func(version byte, body interface{}) []byte {
+		buff := &bytes.Buffer{}
+		buff.Write([]byte{version, byte(core.TypeGetCode)})
+		err := codec.NewEncoder(buff, cborH).Encode(body)
+		require.NoError(t, err)
+		return buff.Bytes()
+	}
+
+	msg, err := Deserialize(bytes.NewBuffer(encode(WireVersion+1, &nextGetCode{Code: ref(1), Extra: "extra"})))
+	require.NoError(t, err)
+	assert.Equal(t, &GetCode{Code: ref(1)}, msg)
+
+	_, err = Deserialize(bytes.NewBuffer(encode(MinWireVersion-1, &GetCode{Code: ref(1)})))
+	assert.Error(t, err)
+}
@@ -17,7 +17,10 @@
 package message
 
 import (
+	"bytes"
+
 	"github.com/insolar/insolar/core"
+	"github.com/ugorji/go/codec"
 )
 
 type ledgerMessage struct {
@@ -111,11 +114,12 @@ func (e *GetDelegate) Target() *core.RecordRef {
 	return &e.Head
 }
 
-// DeclareType creates new type.
+// DeclareType creates new type of the class.
 type DeclareType struct {
 	ledgerMessage
 	Domain  core.RecordRef
 	Request core.RecordRef
+	Class   core.RecordRef
 	TypeDec []byte
 }
 
@@ -126,7 +130,7 @@ func (e *DeclareType) Type() core.MessageType {
 
 // Target implementation of Message interface.
 func (e *DeclareType) Target() *core.RecordRef {
-	return &e.Request
+	return &e.Class
 }
 
 // DeployCode creates new code.
@@ -154,6 +158,7 @@ type ActivateClass struct {
 	Domain  core.RecordRef
 	Request core.RecordRef
 	Code    core.RecordRef
+	Owner   string
 }
 
 // Type implementation of Message interface.
@@ -265,10 +270,11 @@ func (e *DeactivateObject) Target() *core.RecordRef {
 // UpdateObject amends object.
 type UpdateObject struct {
 	ledgerMessage
-	Domain  core.RecordRef
-	Request core.RecordRef
-	Object  core.RecordRef
-	Memory  []byte
+	Domain     core.RecordRef
+	Request    core.RecordRef
+	Object     core.RecordRef
+	Memory     []byte
+	ClassState *core.RecordID // Memory is migrated to the class state, nil if it's not changed.
 }
 
 // Type implementation of Message interface.
@@ -308,6 +314,31 @@ func (*RequestCall) TargetRole() core.JetRole {
 	return core.RoleLightExecutor
 }
 
+// requestCallWire is an encoding of RequestCall, wrapped message is encoded with its type.
+type requestCallWire struct {
+	Message []byte
+}
+
+// CodecEncodeSelf implementation of codec.Selfer interface.
+func (m *RequestCall) CodecEncodeSelf(e *codec.Encoder) {
+	msg, err := ToBytes(m.Message)
+	if err != nil {
+		panic(err)
+	}
+	e.MustEncode(&requestCallWire{Message: msg})
+}
+
+// CodecDecodeSelf implementation of codec.Selfer interface.
+func (m *RequestCall) CodecDecodeSelf(d *codec.Decoder) {
+	var wire requestCallWire
+	d.MustDecode(&wire)
+	msg, err := Deserialize(bytes.NewBuffer(wire.Message))
+	if err != nil {
+		panic(err)
+	}
+	m.Message = msg
+}
+
 // Type implementation of Message interface.
 func (*RequestCall) Type() core.MessageType {
 	return core.TypeRequestCall
@@ -332,6 +363,60 @@ func (e *GetChildren) Target() *core.RecordRef {
 	return &e.Parent
 }
 
+// EmitEvent stores event published by the object.
+type EmitEvent struct {
+	ledgerMessage
+	Domain  core.RecordRef
+	Request core.RecordRef
+	Object  core.RecordRef
+	Name    string
+	Payload []byte
+}
+
+// Type implementation of Message interface.
+func (e *EmitEvent) Type() core.MessageType {
+	return core.TypeEmitEvent
+}
+
+// Target implementation of Message interface.
+func (e *EmitEvent) Target() *core.RecordRef {
+	return &e.Object
+}
+
+// GetEvents retrieves a chunk of events published by the object, latest events go first.
+type GetEvents struct {
+	ledgerMessage
+	Object    core.RecordRef
+	FromEvent *core.RecordID
+	Amount    int
+}
+
+// Type implementation of Message interface.
+func (e *GetEvents) Type() core.MessageType {
+	return core.TypeGetEvents
+}
+
+// Target implementation of Message interface.
+func (e *GetEvents) Target() *core.RecordRef {
+	return &e.Object
+}
+
+// GetClassType retrieves the latest type declared for the class.
+type GetClassType struct {
+	ledgerMessage
+	Class core.RecordRef
+}
+
+// Type implementation of Message interface.
+func (e *GetClassType) Type() core.MessageType {
+	return core.TypeGetClassType
+}
+
+// Target implementation of Message interface.
+func (e *GetClassType) Target() *core.RecordRef {
+	return &e.Class
+}
+
 // JetDrop spreads jet drop
 type JetDrop struct {
 	ledgerMessage
@@ -345,9 +430,9 @@ func (e *JetDrop) Type() core.MessageType {
 	return core.TypeJetDrop
 }
 
-// Target implementation of Message interface.
+// Target implementation of Message interface. JetDrop is delivered to all light validators.
 func (e *JetDrop) Target() *core.RecordRef {
-	return &e.Jet
+	return nil
 }
 
 // TargetRole implementation of Message interface.
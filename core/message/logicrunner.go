@@ -41,6 +41,8 @@ type IBaseLogicMessage interface {
 type BaseLogicMessage struct {
 	Caller core.RecordRef
 	Nonce  uint64
+	Depth  int            // depth of nested calls
+	Trace  core.CallTrace // trace of the caller in the call tree, empty for the root call
 	sign   []byte
 }
 
@@ -70,6 +72,7 @@ type CallMethod struct {
 	ObjectRef  core.RecordRef
 	Method     string
 	Arguments  core.Arguments
+	Immutable  bool // method can't change state of the object, calls are executed concurrently
 }
 
 func (e *CallMethod) GetReference() core.RecordRef {
@@ -124,7 +127,9 @@ func (e *CallConstructor) Target() *core.RecordRef {
 type ExecutorResults struct {
 	RecordRef   core.RecordRef
 	CaseRecords []core.CaseRecord
-	sign        []byte
+	// Pulse is the pulse validators of the case records are chosen for.
+	Pulse core.Pulse
+	sign  []byte
 }
 
 func (e *ExecutorResults) Type() core.MessageType {
@@ -203,7 +208,7 @@ func (e *ValidateCaseBind) SetSign(sign []byte) {
 type ValidationResults struct {
 	RecordRef        core.RecordRef
 	PassedStepsCount int
-	Error            error
+	Error            string
 	sign             []byte
 }
 
@@ -235,3 +240,119 @@ func (e *ValidationResults) GetSign() []byte {
 func (e *ValidationResults) SetSign(sign []byte) {
 	e.sign = sign
 }
+
+// ExecutorHandoff tells the new Executor that the object is still executed by the previous one when the role moves to
+// another node on pulse change. Object is pending on the new Executor until ExecutionCompleted is received from the
+// previous one. Execution context isn't transferred, messages queued on the previous Executor are re-sent by callers.
+type ExecutorHandoff struct {
+	RecordRef core.RecordRef
+	TraceID   []byte
+	sign      []byte
+}
+
+// Type implementation of Message interface.
+func (e *ExecutorHandoff) Type() core.MessageType {
+	return core.TypeExecutorHandoff
+}
+
+// TargetRole implementation of Message interface.
+func (e *ExecutorHandoff) TargetRole() core.JetRole {
+	return core.RoleVirtualExecutor
+}
+
+// Target implementation of Message interface.
+func (e *ExecutorHandoff) Target() *core.RecordRef {
+	return &e.RecordRef
+}
+
+// GetCaller implementation of Message interface.
+func (e *ExecutorHandoff) GetCaller() *core.RecordRef {
+	return &core.RecordRef{}
+}
+
+// GetSign returns a sign.
+func (e *ExecutorHandoff) GetSign() []byte {
+	return e.sign
+}
+
+// SetSign sets a signature to message.
+func (e *ExecutorHandoff) SetSign(sign []byte) {
+	e.sign = sign
+}
+
+// ExecutionCompleted is sent by previous Executor to the new one when execution of the object started before pulse
+// change is completed.
+type ExecutionCompleted struct {
+	RecordRef core.RecordRef
+	sign      []byte
+}
+
+// Type implementation of Message interface.
+func (e *ExecutionCompleted) Type() core.MessageType {
+	return core.TypeExecutionCompleted
+}
+
+// TargetRole implementation of Message interface.
+func (e *ExecutionCompleted) TargetRole() core.JetRole {
+	return core.RoleVirtualExecutor
+}
+
+// Target implementation of Message interface.
+func (e *ExecutionCompleted) Target() *core.RecordRef {
+	return &e.RecordRef
+}
+
+// GetCaller implementation of Message interface.
+func (e *ExecutionCompleted) GetCaller() *core.RecordRef {
+	return &core.RecordRef{}
+}
+
+// GetSign returns a sign.
+func (e *ExecutionCompleted) GetSign() []byte {
+	return e.sign
+}
+
+// SetSign sets a signature to message.
+func (e *ExecutionCompleted) SetSign(sign []byte) {
+	e.sign = sign
+}
+
+// ApplyJournal is sent to Executor of the object changed by a call tree. If Commit is set, the root call of the tree
+// from Trace is completed and changes of the tree are stored, otherwise changes made by the failed call from Trace
+// and its nested calls are reverted. Changes themselves are kept by the Executor of the object.
+type ApplyJournal struct {
+	RecordRef core.RecordRef
+	Trace     core.CallTrace
+	Commit    bool
+	sign      []byte
+}
+
+// Type implementation of Message interface.
+func (e *ApplyJournal) Type() core.MessageType {
+	return core.TypeApplyJournal
+}
+
+// TargetRole implementation of Message interface.
+func (e *ApplyJournal) TargetRole() core.JetRole {
+	return core.RoleVirtualExecutor
+}
+
+// Target implementation of Message interface.
+func (e *ApplyJournal) Target() *core.RecordRef {
+	return &e.RecordRef
+}
+
+// GetCaller implementation of Message interface.
+func (e *ApplyJournal) GetCaller() *core.RecordRef {
+	return &core.RecordRef{}
+}
+
+// GetSign returns a sign.
+func (e *ApplyJournal) GetSign() []byte {
+	return e.sign
+}
+
+// SetSign sets a signature to message.
+func (e *ApplyJournal) SetSign(sign []byte) {
+	e.sign = sign
+}
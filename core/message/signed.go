@@ -0,0 +1,191 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package message
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/insolar/insolar/core"
+	ecdsa2 "github.com/insolar/insolar/cryptohelpers/ecdsa"
+	"github.com/insolar/insolar/log"
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+)
+
+// SignedMessage wraps a message signed by the sender node. Sign of a message is not serialized, so it is passed
+// through the network along with the sender reference in this wrapper. Sign covers the sender, the pulse and the
+// nonce too, so receiver can reject messages of old pulses and messages delivered twice.
+type SignedMessage struct {
+	Msg       core.Message
+	Sender    core.RecordRef
+	Pulse     core.PulseNumber // pulse on which the message is sent
+	Nonce     uint64           // random number distinguishing messages of the sender
+	Signature []byte
+
+	// serialized is the message as it was received, decoded message is not guaranteed to serialize back to the
+	// same bytes, e.g. when it has interface fields
+	serialized []byte
+}
+
+// NewSignedMessage signs message with provided key on behalf of the sender on the pulse and wraps it for sending.
+func NewSignedMessage(
+	msg core.Message, sender core.RecordRef, pulse core.PulseNumber, key *ecdsa.PrivateKey,
+) (*SignedMessage, error) {
+	sm := &SignedMessage{Msg: msg, Sender: sender, Pulse: pulse}
+	err := binary.Read(rand.Reader, binary.BigEndian, &sm.Nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	data, err := sm.signedData()
+	if err != nil {
+		return nil, err
+	}
+	sm.Signature, err = ecdsa2.Sign(data, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign a message")
+	}
+	msg.SetSign(sm.Signature)
+	return sm, nil
+}
+
+// signedData returns serialized message followed by the sender, the pulse and the nonce.
+func (sm *SignedMessage) signedData() ([]byte, error) {
+	serialized, err := sm.serialize()
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(nil)
+	buf.Write(serialized)
+	buf.Write(sm.Sender[:])
+	err = binary.Write(buf, binary.BigEndian, sm.Pulse)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Write(buf, binary.BigEndian, sm.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// serialize returns received bytes of the message or serializes it if it wasn't received.
+func (sm *SignedMessage) serialize() ([]byte, error) {
+	if sm.serialized != nil {
+		return sm.serialized, nil
+	}
+	serialized, err := ToBytes(sm.Msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "filed to serialize message")
+	}
+	return serialized, nil
+}
+
+// Message returns wrapped message with restored sign.
+func (sm *SignedMessage) Message() core.Message {
+	sm.Msg.SetSign(sm.Signature)
+	return sm.Msg
+}
+
+// GetSender returns reference of the node which has sent the message.
+func (sm *SignedMessage) GetSender() core.RecordRef {
+	return sm.Sender
+}
+
+// IsValid checks if sign of the wrapped message, the sender, the pulse and the nonce matches provided PEM encoded
+// public key.
+func (sm *SignedMessage) IsValid(key string) bool {
+	data, err := sm.signedData()
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+	ok, err := ecdsa2.Verify(data, sm.Signature, key)
+	if err != nil {
+		log.Error(err, "failed to verify a message sign")
+		return false
+	}
+	return ok
+}
+
+// Type implementation of Message interface.
+func (*SignedMessage) Type() core.MessageType {
+	return core.TypeSignedMessage
+}
+
+// Target implementation of Message interface.
+func (sm *SignedMessage) Target() *core.RecordRef {
+	return sm.Msg.Target()
+}
+
+// TargetRole implementation of Message interface.
+func (sm *SignedMessage) TargetRole() core.JetRole {
+	return sm.Msg.TargetRole()
+}
+
+// GetCaller implementation of Message interface.
+func (sm *SignedMessage) GetCaller() *core.RecordRef {
+	return sm.Msg.GetCaller()
+}
+
+// SetSign sets a signature to message.
+func (sm *SignedMessage) SetSign(sign []byte) {
+	sm.Signature = sign
+}
+
+// GetSign returns a sign.
+func (sm *SignedMessage) GetSign() []byte {
+	return sm.Signature
+}
+
+// signedMessageWire is an encoding of SignedMessage, wrapped message is encoded with its type.
+type signedMessageWire struct {
+	Msg       []byte
+	Sender    core.RecordRef
+	Pulse     core.PulseNumber
+	Nonce     uint64
+	Signature []byte
+}
+
+// CodecEncodeSelf implementation of codec.Selfer interface.
+func (sm *SignedMessage) CodecEncodeSelf(e *codec.Encoder) {
+	msg, err := sm.serialize()
+	if err != nil {
+		panic(err)
+	}
+	e.MustEncode(&signedMessageWire{
+		Msg: msg, Sender: sm.Sender, Pulse: sm.Pulse, Nonce: sm.Nonce, Signature: sm.Signature,
+	})
+}
+
+// CodecDecodeSelf implementation of codec.Selfer interface.
+func (sm *SignedMessage) CodecDecodeSelf(d *codec.Decoder) {
+	var wire signedMessageWire
+	d.MustDecode(&wire)
+	msg, err := Deserialize(bytes.NewBuffer(wire.Msg))
+	if err != nil {
+		panic(err)
+	}
+	sm.Msg = msg
+	sm.serialized = wire.Msg
+	sm.Sender = wire.Sender
+	sm.Pulse = wire.Pulse
+	sm.Nonce = wire.Nonce
+	sm.Signature = wire.Signature
+}
@@ -16,6 +16,11 @@
 
 package core
 
+import (
+	"context"
+	"time"
+)
+
 // Arguments is a dedicated type for arguments, that represented as bynary cbored blob
 type Arguments []byte
 
@@ -52,6 +57,8 @@ type Reply interface {
 type MessageBus interface {
 	// Send an `Message` and get a `Reply` or error from remote host.
 	Send(Message) (Reply, error)
+	// SendWithOptions sends an `Message` with provided delivery options. Delivery is canceled with the context.
+	SendWithOptions(ctx context.Context, msg Message, opts *MessageSendOptions) (Reply, error)
 	// SendAsync sends an `Message` to remote host.
 	SendAsync(Message)
 	// Register saves message handler in the registry. Only one handler can be registered for a message type.
@@ -60,9 +67,29 @@ type MessageBus interface {
 	MustRegister(p MessageType, handler MessageHandler)
 }
 
+// SignedMessage is a message signed by the sender node.
+type SignedMessage interface {
+	Message
+	// Message returns wrapped message.
+	Message() Message
+	// GetSender returns reference of the node which has sent the message.
+	GetSender() RecordRef
+}
+
+// MessageSendOptions configures message delivery. Zero value means single attempt without timeout.
+type MessageSendOptions struct {
+	// Timeout limits a single delivery attempt.
+	Timeout time.Duration
+	// Retries is a count of additional attempts after failed delivery. Only idempotent messages are retried.
+	Retries int
+	// RetryDelay is a delay before the first retry, it is doubled before every next one.
+	RetryDelay time.Duration
+}
+
 // MessageHandler is a function for message handling. It should be registered via Register method.
-type MessageHandler func(Message) (Reply, error)
+type MessageHandler func(SignedMessage) (Reply, error)
 
+// Message types are encoded on the wire, so new types are appended to the end to keep values of existing ones.
 //go:generate stringer -type=MessageType
 const (
 	// Logicrunner
@@ -119,4 +146,33 @@ const (
 
 	// TypeBootstrapRequest used for bootstrap object generation.
 	TypeBootstrapRequest
+
+	// Bus
+
+	// TypeSignedMessage wraps message signed by the sender node.
+	TypeSignedMessage
+
+	// Logicrunner
+
+	// TypeExecutorHandoff passes execution context of the object to the new Executor on pulse change
+	TypeExecutorHandoff
+	// TypeExecutionCompleted notifies new Executor that execution of the object by previous Executor is completed
+	TypeExecutionCompleted
+
+	// Ledger
+
+	// TypeEmitEvent stores event published by the object.
+	TypeEmitEvent
+	// TypeGetEvents retrieves a chunk of events published by the object.
+	TypeGetEvents
+
+	// Logicrunner
+
+	// TypeApplyJournal commits or reverts changes of the object made by a call tree
+	TypeApplyJournal
+
+	// Ledger
+
+	// TypeGetClassType retrieves the latest type declared for the class.
+	TypeGetClassType
 )
@@ -4,9 +4,9 @@ package core
 
 import "strconv"
 
-const _MessageType_name = "TypeCallMethodTypeCallConstructorTypeRequestCallTypeGetCodeTypeGetClassTypeGetObjectTypeGetDelegateTypeDeclareTypeTypeDeployCodeTypeActivateClassTypeDeactivateClassTypeUpdateClassTypeActivateObjectTypeActivateObjectDelegateTypeDeactivateObjectTypeUpdateObjectTypeRegisterChild"
+const _MessageType_name = "TypeCallMethodTypeCallConstructorTypeExecutorResultsTypeValidateCaseBindTypeValidationResultsTypeRequestCallTypeGetCodeTypeGetClassTypeGetObjectTypeGetDelegateTypeGetChildrenTypeDeclareTypeTypeDeployCodeTypeActivateClassTypeDeactivateClassTypeUpdateClassTypeActivateObjectTypeActivateObjectDelegateTypeDeactivateObjectTypeUpdateObjectTypeRegisterChildTypeJetDropTypeBootstrapRequestTypeSignedMessageTypeExecutorHandoffTypeExecutionCompletedTypeEmitEventTypeGetEventsTypeApplyJournalTypeGetClassType"
 
-var _MessageType_index = [...]uint16{0, 14, 33, 48, 59, 71, 84, 99, 114, 128, 145, 164, 179, 197, 223, 243, 259, 276}
+var _MessageType_index = [...]uint16{0, 14, 33, 52, 72, 93, 108, 119, 131, 144, 159, 174, 189, 203, 220, 239, 254, 272, 298, 318, 334, 351, 362, 382, 399, 418, 440, 453, 466, 482, 498}
 
 func (i MessageType) String() string {
 	if i >= MessageType(len(_MessageType_index)-1) {
@@ -26,6 +26,14 @@ type Cascade struct {
 	ReplicationFactor uint
 }
 
+// CascadeResult is a result of remote procedure call on a node of the cascade.
+type CascadeResult struct {
+	// Result is a data returned by remote procedure.
+	Result []byte
+	// Error is a text of error returned by remote procedure or occurred while delivering message to the node.
+	Error string
+}
+
 // RemoteProcedure is remote procedure call function.
 type RemoteProcedure func(args [][]byte) ([]byte, error)
 
@@ -33,12 +41,15 @@ type RemoteProcedure func(args [][]byte) ([]byte, error)
 type Network interface {
 	// SendMessage sends a message.
 	SendMessage(nodeID RecordRef, method string, msg Message) ([]byte, error)
-	// SendCascadeMessage sends a message.
-	SendCascadeMessage(data Cascade, method string, msg Message) error
+	// SendCascadeMessage sends a message to a cascade of nodes. Every node returns results of its subtree to its parent,
+	// so results of all reached nodes are returned.
+	SendCascadeMessage(data Cascade, method string, msg Message) (map[RecordRef]CascadeResult, error)
 	// GetAddress returns an origin address.
 	GetAddress() string
 	// RemoteProcedureRegister is remote procedure register func.
 	RemoteProcedureRegister(name string, method RemoteProcedure)
 	// GetNodeID returns current node id.
 	GetNodeID() RecordRef
+	// GetActiveNode returns active node by its reference. Returns nil if node is not found.
+	GetActiveNode(ref RecordRef) *ActiveNode
 }
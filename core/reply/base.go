@@ -19,13 +19,28 @@ package reply
 
 import (
 	"bytes"
-	"encoding/gob"
 	"io"
 
 	"github.com/insolar/insolar/core"
 	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
 )
 
+const (
+	// WireVersion is the version of reply encoding written by this node.
+	WireVersion = 1
+	// MinWireVersion is the oldest version of reply encoding this node decodes. Newer versions are decoded too:
+	// adjacent versions may only add or remove fields, unknown fields are skipped and missing ones are left empty.
+	MinWireVersion = 1
+)
+
+// cborH encodes replies as CBOR maps keyed by field names.
+var cborH = &codec.CborHandle{}
+
+func init() {
+	cborH.Canonical = true
+}
+
 const (
 	// Generic
 
@@ -33,6 +48,8 @@ const (
 	TypeError = core.ReplyType(iota + 1)
 	// TypeOK is a generic reply for success calls without returned value.
 	TypeOK
+	// TypeAggregated is a reply collected from several nodes.
+	TypeAggregated
 
 	// Logicrunner
 
@@ -57,6 +74,15 @@ const (
 	TypeID
 	// TypeChildren is a reply for fetching objects children in chunks.
 	TypeChildren
+	// TypeEvents is a reply for fetching objects events in chunks.
+	TypeEvents
+	// TypeClassType is type declaration of the class from storage.
+	TypeClassType
+
+	// Generic
+
+	// TypeHandlerError is an error returned by the handler of the message.
+	TypeHandlerError
 )
 
 // ErrType is used to determine and compare reply errors.
@@ -65,6 +91,8 @@ type ErrType int
 const (
 	// ErrDeactivated returned when requested object is deactivated.
 	ErrDeactivated = iota + 1
+	// ErrUnauthorized returned when message sender is not authorized to perform the operation.
+	ErrUnauthorized
 )
 
 func getEmptyReply(t core.ReplyType) (core.Reply, error) {
@@ -87,55 +115,54 @@ func getEmptyReply(t core.ReplyType) (core.Reply, error) {
 		return &ID{}, nil
 	case TypeChildren:
 		return &Children{}, nil
+	case TypeEvents:
+		return &Events{}, nil
+	case TypeClassType:
+		return &ClassType{}, nil
 	case TypeError:
 		return &Error{}, nil
 	case TypeOK:
 		return &OK{}, nil
+	case TypeAggregated:
+		return &Aggregated{}, nil
+	case TypeHandlerError:
+		return &HandlerError{}, nil
 	default:
 		return nil, errors.Errorf("unimplemented reply type: '%d'", t)
 	}
 }
 
 // Serialize returns encoded reply.
+//
+// Encoded reply is a version byte, a reply type byte and CBOR encoded reply body.
 func Serialize(reply core.Reply) (io.Reader, error) {
 	buff := &bytes.Buffer{}
-	_, err := buff.Write([]byte{byte(reply.Type())})
+	_, err := buff.Write([]byte{WireVersion, byte(reply.Type())})
 	if err != nil {
 		return nil, err
 	}
 
-	enc := gob.NewEncoder(buff)
+	enc := codec.NewEncoder(buff, cborH)
 	err = enc.Encode(reply)
 	return buff, err
 }
 
 // Deserialize returns decoded reply.
 func Deserialize(buff io.Reader) (core.Reply, error) {
-	b := make([]byte, 1)
-	_, err := buff.Read(b)
+	b := make([]byte, 2)
+	_, err := io.ReadFull(buff, b)
 	if err != nil {
 		return nil, errors.New("too short input to deserialize a message reply")
 	}
+	if b[0] < MinWireVersion {
+		return nil, errors.Errorf("unsupported reply encoding version %d", b[0])
+	}
 
-	reply, err := getEmptyReply(core.ReplyType(b[0]))
+	reply, err := getEmptyReply(core.ReplyType(b[1]))
 	if err != nil {
 		return nil, err
 	}
-	enc := gob.NewDecoder(buff)
-	err = enc.Decode(reply)
+	dec := codec.NewDecoder(buff, cborH)
+	err = dec.Decode(reply)
 	return reply, err
 }
-
-func init() {
-	gob.Register(&CallMethod{})
-	gob.Register(&CallConstructor{})
-	gob.Register(&Code{})
-	gob.Register(&Class{})
-	gob.Register(&Object{})
-	gob.Register(&Delegate{})
-	gob.Register(&Reference{})
-	gob.Register(&ID{})
-	gob.Register(&Children{})
-	gob.Register(&Error{})
-	gob.Register(&OK{})
-}
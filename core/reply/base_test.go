@@ -0,0 +1,135 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package reply
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func ref(b byte) core.RecordRef {
+	var r core.RecordRef
+	for i := range r {
+		r[i] = b
+	}
+	return r
+}
+
+func id(b byte) core.RecordID {
+	r := ref(b)
+	return r.GetRecordID()
+}
+
+// goldenReplies returns a reply of every type with all fields filled.
+func goldenReplies() map[string]core.Reply {
+	object := ref(1)
+	next := id(2)
+	return map[string]core.Reply{
+		"Error":        &Error{ErrType: ErrDeactivated},
+		"HandlerError": &HandlerError{Text: "failed"},
+		"OK":           &OK{},
+		"Aggregated": &Aggregated{
+			Replies: map[core.RecordRef]core.Reply{ref(1): &OK{}, ref(2): &ID{ID: id(3)}},
+			Errors:  map[core.RecordRef]string{ref(4): "failed"},
+		},
+		"CallMethod": &CallMethod{
+			Data: []byte{1, 2, 3}, Result: []byte{4, 5, 6},
+			Usage:   core.CallUsage{Time: time.Second, Memory: 1024, Calls: 2},
+			Pending: []core.RecordRef{ref(1), ref(2)},
+		},
+		"CallConstructor": &CallConstructor{
+			Object: &object, Pending: []core.RecordRef{ref(1)},
+		},
+		"Code": &Code{Code: []byte{1, 2, 3}, MachineType: core.MachineTypeGoPlugin},
+		"Class": &Class{
+			Head: ref(1), State: id(2), Code: &object, MachineType: core.MachineTypeGoPlugin,
+			PrevState: &next, Migrations: []core.RecordRef{ref(3)}, Owner: "key",
+		},
+		"Object":    &Object{Head: ref(1), State: id(2), Class: ref(3), Memory: []byte{1, 2, 3}, ClassState: &next},
+		"Delegate":  &Delegate{Head: ref(1)},
+		"Reference": &Reference{Ref: ref(1)},
+		"ID":        &ID{ID: id(1)},
+		"Children":  &Children{Refs: []core.RecordRef{ref(1), ref(2)}, NextFrom: &next},
+		"Events": &Events{
+			Events:   []core.Event{{ID: id(1), Name: "Event", Payload: []byte{1, 2, 3}}},
+			NextFrom: &next,
+		},
+		"ClassType": &ClassType{Ref: ref(1), TypeDeclaration: []byte{1, 2, 3}},
+	}
+}
+
+func TestSerialize_Golden(t *testing.T) {
+	types := map[core.ReplyType]bool{}
+	for name, rep := range goldenReplies() {
+		types[rep.Type()] = true
+		t.Run(name, func(t *testing.T) {
+			rd, err := Serialize(rep)
+			require.NoError(t, err)
+			b, err := ioutil.ReadAll(rd)
+			require.NoError(t, err)
+			path := filepath.Join("testdata", name+".golden")
+			if *update {
+				err = ioutil.WriteFile(path, b, 0644)
+				require.NoError(t, err)
+			}
+
+			golden, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, golden, b, "encoding differs from golden file, run tests with -update if it is intended")
+			decoded, err := Deserialize(bytes.NewBuffer(golden))
+			require.NoError(t, err)
+			assert.Equal(t, rep, decoded)
+		})
+	}
+
+	for rt := TypeError; rt <= TypeHandlerError; rt++ {
+		assert.True(t, types[rt], "no golden file for reply type %d", rt)
+	}
+}
+
+func TestDeserialize_Versions(t *testing.T) {
+	// Reply of the next version with an added field.
+	type nextID struct {
+		ID    core.RecordID
+		Extra string
+	}
+	encode := func(version byte, body interface{}) []byte {
+		buff := &bytes.Buffer{}
+		buff.Write([]byte{version, byte(TypeID)})
+		err := codec.NewEncoder(buff, cborH).Encode(body)
+		require.NoError(t, err)
+		return buff.Bytes()
+	}
+
+	rep, err := Deserialize(bytes.NewBuffer(encode(WireVersion+1, &nextID{ID: id(1), Extra: "extra"})))
+	require.NoError(t, err)
+	assert.Equal(t, &ID{ID: id(1)}, rep)
+
+	_, err = Deserialize(bytes.NewBuffer(encode(MinWireVersion-1, &ID{ID: id(1)})))
+	assert.Error(t, err)
+}
@@ -16,7 +16,17 @@
 
 package reply
 
-import "github.com/insolar/insolar/core"
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/insolar/insolar/core"
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+)
 
 // OK is a generic reply for success calls without returned value.
 type OK struct {
@@ -42,6 +52,104 @@ func (e *Error) Error() error {
 	switch e.ErrType {
 	case ErrDeactivated:
 		return core.ErrDeactivated
+	case ErrUnauthorized:
+		return core.ErrUnauthorized
 	}
 	return core.ErrUnknown
 }
+
+// HandlerError is a reply with error returned by the handler of the message. Message is delivered, so it isn't
+// sent again.
+type HandlerError struct {
+	Text string
+}
+
+// Type implementation of Reply interface.
+func (e *HandlerError) Type() core.ReplyType {
+	return TypeHandlerError
+}
+
+// Error implementation of error interface.
+func (e *HandlerError) Error() string {
+	return e.Text
+}
+
+// Aggregated is a reply collected from several nodes. It is returned when message is delivered to all actors of
+// the role.
+type Aggregated struct {
+	Replies map[core.RecordRef]core.Reply
+	Errors  map[core.RecordRef]string
+}
+
+// Type implementation of Reply interface.
+func (r *Aggregated) Type() core.ReplyType {
+	return TypeAggregated
+}
+
+// Nodes returns count of nodes the message was sent to.
+func (r *Aggregated) Nodes() int {
+	return len(r.Replies) + len(r.Errors)
+}
+
+// Partial returns true if message was delivered to some nodes but not to all of them.
+func (r *Aggregated) Partial() bool {
+	return len(r.Replies) > 0 && len(r.Errors) > 0
+}
+
+// Err returns error describing failed nodes or nil if all nodes replied successfully.
+func (r *Aggregated) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	failed := make([]string, 0, len(r.Errors))
+	for node, e := range r.Errors {
+		failed = append(failed, fmt.Sprintf("%s: %s", node, e))
+	}
+	sort.Strings(failed)
+	return errors.Errorf(
+		"failed to deliver message to %d of %d nodes: %s", len(r.Errors), r.Nodes(), strings.Join(failed, "; "),
+	)
+}
+
+// aggregatedWire is an encoding of Aggregated, replies are encoded with their types.
+type aggregatedWire struct {
+	Replies map[core.RecordRef][]byte
+	Errors  map[core.RecordRef]string
+}
+
+// CodecEncodeSelf implementation of codec.Selfer interface.
+func (r *Aggregated) CodecEncodeSelf(e *codec.Encoder) {
+	wire := aggregatedWire{Errors: r.Errors}
+	if r.Replies != nil {
+		wire.Replies = make(map[core.RecordRef][]byte, len(r.Replies))
+	}
+	for node, rep := range r.Replies {
+		rd, err := Serialize(rep)
+		if err != nil {
+			panic(err)
+		}
+		wire.Replies[node], err = ioutil.ReadAll(rd)
+		if err != nil {
+			panic(err)
+		}
+	}
+	e.MustEncode(&wire)
+}
+
+// CodecDecodeSelf implementation of codec.Selfer interface.
+func (r *Aggregated) CodecDecodeSelf(d *codec.Decoder) {
+	var wire aggregatedWire
+	d.MustDecode(&wire)
+	r.Errors = wire.Errors
+	r.Replies = nil
+	if wire.Replies != nil {
+		r.Replies = make(map[core.RecordRef]core.Reply, len(wire.Replies))
+	}
+	for node, b := range wire.Replies {
+		rep, err := Deserialize(bytes.NewBuffer(b))
+		if err != nil {
+			panic(err)
+		}
+		r.Replies[node] = rep
+	}
+}
@@ -37,6 +37,9 @@ type Class struct {
 	State       core.RecordID
 	Code        *core.RecordRef // Can be nil.
 	MachineType core.MachineType
+	PrevState   *core.RecordID   // Nil for the first state.
+	Migrations  []core.RecordRef // Migration code from the previous state.
+	Owner       string           // Public key of the class deployer.
 }
 
 // Type implementation of Reply interface.
@@ -46,10 +49,11 @@ func (e *Class) Type() core.ReplyType {
 
 // Object is object from storage.
 type Object struct {
-	Head   core.RecordRef
-	State  core.RecordID
-	Class  core.RecordRef
-	Memory []byte
+	Head       core.RecordRef
+	State      core.RecordID
+	Class      core.RecordRef
+	Memory     []byte
+	ClassState *core.RecordID // Class state memory conforms to, can be nil.
 }
 
 // Type implementation of Reply interface.
@@ -97,3 +101,25 @@ type Children struct {
 func (e *Children) Type() core.ReplyType {
 	return TypeChildren
 }
+
+// Events is a reply for fetching objects events in chunks.
+type Events struct {
+	Events   []core.Event
+	NextFrom *core.RecordID
+}
+
+// Type implementation of Reply interface.
+func (e *Events) Type() core.ReplyType {
+	return TypeEvents
+}
+
+// ClassType is type declaration of the class from storage.
+type ClassType struct {
+	Ref             core.RecordRef // Type record.
+	TypeDeclaration []byte
+}
+
+// Type implementation of Reply interface.
+func (e *ClassType) Type() core.ReplyType {
+	return TypeClassType
+}
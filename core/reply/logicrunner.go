@@ -22,8 +22,10 @@ import (
 
 // CallMethod - the most common reply
 type CallMethod struct {
-	Data   []byte
-	Result []byte
+	Data    []byte
+	Result  []byte
+	Usage   core.CallUsage
+	Pending []core.RecordRef // objects changed by the call, they are held until the call tree is completed
 }
 
 // Type returns type of the reply
@@ -32,7 +34,8 @@ func (r *CallMethod) Type() core.ReplyType {
 }
 
 type CallConstructor struct {
-	Object *core.RecordRef
+	Object  *core.RecordRef
+	Pending []core.RecordRef // objects changed by the call, they are held until the call tree is completed
 }
 
 // Type returns type of the reply
@@ -37,6 +37,9 @@ func cborUnMarshal(data []byte, to interface{}) error {
 // MarshalArgs marshals arguments by cbor
 func MarshalArgs(args ...interface{}) (Arguments, error) {
 	var argsSerialized []byte
+	if args == nil {
+		args = []interface{}{} // no arguments is an empty array, not nil
+	}
 
 	argsSerialized, err := cborMarshal(args)
 	if err != nil {
@@ -32,7 +32,8 @@ const (
 	MachineTypesLastID
 )
 
-// MachineLogicExecutor is an interface for implementers of one particular machine type
+// MachineLogicExecutor is an interface for implementers of one particular machine type,
+// executors report resources used by a method call in ctx.Usage and set ctx.Aborted if the method failed
 type MachineLogicExecutor interface {
 	CallMethod(ctx *LogicCallContext, code RecordRef, data []byte, method string, args Arguments) (newObjectState []byte, methodResults Arguments, err error)
 	CallConstructor(ctx *LogicCallContext, code RecordRef, name string, args Arguments) (objectState []byte, err error)
@@ -43,20 +44,95 @@ type MachineLogicExecutor interface {
 type LogicRunner interface {
 	Execute(Message) (res Reply, err error)
 	ValidateCaseBind(Message) (res Reply, err error)
-	ProcessValidationResults(Message) (res Reply, err error)
-	ExecutorResults(Message) (res Reply, err error)
+	ProcessValidationResults(SignedMessage) (res Reply, err error)
+	ExecutorResults(SignedMessage) (res Reply, err error)
 	Validate(ref RecordRef, p Pulse, cr []CaseRecord) (int, error) // TODO hide?
 	OnPulse(Pulse) error
 }
 
 // LogicCallContext is a context of contract execution
 type LogicCallContext struct {
-	Callee *RecordRef // Contract that was called
-	Class  *RecordRef // Class of the callee
-	Parent *RecordRef // Parent of the callee
-	Caller *RecordRef // Contract that made the call
-	Time   time.Time  // Time when call was made
-	Pulse  Pulse      // Number of the pulse
+	Callee    *RecordRef // Contract that was called
+	Class     *RecordRef // Class of the callee
+	Parent    *RecordRef // Parent of the callee
+	Caller    *RecordRef // Contract that made the call
+	Time      time.Time  // Time when call was made
+	Pulse     Pulse      // Number of the pulse
+	Depth     int        // Depth of nested calls
+	Limits    CallLimits // Resources available to the call
+	Usage     CallUsage  // Resources used by the call, filled by executor
+	Aborted   bool       // Method returned error, changes of the call are discarded, filled by executor
+	Immutable bool       // Method can't change state of the object
+	Trace     CallTrace  // Calls from the root of the call tree to this call
+}
+
+// TraceStep is a call of one object in a call tree
+type TraceStep struct {
+	Object RecordRef // Called object
+	Nonce  uint64    // Nonce of the call message, distinguishes calls of the same object
+}
+
+// CallTrace is a position of a call in the call tree, it starts with the root call of the tree
+type CallTrace []TraceStep
+
+// Extend returns trace of the nested call
+func (t CallTrace) Extend(step TraceStep) CallTrace {
+	res := make(CallTrace, len(t), len(t)+1)
+	copy(res, t)
+	return append(res, step)
+}
+
+// HasPrefix checks if the call is made by the call with `prefix` trace or is this call itself
+func (t CallTrace) HasPrefix(prefix CallTrace) bool {
+	if len(prefix) == 0 || len(t) < len(prefix) {
+		return false
+	}
+	for i, step := range prefix {
+		if t[i] != step {
+			return false
+		}
+	}
+	return true
+}
+
+// CallLimits is a set of resources available to one contract call, zero disables a limit
+type CallLimits struct {
+	Time   time.Duration // Execution time
+	Memory uint64        // Bytes allocated
+	Depth  int           // Depth of nested calls
+}
+
+// CallUsage is a set of resources used by one contract call
+type CallUsage struct {
+	Time   time.Duration // Execution time of the contract code, nested calls are not included
+	Memory uint64        // Bytes allocated while contract code was executed
+	Calls  int           // Count of nested calls
+}
+
+// JournalEntryType is a type of ledger change made by a call
+type JournalEntryType int
+
+// Types of journal entries
+const (
+	// JournalEntryUpdate - object's memory was updated, reverted by restoring previous memory, call tree holds
+	// the object until it's completed so no other changes can be made in between
+	JournalEntryUpdate JournalEntryType = iota
+	// JournalEntryActivate - object was created, reverted by deactivation
+	JournalEntryActivate
+	// JournalEntryDeactivate - object was destroyed, deactivation is stored when the call tree is committed
+	JournalEntryDeactivate
+	// JournalEntryEmit - object published event, event is stored when the call tree is committed
+	JournalEntryEmit
+)
+
+// JournalEntry is a ledger change made by a call, changes of all calls of one call tree are committed or
+// reverted together. Entries are kept by the executor of the changed object and never leave it
+type JournalEntry struct {
+	Type    JournalEntryType
+	Object  RecordRef
+	Memory  []byte // previous memory of updated object
+	Name    string // name of emitted event
+	Payload []byte // payload of emitted event
 }
 
 // CaseRecordType is a type of caserecord
@@ -74,6 +150,7 @@ const (
 	CaseRecordTypeSaveAsDelegate
 	CaseRecordTypeGetDelegate
 	CaseRecordTypeDeactivateObject
+	CaseRecordTypeEmit
 )
 
 // CaseRecord is one record of validateable object calling history
@@ -120,6 +120,9 @@ func (m *LedgerArtifactManager) GetClass(head core.RecordRef, state *core.Record
 		state:       react.State,
 		code:        react.Code,
 		machineType: react.MachineType,
+		prevState:   react.PrevState,
+		migrations:  react.Migrations,
+		owner:       react.Owner,
 	}
 	return &desc, nil
 }
@@ -141,11 +144,12 @@ func (m *LedgerArtifactManager) GetObject(head core.RecordRef, state *core.Recor
 	switch r := genericReact.(type) {
 	case *reply.Object:
 		desc := ObjectDescriptor{
-			am:     m,
-			head:   r.Head,
-			state:  r.State,
-			class:  r.Class,
-			memory: r.Memory,
+			am:         m,
+			head:       r.Head,
+			state:      r.State,
+			class:      r.Class,
+			memory:     r.Memory,
+			classState: r.ClassState,
 		}
 		return &desc, nil
 	case *reply.Error:
@@ -183,15 +187,17 @@ func (m *LedgerArtifactManager) GetChildren(parent core.RecordRef, pulse *core.P
 	return NewChildIterator(m.messageBus, parent, pulse, m.getChildrenChunkSize)
 }
 
-// DeclareType creates new type record in storage.
+// DeclareType creates new type record in storage. Provided reference should be a reference to the head of the
+// class the type describes. The type becomes the latest type of the class.
 //
-// Type is a contract interface. It contains one method signature.
+// Type is a contract interface. It contains signatures of the class methods and constructors.
 func (m *LedgerArtifactManager) DeclareType(
-	domain, request core.RecordRef, typeDec []byte,
+	domain, request, class core.RecordRef, typeDec []byte,
 ) (*core.RecordRef, error) {
 	return m.fetchReference(&message.DeclareType{
 		Domain:  domain,
 		Request: request,
+		Class:   class,
 		TypeDec: typeDec,
 	})
 }
@@ -214,12 +220,13 @@ func (m *LedgerArtifactManager) DeployCode(
 //
 // Request reference will be this class'es identifier and referred as "class head".
 func (m *LedgerArtifactManager) ActivateClass(
-	domain, request, code core.RecordRef,
+	domain, request, code core.RecordRef, owner string,
 ) (*core.RecordID, error) {
 	return m.fetchID(&message.ActivateClass{
 		Domain:  domain,
 		Request: request,
 		Code:    code,
+		Owner:   owner,
 	})
 }
 
@@ -327,6 +334,36 @@ func (m *LedgerArtifactManager) UpdateObject(
 	})
 }
 
+// MigrateObject creates amend object record in storage like UpdateObject does. Provided memory is the object memory
+// migrated to provided class state.
+//
+// Returned reference will be the latest object state (exact) reference.
+func (m *LedgerArtifactManager) MigrateObject(
+	domain, request, object core.RecordRef, classState core.RecordID, memory []byte,
+) (*core.RecordID, error) {
+	return m.fetchID(&message.UpdateObject{
+		Domain:     domain,
+		Request:    request,
+		Object:     object,
+		Memory:     memory,
+		ClassState: &classState,
+	})
+}
+
+// EmitEvent creates event record in storage. Provided reference should be a reference to the head of the object
+// which published the event. Payload is CBOR encoded.
+func (m *LedgerArtifactManager) EmitEvent(
+	domain, request, object core.RecordRef, name string, payload []byte,
+) (*core.RecordID, error) {
+	return m.fetchID(&message.EmitEvent{
+		Domain:  domain,
+		Request: request,
+		Object:  object,
+		Name:    name,
+		Payload: payload,
+	})
+}
+
 func (m *LedgerArtifactManager) fetchReference(ev core.Message) (*core.RecordRef, error) {
 	genericReact, err := m.messageBus.Send(ev)
 
@@ -334,11 +371,13 @@ func (m *LedgerArtifactManager) fetchReference(ev core.Message) (*core.RecordRef
 		return nil, err
 	}
 
-	react, ok := genericReact.(*reply.Reference)
-	if !ok {
-		return nil, ErrUnexpectedReply
+	switch r := genericReact.(type) {
+	case *reply.Reference:
+		return &r.Ref, nil
+	case *reply.Error:
+		return nil, r.Error()
 	}
-	return &react.Ref, nil
+	return nil, ErrUnexpectedReply
 }
 
 func (m *LedgerArtifactManager) fetchID(msg core.Message) (*core.RecordID, error) {
@@ -348,9 +387,11 @@ func (m *LedgerArtifactManager) fetchID(msg core.Message) (*core.RecordID, error
 		return nil, err
 	}
 
-	react, ok := genericReact.(*reply.ID)
-	if !ok {
-		return nil, ErrUnexpectedReply
+	switch r := genericReact.(type) {
+	case *reply.ID:
+		return &r.ID, nil
+	case *reply.Error:
+		return nil, r.Error()
 	}
-	return &react.ID, nil
+	return nil, ErrUnexpectedReply
 }
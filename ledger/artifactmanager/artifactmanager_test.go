@@ -17,6 +17,7 @@
 package artifactmanager
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -24,10 +25,13 @@ import (
 	"github.com/insolar/insolar/core/reply"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/record"
 	"github.com/insolar/insolar/ledger/storage"
 
@@ -69,12 +73,15 @@ type preparedAMTestData struct {
 	requestRef *record.Reference
 }
 
+// messageBusMock delivers messages to local handlers on behalf of the current virtual executor.
 type messageBusMock struct {
 	handlers map[core.MessageType]core.MessageHandler
+	db       *storage.DB
+	jc       core.JetCoordinator
 }
 
-func NewMessageBusMock() *messageBusMock {
-	return &messageBusMock{handlers: map[core.MessageType]core.MessageHandler{}}
+func NewMessageBusMock(db *storage.DB, jc core.JetCoordinator) *messageBusMock {
+	return &messageBusMock{handlers: map[core.MessageType]core.MessageHandler{}, db: db, jc: jc}
 }
 
 func (mb *messageBusMock) Register(p core.MessageType, handler core.MessageHandler) error {
@@ -108,7 +115,21 @@ func (mb *messageBusMock) Send(m core.Message) (core.Reply, error) {
 	if !ok {
 		return nil, errors.New(fmt.Sprintf("no handler for this message type %s", typ))
 	}
-	return handler(m)
+	pulse, err := mb.db.GetLatestPulseNumber()
+	if err != nil {
+		return nil, err
+	}
+	executors, err := mb.jc.QueryRoleNodes(core.RoleVirtualExecutor, pulse)
+	if err != nil {
+		return nil, err
+	}
+	return handler(&message.SignedMessage{Msg: m, Sender: executors[0]})
+}
+
+func (mb *messageBusMock) SendWithOptions(
+	ctx context.Context, m core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return mb.Send(m)
 }
 
 func (mb *messageBusMock) SendAsync(m core.Message) {
@@ -118,9 +139,11 @@ func (mb *messageBusMock) SendAsync(m core.Message) {
 func prepareAMTestData(t *testing.T) (preparedAMTestData, func()) {
 	db, cleaner := storagetest.TmpDB(t, "")
 
-	mb := NewMessageBusMock()
+	jc, err := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	require.NoError(t, err)
+	mb := NewMessageBusMock(db, jc)
 	components := core.Components{MessageBus: mb}
-	handler := MessageHandler{db: db}
+	handler := MessageHandler{db: db, jc: jc}
 	handler.Link(components)
 
 	return preparedAMTestData{
@@ -195,8 +218,14 @@ func TestLedgerArtifactManager_DeclareType(t *testing.T) {
 	td, cleaner := prepareAMTestData(t)
 	defer cleaner()
 
+	classID, _ := td.db.SetRecord(&record.ClassActivateRecord{})
+	td.db.SetClassIndex(classID, &index.ClassLifeline{
+		LatestState: *classID,
+	})
+	classRef := genRefWithID(classID)
+
 	typeDec := []byte{1, 2, 3}
-	coreRef, err := td.manager.DeclareType(*domainRef.CoreRef(), *td.requestRef.CoreRef(), typeDec)
+	coreRef, err := td.manager.DeclareType(*domainRef.CoreRef(), *td.requestRef.CoreRef(), *classRef, typeDec)
 	assert.NoError(t, err)
 	ref := record.Core2Reference(*coreRef)
 	typeRec, err := td.db.GetRecord(&ref.Record)
@@ -210,8 +239,20 @@ func TestLedgerArtifactManager_DeclareType(t *testing.T) {
 				},
 			},
 		},
+		Class:           record.Core2Reference(*classRef),
 		TypeDeclaration: typeDec,
 	}, typeRec)
+	idx, err := td.db.GetClassIndex(classID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &ref.Record, idx.LatestType)
+
+	mb := td.manager.messageBus.(*messageBusMock)
+	rep, err := mb.Send(&message.GetClassType{Class: *classRef})
+	require.NoError(t, err)
+	assert.Equal(t, &reply.ClassType{Ref: *coreRef, TypeDeclaration: typeDec}, rep)
+
+	_, err = td.manager.DeclareType(*domainRef.CoreRef(), *td.requestRef.CoreRef(), *genRandomRef(0).CoreRef(), typeDec)
+	assert.Error(t, err)
 }
 
 func TestLedgerArtifactManager_DeployCode_CreatesCorrectRecord(t *testing.T) {
@@ -249,7 +290,7 @@ func TestLedgerArtifactManager_ActivateClass_CreatesCorrectRecord(t *testing.T)
 	codeRef := record.Reference{Record: *codeID, Domain: domainID}
 	classRef := genRandomRef(0)
 	activateCoreID, err := td.manager.ActivateClass(
-		*domainRef.CoreRef(), *classRef.CoreRef(), *codeRef.CoreRef(),
+		*domainRef.CoreRef(), *classRef.CoreRef(), *codeRef.CoreRef(), "owner",
 	)
 	assert.Nil(t, err)
 	activateID := record.Bytes2ID(activateCoreID[:])
@@ -264,11 +305,16 @@ func TestLedgerArtifactManager_ActivateClass_CreatesCorrectRecord(t *testing.T)
 				},
 			},
 		},
-		Code: codeRef,
+		Code:  codeRef,
+		Owner: "owner",
 	})
 	idx, err := td.db.GetClassIndex(&classRef.Record, false)
 	assert.NoError(t, err)
 	assert.Equal(t, activateID, idx.LatestState)
+
+	classDesc, err := td.manager.GetClass(*classRef.CoreRef(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "owner", classDesc.Owner())
 }
 
 func TestLedgerArtifactManager_DeactivateClass_VerifiesRecord(t *testing.T) {
@@ -498,10 +544,11 @@ func TestLedgerArtifactManager_ActivateObject_CreatesCorrectRecord(t *testing.T)
 				},
 			},
 		},
-		Class:    record.Reference{Domain: td.requestRef.Domain, Record: *classID},
-		Memory:   memory,
-		Parent:   record.Reference{Domain: td.requestRef.Domain, Record: *parentID},
-		Delegate: false,
+		Class:      record.Reference{Domain: td.requestRef.Domain, Record: *classID},
+		ClassState: classID,
+		Memory:     memory,
+		Parent:     record.Reference{Domain: td.requestRef.Domain, Record: *parentID},
+		Delegate:   false,
 	})
 
 	idx, err := td.db.GetObjectIndex(parentID, false)
@@ -592,10 +639,11 @@ func TestLedgerArtifactManager_ActivateObjectDelegate_CreatesCorrectRecord(t *te
 				},
 			},
 		},
-		Class:    *classRef,
-		Memory:   memory,
-		Parent:   *parentRef,
-		Delegate: true,
+		Class:      *classRef,
+		ClassState: classID,
+		Memory:     memory,
+		Parent:     *parentRef,
+		Delegate:   true,
 	})
 
 	delegate, err := td.manager.GetDelegate(*parentRef.CoreRef(), *classRef.CoreRef())
@@ -736,12 +784,50 @@ func TestLedgerArtifactManager_UpdateObject_CreatesCorrectRecord(t *testing.T) {
 	})
 }
 
+func TestLedgerArtifactManager_MigrateObject_SetsClassState(t *testing.T) {
+	t.Parallel()
+	td, cleaner := prepareAMTestData(t)
+	defer cleaner()
+
+	classState := genRandomID(0)
+	objID, _ := td.db.SetRecord(&record.ObjectActivateRecord{
+		ActivationRecord: record.ActivationRecord{
+			StatefulResult: record.StatefulResult{
+				ResultRecord: record.ResultRecord{
+					DomainRecord: *genRandomRef(0),
+				},
+			},
+		},
+		ClassState: genRandomID(0),
+	})
+	td.db.SetObjectIndex(objID, &index.ObjectLifeline{
+		LatestState: *objID,
+	})
+
+	updateCoreID, err := td.manager.MigrateObject(
+		*domainRef.CoreRef(), *td.requestRef.CoreRef(), *genRefWithID(objID), *classState.CoreID(), []byte{1})
+	assert.NoError(t, err)
+	updateID := record.Bytes2ID(updateCoreID[:])
+	updateRec, err := td.db.GetRecord(&updateID)
+	assert.NoError(t, err)
+	assert.Equal(t, classState, updateRec.(*record.ObjectAmendRecord).ClassState)
+
+	updateCoreID, err = td.manager.UpdateObject(
+		*domainRef.CoreRef(), *td.requestRef.CoreRef(), *genRefWithID(objID), []byte{2})
+	assert.NoError(t, err)
+	updateID = record.Bytes2ID(updateCoreID[:])
+	updateRec, err = td.db.GetRecord(&updateID)
+	assert.NoError(t, err)
+	assert.Equal(t, classState, updateRec.(*record.ObjectAmendRecord).ClassState, "update should keep class state")
+}
+
 func TestLedgerArtifactManager_GetClass_ReturnsCorrectDescriptors(t *testing.T) {
 	t.Parallel()
 	td, cleaner := prepareAMTestData(t)
 	defer cleaner()
 
 	codeRef := *genRandomRef(0)
+	migrationRef := *genRandomRef(0)
 	classID, _ := td.db.SetRecord(&record.ClassActivateRecord{
 		ActivationRecord: record.ActivationRecord{
 			StatefulResult: record.StatefulResult{
@@ -759,8 +845,10 @@ func TestLedgerArtifactManager_GetClass_ReturnsCorrectDescriptors(t *testing.T)
 					RequestRecord: *td.requestRef,
 				},
 			},
+			AmendedRecord: *classID,
 		},
-		NewCode: codeRef,
+		NewCode:    codeRef,
+		Migrations: []record.Reference{migrationRef},
 	})
 	classIndex := index.ClassLifeline{
 		LatestState: *classAmendID,
@@ -771,13 +859,22 @@ func TestLedgerArtifactManager_GetClass_ReturnsCorrectDescriptors(t *testing.T)
 	classDesc, err := td.manager.GetClass(*classRef, nil)
 	assert.NoError(t, err)
 	expectedClassDesc := &ClassDescriptor{
-		am:    td.manager,
-		head:  *classRef,
-		state: *classAmendID.CoreID(),
-		code:  codeRef.CoreRef(),
+		am:         td.manager,
+		head:       *classRef,
+		state:      *classAmendID.CoreID(),
+		code:       codeRef.CoreRef(),
+		prevState:  classID.CoreID(),
+		migrations: []core.RecordRef{*migrationRef.CoreRef()},
 	}
 
 	assert.Equal(t, *expectedClassDesc, *classDesc.(*ClassDescriptor))
+
+	prevState := core.ComposeRecordRef(classRef.Domain(), *classID.CoreID())
+	classDesc, err = td.manager.GetClass(*classRef, &prevState)
+	assert.NoError(t, err)
+	assert.Equal(t, classID.CoreID(), classDesc.StateID())
+	assert.Nil(t, classDesc.PrevStateID())
+	assert.Empty(t, classDesc.Migrations())
 }
 
 func TestLedgerArtifactManager_GetObject_VerifiesRecords(t *testing.T) {
@@ -941,6 +1038,22 @@ func TestLedgerArtifactManager_GetChildren(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("skips deactivated children", func(t *testing.T) {
+		deactivateID, _ := td.db.SetRecord(&record.DeactivationRecord{})
+		td.db.SetObjectIndex(&child2Ref.Record, &index.ObjectLifeline{LatestState: *deactivateID})
+		defer td.db.SetObjectIndex(&child2Ref.Record, &index.ObjectLifeline{LatestState: child2Ref.Record})
+
+		i, err := td.manager.GetChildren(*genRefWithID(parentID), nil)
+		assert.NoError(t, err)
+		child, err := i.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, *child3Ref.CoreRef(), *child)
+		child, err = i.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, *child1Ref.CoreRef(), *child)
+		assert.False(t, i.HasNext())
+	})
+
 	t.Run("returns correct children in many chunks", func(t *testing.T) {
 		td.manager.getChildrenChunkSize = 1
 		i, err := td.manager.GetChildren(*genRefWithID(parentID), nil)
@@ -983,6 +1096,51 @@ func TestLedgerArtifactManager_GetChildren(t *testing.T) {
 	})
 }
 
+func TestLedgerArtifactManager_EmitEvent(t *testing.T) {
+	t.Parallel()
+	td, cleaner := prepareAMTestData(t)
+	defer cleaner()
+
+	objID, _ := td.db.SetRecord(&record.ObjectActivateRecord{
+		ActivationRecord: record.ActivationRecord{
+			StatefulResult: record.StatefulResult{
+				ResultRecord: record.ResultRecord{
+					DomainRecord: domainRef,
+				},
+			},
+		},
+	})
+	td.db.SetObjectIndex(objID, &index.ObjectLifeline{
+		LatestState: *objID,
+	})
+	objRef := *genRefWithID(objID)
+
+	first, err := td.manager.EmitEvent(*domainRef.CoreRef(), *td.requestRef.CoreRef(), objRef, "first", []byte{1})
+	require.NoError(t, err)
+	_, err = td.manager.EmitEvent(*domainRef.CoreRef(), *td.requestRef.CoreRef(), objRef, "second", []byte{2})
+	require.NoError(t, err)
+
+	rep, err := td.manager.messageBus.Send(&message.GetEvents{Object: objRef, Amount: 1})
+	require.NoError(t, err)
+	events := rep.(*reply.Events)
+	require.Len(t, events.Events, 1)
+	assert.Equal(t, "second", events.Events[0].Name)
+	assert.Equal(t, []byte{2}, events.Events[0].Payload)
+	assert.Equal(t, first, events.NextFrom)
+
+	rep, err = td.manager.messageBus.Send(&message.GetEvents{Object: objRef, FromEvent: events.NextFrom, Amount: 10})
+	require.NoError(t, err)
+	events = rep.(*reply.Events)
+	require.Len(t, events.Events, 1)
+	assert.Equal(t, core.Event{ID: *first, Name: "first", Payload: []byte{1}}, events.Events[0])
+	assert.Nil(t, events.NextFrom)
+
+	otherID, _ := td.db.SetRecord(&record.ObjectActivateRecord{})
+	td.db.SetObjectIndex(otherID, &index.ObjectLifeline{LatestState: *otherID})
+	_, err = td.manager.messageBus.Send(&message.GetEvents{Object: *genRefWithID(otherID), FromEvent: first, Amount: 10})
+	assert.Error(t, err, "events of another object shouldn't be returned")
+}
+
 func TestLedgerArtifactManager_HandleJetDrop(t *testing.T) {
 	t.Parallel()
 	td, cleaner := prepareAMTestData(t)
@@ -1016,3 +1174,66 @@ func TestLedgerArtifactManager_HandleJetDrop(t *testing.T) {
 		assert.Equal(t, records[i], *rec.(*record.ObjectActivateRecord))
 	}
 }
+
+func TestMessageHandler_RejectsUnauthorizedSender(t *testing.T) {
+	t.Parallel()
+	td, cleaner := prepareAMTestData(t)
+	defer cleaner()
+
+	classID, _ := td.db.SetRecord(&record.ClassActivateRecord{})
+	td.db.SetClassIndex(classID, &index.ClassLifeline{
+		LatestState: *classID,
+	})
+
+	mb := td.manager.messageBus.(*messageBusMock)
+	msg := &message.DeclareType{
+		Domain:  *domainRef.CoreRef(),
+		Request: *td.requestRef.CoreRef(),
+		Class:   *genRefWithID(classID),
+		TypeDec: []byte{1, 2, 3},
+	}
+
+	rep, err := mb.handlers[core.TypeDeclareType](&message.SignedMessage{
+		Msg:    msg,
+		Sender: *genRandomRef(0).CoreRef(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &reply.Error{ErrType: reply.ErrUnauthorized}, rep)
+	assert.Equal(t, core.ErrUnauthorized, rep.(*reply.Error).Error())
+
+	rep, err = mb.Send(msg)
+	require.NoError(t, err)
+	assert.IsType(t, &reply.Reference{}, rep)
+}
+
+func TestMessageHandler_AuthorizesMutatedObject(t *testing.T) {
+	t.Parallel()
+	td, cleaner := prepareAMTestData(t)
+	defer cleaner()
+
+	request := *genRandomRef(0).CoreRef()
+	class := *genRandomRef(0).CoreRef()
+	code := *genRandomRef(0).CoreRef()
+	assert.Equal(t, request, *mutatedObject(&message.ActivateObject{Request: request, Class: class}))
+	assert.Equal(t, request, *mutatedObject(&message.ActivateClass{Request: request, Code: code}))
+	assert.Equal(t, request, *mutatedObject(&message.DeployCode{Request: request}))
+	assert.Equal(t, class, *mutatedObject(&message.UpdateClass{Request: request, Class: class, Code: code}))
+	assert.Equal(t, code, *mutatedObject(&message.ActivateObjectDelegate{Request: request, Class: class, Parent: code}))
+	assert.Nil(t, mutatedObject(&message.GetCode{Code: code}))
+
+	mb := td.manager.messageBus.(*messageBusMock)
+	_, err := mb.Send(&message.ActivateObject{Domain: *domainRef.CoreRef(), Class: class})
+	assert.Error(t, err, "message without changed object should be rejected")
+
+	deployer := *genRandomRef(0).CoreRef()
+	handler := MessageHandler{db: td.db, jc: mb.jc, deployers: map[core.RecordRef]bool{deployer: true}}
+	accept := handler.authorized(func(core.SignedMessage) (core.Reply, error) {
+		return &reply.OK{}, nil
+	})
+	rep, err := accept(&message.SignedMessage{Msg: &message.DeployCode{Request: request}, Sender: deployer})
+	require.NoError(t, err)
+	assert.Equal(t, &reply.OK{}, rep)
+	rep, err = accept(&message.SignedMessage{Msg: &message.ActivateObject{Request: request}, Sender: deployer})
+	require.NoError(t, err)
+	assert.Equal(t, &reply.Error{ErrType: reply.ErrUnauthorized}, rep, "deployer can't activate objects")
+}
@@ -73,6 +73,9 @@ type ClassDescriptor struct {
 	state       core.RecordID
 	code        *core.RecordRef // Can be nil.
 	machineType core.MachineType
+	prevState   *core.RecordID // Can be nil.
+	migrations  []core.RecordRef
+	owner       string
 }
 
 // HeadRef returns head reference to represented class record.
@@ -97,6 +100,21 @@ func (d *ClassDescriptor) CodeDescriptor() core.CodeDescriptor {
 	return d.cache.codeDescriptor
 }
 
+// PrevStateID returns reference to class state amended by represented state, nil for the first state.
+func (d *ClassDescriptor) PrevStateID() *core.RecordID {
+	return d.prevState
+}
+
+// Migrations returns references to code migrating objects memory from the previous class state.
+func (d *ClassDescriptor) Migrations() []core.RecordRef {
+	return d.migrations
+}
+
+// Owner returns public key of the class deployer, empty if the class has no owner.
+func (d *ClassDescriptor) Owner() string {
+	return d.owner
+}
+
 // ObjectDescriptor represents meta info required to fetch all object data.
 type ObjectDescriptor struct {
 	cache struct {
@@ -104,11 +122,12 @@ type ObjectDescriptor struct {
 	}
 	am *LedgerArtifactManager
 
-	head     core.RecordRef
-	state    core.RecordID
-	class    core.RecordRef
-	memory   []byte
-	children []core.RecordRef
+	head       core.RecordRef
+	state      core.RecordID
+	class      core.RecordRef
+	memory     []byte
+	children   []core.RecordRef
+	classState *core.RecordID // Can be nil.
 }
 
 // HeadRef returns reference to represented object record.
@@ -126,6 +145,11 @@ func (d *ObjectDescriptor) Memory() []byte {
 	return d.memory
 }
 
+// ClassStateID returns reference to class state which object memory conforms to, nil if it's unknown.
+func (d *ObjectDescriptor) ClassStateID() *core.RecordID {
+	return d.classState
+}
+
 // Children returns object's children references.
 func (d *ObjectDescriptor) Children(pulse *core.PulseNumber) (core.RefIterator, error) {
 	return d.am.GetChildren(d.head, pulse)
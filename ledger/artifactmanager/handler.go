@@ -23,6 +23,7 @@ import (
 	"github.com/insolar/insolar/log"
 	"github.com/pkg/errors"
 
+	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
@@ -32,12 +33,18 @@ import (
 
 // MessageHandler processes messages for local storage interaction.
 type MessageHandler struct {
-	db *storage.DB
+	db        *storage.DB
+	jc        core.JetCoordinator
+	deployers map[core.RecordRef]bool
 }
 
 // NewMessageHandler creates new handler.
-func NewMessageHandler(db *storage.DB) (*MessageHandler, error) {
-	return &MessageHandler{db: db}, nil
+func NewMessageHandler(db *storage.DB, jc core.JetCoordinator, conf configuration.Ledger) (*MessageHandler, error) {
+	deployers := map[core.RecordRef]bool{}
+	for _, d := range conf.Deployers {
+		deployers[core.NewRefFromBase58(d)] = true
+	}
+	return &MessageHandler{db: db, jc: jc, deployers: deployers}, nil
 }
 
 // Link links external components.
@@ -49,22 +56,93 @@ func (h *MessageHandler) Link(components core.Components) error {
 	bus.MustRegister(core.TypeGetObject, h.handleGetObject)
 	bus.MustRegister(core.TypeGetDelegate, h.handleGetDelegate)
 	bus.MustRegister(core.TypeGetChildren, h.handleGetChildren)
-	bus.MustRegister(core.TypeDeclareType, h.handleDeclareType)
-	bus.MustRegister(core.TypeDeployCode, h.handleDeployCode)
-	bus.MustRegister(core.TypeActivateClass, h.handleActivateClass)
-	bus.MustRegister(core.TypeDeactivateClass, h.handleDeactivateClass)
-	bus.MustRegister(core.TypeUpdateClass, h.handleUpdateClass)
-	bus.MustRegister(core.TypeActivateObject, h.handleActivateObject)
-	bus.MustRegister(core.TypeActivateObjectDelegate, h.handleActivateObjectDelegate)
-	bus.MustRegister(core.TypeDeactivateObject, h.handleDeactivateObject)
-	bus.MustRegister(core.TypeUpdateObject, h.handleUpdateObject)
-	bus.MustRegister(core.TypeRegisterChild, h.handleRegisterChild)
+	bus.MustRegister(core.TypeDeclareType, h.authorized(h.handleDeclareType))
+	bus.MustRegister(core.TypeDeployCode, h.authorized(h.handleDeployCode))
+	bus.MustRegister(core.TypeActivateClass, h.authorized(h.handleActivateClass))
+	bus.MustRegister(core.TypeDeactivateClass, h.authorized(h.handleDeactivateClass))
+	bus.MustRegister(core.TypeUpdateClass, h.authorized(h.handleUpdateClass))
+	bus.MustRegister(core.TypeActivateObject, h.authorized(h.handleActivateObject))
+	bus.MustRegister(core.TypeActivateObjectDelegate, h.authorized(h.handleActivateObjectDelegate))
+	bus.MustRegister(core.TypeDeactivateObject, h.authorized(h.handleDeactivateObject))
+	bus.MustRegister(core.TypeUpdateObject, h.authorized(h.handleUpdateObject))
+	bus.MustRegister(core.TypeRegisterChild, h.authorized(h.handleRegisterChild))
+	bus.MustRegister(core.TypeEmitEvent, h.authorized(h.handleEmitEvent))
+	bus.MustRegister(core.TypeGetEvents, h.handleGetEvents)
+	bus.MustRegister(core.TypeGetClassType, h.handleGetClassType)
 	bus.MustRegister(core.TypeJetDrop, h.handleJetDrop)
 	bus.MustRegister(core.TypeRequestCall, h.handleRegisterRequest)
 
 	return nil
 }
 
+// authorized wraps handler of mutating message. Message is rejected with ErrUnauthorized reply if its sender is not
+// the current virtual executor for the object changed by the message. Code and classes are also accepted from
+// deployer nodes of the configuration.
+func (h *MessageHandler) authorized(handler core.MessageHandler) core.MessageHandler {
+	return func(genericMsg core.SignedMessage) (core.Reply, error) {
+		if isDeploy(genericMsg.Message()) && h.deployers[genericMsg.GetSender()] {
+			return handler(genericMsg)
+		}
+		obj := mutatedObject(genericMsg.Message())
+		if obj == nil || *obj == (core.RecordRef{}) {
+			return nil, errors.Errorf("no object is changed by %s message", genericMsg.Message().Type())
+		}
+		pulse, err := h.db.GetLatestPulseNumber()
+		if err != nil {
+			return nil, err
+		}
+		ok, err := h.jc.IsAuthorized(core.RoleVirtualExecutor, *obj, pulse, genericMsg.GetSender())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check sender authorization")
+		}
+		if !ok {
+			log.Warnf("unauthorized %s message from %s", genericMsg.Message().Type(), genericMsg.GetSender())
+			return &reply.Error{ErrType: reply.ErrUnauthorized}, nil
+		}
+		return handler(genericMsg)
+	}
+}
+
+// isDeploy checks if the message deploys code or changes a class.
+func isDeploy(msg core.Message) bool {
+	switch msg.(type) {
+	case *message.DeployCode, *message.ActivateClass, *message.DeclareType, *message.UpdateClass,
+		*message.DeactivateClass:
+		return true
+	}
+	return false
+}
+
+// mutatedObject returns the object changed by the message. Activations and code deploy create the object with
+// reference of the request. Delegates are created by executor of their parent.
+func mutatedObject(msg core.Message) *core.RecordRef {
+	switch m := msg.(type) {
+	case *message.DeployCode:
+		return &m.Request
+	case *message.ActivateClass:
+		return &m.Request
+	case *message.ActivateObject:
+		return &m.Request
+	case *message.ActivateObjectDelegate:
+		return &m.Parent
+	case *message.DeclareType:
+		return &m.Class
+	case *message.DeactivateClass:
+		return &m.Class
+	case *message.UpdateClass:
+		return &m.Class
+	case *message.DeactivateObject:
+		return &m.Object
+	case *message.UpdateObject:
+		return &m.Object
+	case *message.RegisterChild:
+		return &m.Parent
+	case *message.EmitEvent:
+		return &m.Object
+	}
+	return nil
+}
+
 func logTimeInside(start time.Time, funcName string) {
 	if time.Since(start) > time.Second {
 		log.Debugf("Handle takes too long: %s: time inside - %s", funcName, time.Since(start))
@@ -72,10 +150,10 @@ func logTimeInside(start time.Time, funcName string) {
 }
 
 func (h *MessageHandler) handleRegisterRequest(
-	genericMsg core.Message,
+	genericMsg core.SignedMessage,
 ) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.RequestCall)
+	msg := genericMsg.Message().(*message.RequestCall)
 	requestRec := &record.CallRequest{
 		Payload: message.MustSerializeBytes(msg.Message),
 	}
@@ -89,9 +167,9 @@ func (h *MessageHandler) handleRegisterRequest(
 	return &reply.ID{ID: *id.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleGetCode(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetCode(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.GetCode)
+	msg := genericMsg.Message().(*message.GetCode)
 	codeRef := record.Core2Reference(msg.Code)
 
 	codeRec, err := getCode(h.db, codeRef.Record)
@@ -109,12 +187,12 @@ func (h *MessageHandler) handleGetCode(genericMsg core.Message) (core.Reply, err
 	return &rep, nil
 }
 
-func (h *MessageHandler) handleGetClass(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetClass(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.GetClass)
+	msg := genericMsg.Message().(*message.GetClass)
 	headRef := record.Core2Reference(msg.Head)
 
-	_, stateID, state, err := getClass(h.db, &headRef.Record, msg.State)
+	idx, stateID, state, err := getClass(h.db, &headRef.Record, msg.State)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +209,13 @@ func (h *MessageHandler) handleGetClass(genericMsg core.Message) (core.Reply, er
 		State:       *stateID,
 		Code:        code,
 		MachineType: state.GetMachineType(),
+		Owner:       idx.Owner,
+	}
+	if amend, ok := state.(*record.ClassAmendRecord); ok {
+		rep.PrevState = amend.AmendedRecord.CoreID()
+		for _, migration := range amend.Migrations {
+			rep.Migrations = append(rep.Migrations, *migration.CoreRef())
+		}
 	}
 
 	logTimeInside(start, "handleGetClass")
@@ -138,9 +223,9 @@ func (h *MessageHandler) handleGetClass(genericMsg core.Message) (core.Reply, er
 	return &rep, nil
 }
 
-func (h *MessageHandler) handleGetObject(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetObject(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.GetObject)
+	msg := genericMsg.Message().(*message.GetObject)
 	headRef := record.Core2Reference(msg.Head)
 
 	idx, stateID, state, err := getObject(h.db, &headRef.Record, msg.State)
@@ -157,15 +242,18 @@ func (h *MessageHandler) handleGetObject(genericMsg core.Message) (core.Reply, e
 		Class:  *idx.ClassRef.CoreRef(),
 		Memory: state.GetMemory(),
 	}
+	if classState := state.GetClassState(); classState != nil {
+		rep.ClassState = classState.CoreID()
+	}
 
 	logTimeInside(start, "handleGetObject")
 
 	return &rep, nil
 }
 
-func (h *MessageHandler) handleGetDelegate(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetDelegate(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.GetDelegate)
+	msg := genericMsg.Message().(*message.GetDelegate)
 	headRef := record.Core2Reference(msg.Head)
 
 	idx, _, _, err := getObject(h.db, &headRef.Record, nil)
@@ -187,9 +275,9 @@ func (h *MessageHandler) handleGetDelegate(genericMsg core.Message) (core.Reply,
 	return &rep, nil
 }
 
-func (h *MessageHandler) handleGetChildren(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetChildren(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.GetChildren)
+	msg := genericMsg.Message().(*message.GetChildren)
 	parentRef := record.Core2Reference(msg.Parent)
 
 	idx, _, _, err := getObject(h.db, &parentRef.Record, nil)
@@ -232,6 +320,10 @@ func (h *MessageHandler) handleGetChildren(genericMsg core.Message) (core.Reply,
 		if msg.FromPulse != nil && childRec.Ref.Record.Pulse > *msg.FromPulse {
 			continue
 		}
+		// Skip children deactivated by reverted calls.
+		if _, _, _, err := getObject(h.db, &childRec.Ref.Record, nil); err == ErrObjectDeactivated {
+			continue
+		}
 		refs = append(refs, *childRec.Ref.CoreRef())
 	}
 
@@ -240,27 +332,46 @@ func (h *MessageHandler) handleGetChildren(genericMsg core.Message) (core.Reply,
 	return &reply.Children{Refs: refs, NextFrom: nil}, nil
 }
 
-func (h *MessageHandler) handleDeclareType(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleDeclareType(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.DeclareType)
+	msg := genericMsg.Message().(*message.DeclareType)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
+	classRef := record.Core2Reference(msg.Class)
 
-	rec := record.TypeRecord{
-		StorageRecord: record.StorageRecord{
-			StatefulResult: record.StatefulResult{
-				ResultRecord: record.ResultRecord{
-					DomainRecord:  domainRef,
-					RequestRecord: requestRef,
+	var typeID *record.ID
+	err := h.db.Update(func(tx *storage.TransactionManager) error {
+		idx, _, _, err := getClass(tx, &classRef.Record, nil)
+		if err != nil {
+			return err
+		}
+
+		rec := record.TypeRecord{
+			StorageRecord: record.StorageRecord{
+				StatefulResult: record.StatefulResult{
+					ResultRecord: record.ResultRecord{
+						DomainRecord:  domainRef,
+						RequestRecord: requestRef,
+					},
 				},
 			},
-		},
-		TypeDeclaration: msg.TypeDec,
-	}
-	typeID, err := h.db.SetRecord(&rec)
+			Class:           classRef,
+			TypeDeclaration: msg.TypeDec,
+		}
+		typeID, err = tx.SetRecord(&rec)
+		if err != nil {
+			return errors.Wrap(err, "failed to store record")
+		}
+		idx.LatestType = typeID
+		err = tx.SetClassIndex(&classRef.Record, idx)
+		if err != nil {
+			return errors.Wrap(err, "failed to store lifeline index")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to store record")
+		return nil, err
 	}
 
 	logTimeInside(start, "handleDeclareType")
@@ -268,9 +379,38 @@ func (h *MessageHandler) handleDeclareType(genericMsg core.Message) (core.Reply,
 	return &reply.Reference{Ref: *getReference(&msg.Request, typeID)}, nil
 }
 
-func (h *MessageHandler) handleDeployCode(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleGetClassType(genericMsg core.SignedMessage) (core.Reply, error) {
+	start := time.Now()
+	msg := genericMsg.Message().(*message.GetClassType)
+	classRef := record.Core2Reference(msg.Class)
+
+	idx, err := h.db.GetClassIndex(&classRef.Record, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch class index")
+	}
+	if idx.LatestType == nil {
+		return nil, ErrNotFound
+	}
+	rec, err := h.db.GetRecord(idx.LatestType)
+	if err != nil {
+		return nil, err
+	}
+	typeRec, ok := rec.(*record.TypeRecord)
+	if !ok {
+		return nil, errors.New("invalid type record")
+	}
+
+	logTimeInside(start, "handleGetClassType")
+
+	return &reply.ClassType{
+		Ref:             *getReference(&msg.Class, idx.LatestType),
+		TypeDeclaration: typeRec.TypeDeclaration,
+	}, nil
+}
+
+func (h *MessageHandler) handleDeployCode(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.DeployCode)
+	msg := genericMsg.Message().(*message.DeployCode)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -297,9 +437,9 @@ func (h *MessageHandler) handleDeployCode(genericMsg core.Message) (core.Reply,
 	return &reply.Reference{Ref: *getReference(&msg.Request, codeID)}, nil
 }
 
-func (h *MessageHandler) handleActivateClass(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleActivateClass(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.ActivateClass)
+	msg := genericMsg.Message().(*message.ActivateClass)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -321,6 +461,7 @@ func (h *MessageHandler) handleActivateClass(genericMsg core.Message) (core.Repl
 		},
 		Code:        codeRef,
 		MachineType: codeRec.MachineType,
+		Owner:       msg.Owner,
 	}
 
 	var activateID *record.ID
@@ -331,6 +472,7 @@ func (h *MessageHandler) handleActivateClass(genericMsg core.Message) (core.Repl
 		}
 		err = tx.SetClassIndex(&requestRef.Record, &index.ClassLifeline{
 			LatestState: *activateID,
+			Owner:       msg.Owner,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to store lifeline index")
@@ -348,9 +490,9 @@ func (h *MessageHandler) handleActivateClass(genericMsg core.Message) (core.Repl
 	return &reply.ID{ID: *activateID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleDeactivateClass(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleDeactivateClass(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.DeactivateClass)
+	msg := genericMsg.Message().(*message.DeactivateClass)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -399,9 +541,9 @@ func (h *MessageHandler) handleDeactivateClass(genericMsg core.Message) (core.Re
 	return &reply.ID{ID: *deactivationID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleUpdateClass(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleUpdateClass(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.UpdateClass)
+	msg := genericMsg.Message().(*message.UpdateClass)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -468,20 +610,20 @@ func (h *MessageHandler) handleUpdateClass(genericMsg core.Message) (core.Reply,
 	return &reply.ID{ID: *amendID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleActivateObject(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleActivateObject(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.ActivateObject)
+	msg := genericMsg.Message().(*message.ActivateObject)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
 	classRef := record.Core2Reference(msg.Class)
 	parentRef := record.Core2Reference(msg.Parent)
 
-	var err error
-	_, _, _, err = getClass(h.db, &classRef.Record, nil)
+	_, classStateID, _, err := getClass(h.db, &classRef.Record, nil)
 	if err != nil {
 		return nil, err
 	}
+	classState := record.Bytes2ID(classStateID[:])
 	_, _, _, err = getObject(h.db, &parentRef.Record, nil)
 	if err != nil {
 		return nil, err
@@ -498,10 +640,11 @@ func (h *MessageHandler) handleActivateObject(genericMsg core.Message) (core.Rep
 					},
 				},
 			},
-			Class:    classRef,
-			Memory:   msg.Memory,
-			Parent:   parentRef,
-			Delegate: false,
+			Class:      classRef,
+			ClassState: &classState,
+			Memory:     msg.Memory,
+			Parent:     parentRef,
+			Delegate:   false,
 		}
 
 		// save new record and it's index
@@ -543,20 +686,20 @@ func (h *MessageHandler) handleActivateObject(genericMsg core.Message) (core.Rep
 	return &reply.ID{ID: *activateID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleActivateObjectDelegate(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleActivateObjectDelegate(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.ActivateObjectDelegate)
+	msg := genericMsg.Message().(*message.ActivateObjectDelegate)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
 	classRef := record.Core2Reference(msg.Class)
 	parentRef := record.Core2Reference(msg.Parent)
 
-	var err error
-	_, _, _, err = getClass(h.db, &classRef.Record, nil)
+	_, classStateID, _, err := getClass(h.db, &classRef.Record, nil)
 	if err != nil {
 		return nil, err
 	}
+	classState := record.Bytes2ID(classStateID[:])
 	_, _, _, err = getObject(h.db, &parentRef.Record, nil)
 	if err != nil {
 		return nil, err
@@ -573,10 +716,11 @@ func (h *MessageHandler) handleActivateObjectDelegate(genericMsg core.Message) (
 					},
 				},
 			},
-			Class:    classRef,
-			Memory:   msg.Memory,
-			Parent:   parentRef,
-			Delegate: true,
+			Class:      classRef,
+			ClassState: &classState,
+			Memory:     msg.Memory,
+			Parent:     parentRef,
+			Delegate:   true,
 		}
 
 		// save new record and it's index
@@ -618,9 +762,9 @@ func (h *MessageHandler) handleActivateObjectDelegate(genericMsg core.Message) (
 	return &reply.ID{ID: *activationID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleDeactivateObject(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleDeactivateObject(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.DeactivateObject)
+	msg := genericMsg.Message().(*message.DeactivateObject)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -669,9 +813,9 @@ func (h *MessageHandler) handleDeactivateObject(genericMsg core.Message) (core.R
 	return &reply.ID{ID: *deactivationID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleUpdateObject(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleUpdateObject(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.UpdateObject)
+	msg := genericMsg.Message().(*message.UpdateObject)
 
 	domainRef := record.Core2Reference(msg.Domain)
 	requestRef := record.Core2Reference(msg.Request)
@@ -682,10 +826,15 @@ func (h *MessageHandler) handleUpdateObject(genericMsg core.Message) (core.Reply
 		amendID *record.ID
 	)
 	err = h.db.Update(func(tx *storage.TransactionManager) error {
-		idx, _, _, err := getObject(tx, &objRef.Record, nil)
+		idx, _, state, err := getObject(tx, &objRef.Record, nil)
 		if err != nil {
 			return err
 		}
+		classState := state.GetClassState()
+		if msg.ClassState != nil {
+			id := record.Bytes2ID(msg.ClassState[:])
+			classState = &id
+		}
 
 		rec := record.ObjectAmendRecord{
 			AmendRecord: record.AmendRecord{
@@ -697,7 +846,8 @@ func (h *MessageHandler) handleUpdateObject(genericMsg core.Message) (core.Reply
 				},
 				AmendedRecord: idx.LatestState,
 			},
-			NewMemory: msg.Memory,
+			NewMemory:  msg.Memory,
+			ClassState: classState,
 		}
 
 		amendID, err = tx.SetRecord(&rec)
@@ -722,9 +872,9 @@ func (h *MessageHandler) handleUpdateObject(genericMsg core.Message) (core.Reply
 	return &reply.ID{ID: *amendID.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleRegisterChild(genericMsg core.Message) (core.Reply, error) {
+func (h *MessageHandler) handleRegisterChild(genericMsg core.SignedMessage) (core.Reply, error) {
 	start := time.Now()
-	msg := genericMsg.(*message.RegisterChild)
+	msg := genericMsg.Message().(*message.RegisterChild)
 	parentRef := record.Core2Reference(msg.Parent)
 
 	var child *record.ID
@@ -760,8 +910,107 @@ func (h *MessageHandler) handleRegisterChild(genericMsg core.Message) (core.Repl
 	return &reply.ID{ID: *child.CoreID()}, nil
 }
 
-func (h *MessageHandler) handleJetDrop(genericMsg core.Message) (core.Reply, error) {
-	msg := genericMsg.(*message.JetDrop)
+func (h *MessageHandler) handleEmitEvent(genericMsg core.SignedMessage) (core.Reply, error) {
+	start := time.Now()
+	msg := genericMsg.Message().(*message.EmitEvent)
+	objRef := record.Core2Reference(msg.Object)
+
+	var event *record.ID
+	err := h.db.Update(func(tx *storage.TransactionManager) error {
+		// events published before deactivation are stored too
+		idx, err := tx.GetObjectIndex(&objRef.Record, true)
+		if err != nil {
+			return errors.Wrap(err, "inconsistent object index")
+		}
+
+		rec := record.EventRecord{
+			StatefulResult: record.StatefulResult{
+				ResultRecord: record.ResultRecord{
+					DomainRecord:  record.Core2Reference(msg.Domain),
+					RequestRecord: record.Core2Reference(msg.Request),
+				},
+			},
+			PrevEvent: idx.LatestEvent,
+			Object:    objRef,
+			Name:      msg.Name,
+			Payload:   msg.Payload,
+		}
+		event, err = tx.SetRecord(&rec)
+		if err != nil {
+			return errors.Wrap(err, "failed to store event")
+		}
+		idx.LatestEvent = event
+		err = tx.SetObjectIndex(&objRef.Record, idx)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logTimeInside(start, "handleEmitEvent")
+
+	return &reply.ID{ID: *event.CoreID()}, nil
+}
+
+func (h *MessageHandler) handleGetEvents(genericMsg core.SignedMessage) (core.Reply, error) {
+	start := time.Now()
+	msg := genericMsg.Message().(*message.GetEvents)
+	objRef := record.Core2Reference(msg.Object)
+
+	idx, err := h.db.GetObjectIndex(&objRef.Record, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch object index")
+	}
+
+	var (
+		events       []core.Event
+		currentEvent *record.ID
+	)
+
+	// Counting from specified event or the latest.
+	if msg.FromEvent != nil {
+		id := record.Bytes2ID(msg.FromEvent[:])
+		currentEvent = &id
+	} else {
+		currentEvent = idx.LatestEvent
+	}
+
+	for currentEvent != nil {
+		// We have enough results.
+		if len(events) >= msg.Amount {
+			return &reply.Events{Events: events, NextFrom: currentEvent.CoreID()}, nil
+		}
+
+		rec, err := h.db.GetRecord(currentEvent)
+		if err != nil {
+			return nil, errors.New("failed to retrieve events")
+		}
+		eventRec, ok := rec.(*record.EventRecord)
+		if !ok {
+			return nil, errors.New("failed to retrieve events")
+		}
+		if eventRec.Object.IsNotEqual(objRef) {
+			return nil, errors.New("event belongs to another object")
+		}
+		events = append(events, core.Event{
+			ID:      *currentEvent.CoreID(),
+			Name:    eventRec.Name,
+			Payload: eventRec.Payload,
+		})
+		currentEvent = eventRec.PrevEvent
+	}
+
+	logTimeInside(start, "handleGetEvents")
+
+	return &reply.Events{Events: events, NextFrom: nil}, nil
+}
+
+func (h *MessageHandler) handleJetDrop(genericMsg core.SignedMessage) (core.Reply, error) {
+	msg := genericMsg.Message().(*message.JetDrop)
 
 	// TODO: validate
 	for _, rec := range msg.Records {
@@ -25,6 +25,8 @@ import (
 type ClassLifeline struct {
 	LatestState record.ID   // Amend or activate record
 	AmendRefs   []record.ID // ClassAmendRecord
+	LatestType  *record.ID  // TypeRecord
+	Owner       string      // Public key of the class deployer
 }
 
 // ObjectLifeline represents meta information for record object
@@ -32,5 +34,6 @@ type ObjectLifeline struct {
 	ClassRef    record.Reference
 	LatestState record.ID  // Amend or activate record
 	LatestChild *record.ID // Meta record about child activation
+	LatestEvent *record.ID // Event published by the object
 	Delegates   map[core.RecordRef]record.Reference
 }
@@ -90,6 +90,12 @@ func (jc *JetCoordinator) IsAuthorized(
 func (jc *JetCoordinator) QueryRole(
 	role core.JetRole, obj core.RecordRef, pulse core.PulseNumber,
 ) ([]core.RecordRef, error) {
+	// TODO: select nodes by object's jet when jets are split.
+	return jc.QueryRoleNodes(role, pulse)
+}
+
+// QueryRoleNodes returns refs of all nodes acting in role on given pulse regardless of object affinity.
+func (jc *JetCoordinator) QueryRoleNodes(role core.JetRole, pulse core.PulseNumber) ([]core.RecordRef, error) {
 	pulseData, err := jc.db.GetPulse(pulse)
 	if err != nil {
 		return nil, err
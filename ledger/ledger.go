@@ -69,7 +69,7 @@ func NewLedger(conf configuration.Ledger) (*Ledger, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "pulse manager creation failed")
 	}
-	handler, err := artifactmanager.NewMessageHandler(db)
+	handler, err := artifactmanager.NewMessageHandler(db, jc, conf)
 	if err != nil {
 		return nil, err
 	}
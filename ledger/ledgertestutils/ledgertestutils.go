@@ -17,12 +17,14 @@
 package ledgertestutils
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/ledger/artifactmanager"
 	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/pulsemanager"
@@ -32,12 +34,15 @@ import (
 	"github.com/insolar/insolar/ledger/storage/storagetest"
 )
 
+// messageBusMock delivers messages to local handlers on behalf of the current virtual executor.
 type messageBusMock struct {
 	handlers map[core.MessageType]core.MessageHandler
+	jc       core.JetCoordinator
+	pm       core.PulseManager
 }
 
-func newMessageBusMock() *messageBusMock {
-	return &messageBusMock{handlers: map[core.MessageType]core.MessageHandler{}}
+func newMessageBusMock(jc core.JetCoordinator, pm core.PulseManager) *messageBusMock {
+	return &messageBusMock{handlers: map[core.MessageType]core.MessageHandler{}, jc: jc, pm: pm}
 }
 
 func (mb *messageBusMock) Register(p core.MessageType, handler core.MessageHandler) error {
@@ -72,7 +77,22 @@ func (mb *messageBusMock) Send(m core.Message) (core.Reply, error) {
 		return nil, errors.New(fmt.Sprint("no handler for message type:", t.String()))
 	}
 
-	return handler(m)
+	pulse, err := mb.pm.Current()
+	if err != nil {
+		return nil, err
+	}
+	executors, err := mb.jc.QueryRoleNodes(core.RoleVirtualExecutor, pulse.PulseNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(&message.SignedMessage{Msg: m, Sender: executors[0]})
+}
+
+func (mb *messageBusMock) SendWithOptions(
+	ctx context.Context, m core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return mb.Send(m)
 }
 
 func (mb *messageBusMock) SendAsync(m core.Message) {
@@ -86,17 +106,17 @@ func TmpLedger(t testing.TB, lr core.LogicRunner, dir string) (*ledger.Ledger, f
 	// Init subcomponents.
 	conf := configuration.NewLedger()
 	db, dbcancel := storagetest.TmpDB(t, dir)
-	handler, err := artifactmanager.NewMessageHandler(db)
-	assert.NoError(t, err)
 	am, err := artifactmanager.NewArtifactManger(db)
 	assert.NoError(t, err)
 	jc, err := jetcoordinator.NewJetCoordinator(db, conf.JetCoordinator)
 	assert.NoError(t, err)
+	handler, err := artifactmanager.NewMessageHandler(db, jc, conf)
+	assert.NoError(t, err)
 	pm, err := pulsemanager.NewPulseManager(db)
 	assert.NoError(t, err)
 
 	// Init components.
-	mb := newMessageBusMock()
+	mb := newMessageBusMock(jc, pm)
 	components := core.Components{MessageBus: mb, LogicRunner: lr}
 
 	// Create ledger.
@@ -19,8 +19,10 @@ package pulsemanager
 import (
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
 	"github.com/insolar/insolar/ledger/jetdrop"
 	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/log"
 )
 
 // PulseManager implements core.PulseManager.
@@ -80,10 +82,13 @@ func (m *PulseManager) Set(pulse core.Pulse) error {
 	if err != nil {
 		return err
 	}
-	_, err = m.bus.Send(&message.JetDrop{Drop: dropSerialized, Records: records})
+	rep, err := m.bus.Send(&message.JetDrop{Drop: dropSerialized, Records: records})
 	if err != nil {
 		return err
 	}
+	if aggregated, ok := rep.(*reply.Aggregated); ok && aggregated.Partial() {
+		log.Warnf("jet drop is delivered partially: %s", aggregated.Err())
+	}
 
 	return m.lr.OnPulse(pulse)
 }
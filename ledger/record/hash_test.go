@@ -50,6 +50,7 @@ var emptyRecordsGens = []recordgen{
 	func() Record { return &StatefulExceptionResult{} },
 	func() Record { return &EnforcedObjectAmendRecord{} },
 	func() Record { return &ObjectAppendRecord{} },
+	func() Record { return &EventRecord{} },
 }
 
 func Test_HashesNotTheSameOnDifferentTypes(t *testing.T) {
@@ -39,6 +39,11 @@ func (*GenesisRecord) GetMemory() []byte {
 	return nil
 }
 
+// GetClassState returns class state which memory conforms to.
+func (*GenesisRecord) GetClassState() *ID {
+	return nil
+}
+
 // GetCode returns state code.
 func (*GenesisRecord) GetCode() *Reference {
 	return nil
@@ -40,6 +40,8 @@ type ObjectState interface {
 	IsAmend() bool
 	// GetMemory returns state memory.
 	GetMemory() []byte
+	// GetClassState returns class state which memory conforms to.
+	GetClassState() *ID
 }
 
 // ReasonCode is an error reason code.
@@ -142,6 +144,7 @@ type ClassActivateRecord struct {
 	Code          Reference
 	MachineType   core.MachineType
 	DefaultMemory Memory
+	Owner         string // Public key of the class deployer.
 }
 
 func (r *ClassActivateRecord) GetMachineType() core.MachineType {
@@ -167,10 +170,11 @@ func (r *ClassActivateRecord) GetCode() *Reference {
 type ObjectActivateRecord struct {
 	ActivationRecord
 
-	Class    Reference
-	Memory   Memory
-	Parent   Reference
-	Delegate bool
+	Class      Reference
+	ClassState *ID
+	Memory     Memory
+	Parent     Reference
+	Delegate   bool
 }
 
 // IsDeactivation determines if current state is deactivation.
@@ -188,6 +192,11 @@ func (r *ObjectActivateRecord) GetMemory() []byte {
 	return r.Memory
 }
 
+// GetClassState returns class state which memory conforms to.
+func (r *ObjectActivateRecord) GetClassState() *ID {
+	return r.ClassState
+}
+
 // StorageRecord is produced when we store something in ledger. Code, data etc.
 type StorageRecord struct {
 	StatefulResult
@@ -205,9 +214,20 @@ type CodeRecord struct {
 type TypeRecord struct {
 	StorageRecord
 
+	Class           Reference // Class the type describes.
 	TypeDeclaration []byte
 }
 
+// EventRecord is an event published by an object during a call. Events of the object are linked into a list.
+type EventRecord struct {
+	StatefulResult
+
+	PrevEvent *ID
+	Object    Reference
+	Name      string
+	Payload   []byte
+}
+
 // AmendRecord is produced when we modify another record in ledger.
 type AmendRecord struct {
 	StatefulResult
@@ -267,6 +287,11 @@ func (*DeactivationRecord) GetMemory() []byte {
 	return nil
 }
 
+// GetClassState returns class state which memory conforms to.
+func (*DeactivationRecord) GetClassState() *ID {
+	return nil
+}
+
 // GetCode returns state code.
 func (*DeactivationRecord) GetCode() *Reference {
 	return nil
@@ -276,7 +301,8 @@ func (*DeactivationRecord) GetCode() *Reference {
 type ObjectAmendRecord struct {
 	AmendRecord
 
-	NewMemory Memory
+	NewMemory  Memory
+	ClassState *ID
 }
 
 // IsDeactivation determines if current state is deactivation.
@@ -294,6 +320,11 @@ func (r *ObjectAmendRecord) GetMemory() []byte {
 	return r.NewMemory
 }
 
+// GetClassState returns class state which memory conforms to.
+func (r *ObjectAmendRecord) GetClassState() *ID {
+	return r.ClassState
+}
+
 // StatefulCallResult is a contract call result that produces new state.
 type StatefulCallResult struct {
 	ObjectAmendRecord
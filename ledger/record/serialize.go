@@ -154,6 +154,8 @@ const (
 	// meta
 	childRecordID   TypeID = 29
 	genesisRecordID TypeID = 30
+	// events
+	eventRecordID TypeID = 31
 )
 
 // getRecordByTypeID returns Record interface with concrete record type under the hood.
@@ -209,6 +211,8 @@ func getRecordByTypeID(id TypeID) Record { // nolint: gocyclo
 		return &ChildRecord{}
 	case genesisRecordID:
 		return &GenesisRecord{}
+	case eventRecordID:
+		return &EventRecord{}
 	default:
 		panic(fmt.Errorf("unknown record type id %v", id))
 	}
@@ -269,6 +273,8 @@ func getTypeIDbyRecord(rec Record) TypeID { // nolint: gocyclo, megacheck
 		return childRecordID
 	case *GenesisRecord:
 		return genesisRecordID
+	case *EventRecord:
+		return eventRecordID
 	default:
 		panic(fmt.Errorf("can't find record id by type %T", v))
 	}
@@ -153,15 +153,15 @@ func TestDB_CreateDrop(t *testing.T) {
 	id3, err := db.SetRecord(&records[2])
 	assert.NoError(t, err)
 	expectedRecData := [][2][]byte{
-		{record.ID2Bytes(*id1), record.MustEncodeRaw(record.MustEncodeToRaw(&records[0]))},
 		{record.ID2Bytes(*id2), record.MustEncodeRaw(record.MustEncodeToRaw(&records[1]))},
 		{record.ID2Bytes(*id3), record.MustEncodeRaw(record.MustEncodeToRaw(&records[2]))},
+		{record.ID2Bytes(*id1), record.MustEncodeRaw(record.MustEncodeToRaw(&records[0]))},
 	}
 
 	drop, recData, err := db.CreateDrop(pulse, []byte{4, 5, 6})
 	assert.NoError(t, err)
 	assert.Equal(t, pulse, drop.Pulse)
-	assert.Equal(t, "2rfH6sgiW5GygXXmFTfh9J6162hFUXRrB1Nz7P9", base58.Encode(drop.Hash))
+	assert.Equal(t, "37euiH6Nw2UYf5KfePAGgLGvSonEWQnHqUmCDEf", base58.Encode(drop.Hash))
 	assert.Equal(t, expectedRecData, recData)
 }
 
@@ -18,11 +18,16 @@
 package builtin
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/logicrunner/builtin/helloworld"
+	"github.com/insolar/insolar/logicrunner/goplugin/proxyctx"
 	"github.com/pkg/errors"
+	"github.com/tylerb/gls"
 	"github.com/ugorji/go/codec"
 )
 
@@ -30,48 +35,132 @@ import (
 type Contract interface {
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// contractType is a registered builtin contract
+type contractType struct {
+	typ          reflect.Type
+	constructors map[string]reflect.Value
+}
+
 // BuiltIn is a contract runner engine
 type BuiltIn struct {
-	AM       core.ArtifactManager
-	EB       core.MessageBus
-	Registry map[string]Contract
+	AM core.ArtifactManager
+	EB core.MessageBus
+
+	registry      map[string]*contractType
+	registryMutex sync.RWMutex
 }
 
-// NewBuiltIn is an constructor
-func NewBuiltIn(eb core.MessageBus, am core.ArtifactManager) *BuiltIn {
+// NewBuiltIn is an constructor, upstream provides services of logic runner to contracts
+func NewBuiltIn(eb core.MessageBus, am core.ArtifactManager, upstream Upstream) *BuiltIn {
 	bi := BuiltIn{
 		AM:       am,
 		EB:       eb,
-		Registry: make(map[string]Contract),
+		registry: make(map[string]*contractType),
 	}
 
-	bi.Registry["helloworld"] = helloworld.NewHelloWorld()
+	err := bi.Register("helloworld", &helloworld.HelloWorld{}, map[string]interface{}{
+		"NewHelloWorld": helloworld.NewHelloWorld,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	proxyctx.Current = &helper{upstream: upstream}
 
 	return &bi
 }
 
+// Register adds native contract to the engine, classes of builtin machine type with code equal to the name
+// run the contract. Prototype is a pointer to the contract struct, constructors are functions returning
+// such pointers.
+func (bi *BuiltIn) Register(name string, prototype Contract, constructors map[string]interface{}) error {
+	typ := reflect.TypeOf(prototype)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("contract %q should be a pointer to struct, got %T", name, prototype)
+	}
+
+	ct := &contractType{typ: typ, constructors: make(map[string]reflect.Value)}
+	for cname, constructor := range constructors {
+		f := reflect.ValueOf(constructor)
+		if f.Kind() != reflect.Func || f.Type().NumOut() != 1 || f.Type().Out(0) != typ {
+			return errors.Errorf("constructor %q of contract %q should be a function returning %s", cname, name, typ)
+		}
+		ct.constructors[cname] = f
+	}
+
+	bi.registryMutex.Lock()
+	defer bi.registryMutex.Unlock()
+	if _, ok := bi.registry[name]; ok {
+		return errors.Errorf("contract %q is already registered", name)
+	}
+	bi.registry[name] = ct
+	return nil
+}
+
+// contract finds registered contract by code of a class
+func (bi *BuiltIn) contract(codeRef core.RecordRef) (*contractType, error) {
+	codeDescriptor, err := bi.AM.GetCode(codeRef)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't find code")
+	}
+	code, err := codeDescriptor.Code()
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't get code")
+	}
+
+	bi.registryMutex.RLock()
+	defer bi.registryMutex.RUnlock()
+	c, ok := bi.registry[string(code)]
+	if !ok {
+		return nil, errors.New("Wrong reference for builtin contract")
+	}
+	return c, nil
+}
+
+// CallConstructor runs a constructor of a contract and returns memory of the new object
 func (bi *BuiltIn) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) (objectState []byte, err error) {
-	panic("implement me")
+	c, err := bi.contract(code)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := c.constructors[name]
+	if !ok {
+		return nil, errors.New("no constructor " + name + " in the contract")
+	}
+
+	in, err := decodeArguments(f.Type(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	resValues, err := call(ctx, f, in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't call constructor %s", name)
+	}
+
+	ch := new(codec.CborHandle)
+	err = codec.NewEncoderBytes(&objectState, ch).Encode(resValues[0].Interface())
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't marshal new object data into cbor")
+	}
+	return objectState, nil
 }
 
+// Stop ...
 func (bi *BuiltIn) Stop() error {
 	return nil
 }
 
 // CallMethod runs a method on contract
 func (bi *BuiltIn) CallMethod(ctx *core.LogicCallContext, codeRef core.RecordRef, data []byte, method string, args core.Arguments) (newObjectState []byte, methodResults core.Arguments, err error) {
-	am := bi.AM
-	codeDescriptor, err := am.GetCode(codeRef)
+	c, err := bi.contract(codeRef)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "Can't find code")
-	}
-	code, err := codeDescriptor.Code()
-	c, ok := bi.Registry[string(code)]
-	if !ok {
-		return nil, nil, errors.New("Wrong reference for builtin contract")
+		return nil, nil, err
 	}
 
-	zv := reflect.New(reflect.TypeOf(c).Elem()).Interface()
+	zv := reflect.New(c.typ.Elem()).Interface()
 	ch := new(codec.CborHandle)
 
 	err = codec.NewDecoderBytes(data, ch).Decode(zv)
@@ -84,26 +173,18 @@ func (bi *BuiltIn) CallMethod(ctx *core.LogicCallContext, codeRef core.RecordRef
 		return nil, nil, errors.New("no method " + method + " in the contract")
 	}
 
-	inLen := m.Type().NumIn()
-
-	mask := make([]interface{}, inLen)
-	for i := 0; i < inLen; i++ {
-		argType := m.Type().In(i)
-		mask[i] = reflect.Zero(argType).Interface()
-	}
-
-	err = codec.NewDecoderBytes(args, ch).Decode(&mask)
+	in, err := decodeArguments(m.Type(), args)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "couldn't unmarshal CBOR for arguments of the method")
+		return nil, nil, err
 	}
 
-	in := make([]reflect.Value, inLen)
-	for i := 0; i < inLen; i++ {
-		in[i] = reflect.ValueOf(mask[i])
+	start := time.Now()
+	resValues, err := call(ctx, m, in)
+	ctx.Usage.Time = time.Since(start)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Method call returned error")
 	}
 
-	resValues := m.Call(in)
-
 	err = codec.NewEncoderBytes(&newObjectState, ch).Encode(zv)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "couldn't marshal new object data into cbor")
@@ -112,6 +193,13 @@ func (bi *BuiltIn) CallMethod(ctx *core.LogicCallContext, codeRef core.RecordRef
 	res := make([]interface{}, len(resValues))
 	for i, v := range resValues {
 		res[i] = v.Interface()
+		if e, ok := res[i].(error); ok {
+			res[i] = makeErrorSerializable(e)
+		}
+	}
+	// like goplugin wrappers do, changes of the method returned error are discarded
+	if n := len(res); n > 0 && m.Type().Out(n-1) == errorType && res[n-1] != nil {
+		ctx.Aborted = true
 	}
 
 	var resSerialized []byte
@@ -124,3 +212,43 @@ func (bi *BuiltIn) CallMethod(ctx *core.LogicCallContext, codeRef core.RecordRef
 
 	return newObjectState, methodResults, nil
 }
+
+// decodeArguments unmarshals CBOR encoded arguments into values of parameter types of the function
+func decodeArguments(f reflect.Type, args core.Arguments) ([]reflect.Value, error) {
+	inLen := f.NumIn()
+	ptrs := make([]interface{}, inLen)
+	for i := 0; i < inLen; i++ {
+		ptrs[i] = reflect.New(f.In(i)).Interface()
+	}
+
+	ch := new(codec.CborHandle)
+	err := codec.NewDecoderBytes(args, ch).Decode(&ptrs)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't unmarshal CBOR for arguments of the method")
+	}
+
+	in := make([]reflect.Value, inLen)
+	for i := 0; i < inLen; i++ {
+		in[i] = reflect.ValueOf(ptrs[i]).Elem()
+	}
+	return in, nil
+}
+
+// call runs the function in context of the call, contracts get the context through foundation
+func call(ctx *core.LogicCallContext, f reflect.Value, in []reflect.Value) (res []reflect.Value, err error) {
+	// nested calls of builtin contracts are executed in the same goroutine
+	prev := gls.Get("ctx")
+	gls.Set("ctx", ctx)
+	defer func() {
+		if prev != nil {
+			gls.Set("ctx", prev)
+		} else {
+			gls.Cleanup()
+		}
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	return f.Call(in), nil
+}
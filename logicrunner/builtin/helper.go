@@ -0,0 +1,191 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package builtin
+
+import (
+	"reflect"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
+	"github.com/pkg/errors"
+	"github.com/tylerb/gls"
+	"github.com/ugorji/go/codec"
+)
+
+// Upstream is a set of logic runner services for contracts, goplugin runners use the same services through RPC
+type Upstream interface {
+	RouteCall(req rpctypes.UpRouteReq, rep *rpctypes.UpRouteResp) error
+	SaveAsChild(req rpctypes.UpSaveAsChildReq, rep *rpctypes.UpSaveAsChildResp) error
+	GetObjChildren(req rpctypes.UpGetObjChildrenReq, rep *rpctypes.UpGetObjChildrenResp) error
+	SaveAsDelegate(req rpctypes.UpSaveAsDelegateReq, rep *rpctypes.UpSaveAsDelegateResp) error
+	GetDelegate(req rpctypes.UpGetDelegateReq, rep *rpctypes.UpGetDelegateResp) error
+	DeactivateObject(req rpctypes.UpDeactivateObjectReq, rep *rpctypes.UpDeactivateObjectResp) error
+	Emit(req rpctypes.UpEmitReq, rep *rpctypes.UpEmitResp) error
+}
+
+// helper gives proxies of builtin contracts access to logic runner
+type helper struct {
+	upstream Upstream
+}
+
+// upBaseReq makes base of request from the context of the current call
+func upBaseReq() rpctypes.UpBaseReq {
+	ctx, ok := gls.Get("ctx").(*core.LogicCallContext)
+	if !ok {
+		panic("Wrong or unexistent context")
+	}
+	req := rpctypes.UpBaseReq{
+		Depth:     ctx.Depth,
+		Immutable: ctx.Immutable,
+		Trace:     ctx.Trace,
+	}
+	if ctx.Callee != nil {
+		req.Me = *ctx.Callee
+	}
+	return req
+}
+
+// RouteCall ...
+func (h *helper) RouteCall(ref core.RecordRef, wait bool, immutable bool, method string, args []byte) ([]byte, error) {
+	req := rpctypes.UpRouteReq{
+		UpBaseReq:       upBaseReq(),
+		Wait:            wait,
+		ImmutableMethod: immutable,
+		Object:          ref,
+		Method:          method,
+		Arguments:       args,
+	}
+	res := rpctypes.UpRouteResp{}
+	err := h.upstream.RouteCall(req, &res)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't route call")
+	}
+	return []byte(res.Result), nil
+}
+
+// SaveAsChild ...
+func (h *helper) SaveAsChild(parentRef, classRef core.RecordRef, constructorName string, argsSerialized []byte) (core.RecordRef, error) {
+	req := rpctypes.UpSaveAsChildReq{
+		UpBaseReq:       upBaseReq(),
+		Parent:          parentRef,
+		Class:           classRef,
+		ConstructorName: constructorName,
+		ArgsSerialized:  argsSerialized,
+	}
+	res := rpctypes.UpSaveAsChildResp{}
+	err := h.upstream.SaveAsChild(req, &res)
+	if err != nil {
+		return core.RecordRef{}, errors.Wrap(err, "couldn't save as child")
+	}
+	return *res.Reference, nil
+}
+
+// GetObjChildren ...
+func (h *helper) GetObjChildren(obj core.RecordRef, class core.RecordRef) ([]core.RecordRef, error) {
+	req := rpctypes.UpGetObjChildrenReq{
+		UpBaseReq: upBaseReq(),
+		Obj:       obj,
+		Class:     class,
+	}
+	res := rpctypes.UpGetObjChildrenResp{}
+	err := h.upstream.GetObjChildren(req, &res)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get children")
+	}
+	return res.Children, nil
+}
+
+// SaveAsDelegate ...
+func (h *helper) SaveAsDelegate(intoRef, classRef core.RecordRef, constructorName string, argsSerialized []byte) (core.RecordRef, error) {
+	req := rpctypes.UpSaveAsDelegateReq{
+		UpBaseReq:       upBaseReq(),
+		Into:            intoRef,
+		Class:           classRef,
+		ConstructorName: constructorName,
+		ArgsSerialized:  argsSerialized,
+	}
+	res := rpctypes.UpSaveAsDelegateResp{}
+	err := h.upstream.SaveAsDelegate(req, &res)
+	if err != nil {
+		return core.RecordRef{}, errors.Wrap(err, "couldn't save as delegate")
+	}
+	return *res.Reference, nil
+}
+
+// GetDelegate ...
+func (h *helper) GetDelegate(object, ofType core.RecordRef) (core.RecordRef, error) {
+	req := rpctypes.UpGetDelegateReq{
+		UpBaseReq: upBaseReq(),
+		Object:    object,
+		OfType:    ofType,
+	}
+	res := rpctypes.UpGetDelegateResp{}
+	err := h.upstream.GetDelegate(req, &res)
+	if err != nil {
+		return core.RecordRef{}, errors.Wrap(err, "couldn't get delegate")
+	}
+	return res.Object, nil
+}
+
+// DeactivateObject ...
+func (h *helper) DeactivateObject(object core.RecordRef) error {
+	req := rpctypes.UpDeactivateObjectReq{
+		UpBaseReq: upBaseReq(),
+		Object:    object,
+	}
+	res := rpctypes.UpDeactivateObjectResp{}
+	return errors.Wrap(h.upstream.DeactivateObject(req, &res), "couldn't deactivate object")
+}
+
+// Emit publishes event of the current contract
+func (h *helper) Emit(name string, payload []byte) error {
+	req := rpctypes.UpEmitReq{
+		UpBaseReq: upBaseReq(),
+		Name:      name,
+		Payload:   payload,
+	}
+	res := rpctypes.UpEmitResp{}
+	return errors.Wrap(h.upstream.Emit(req, &res), "couldn't emit event")
+}
+
+// Serialize - CBOR serializer wrapper: `what` -> `to`
+func (h *helper) Serialize(what interface{}, to *[]byte) error {
+	ch := new(codec.CborHandle)
+	return codec.NewEncoderBytes(to, ch).Encode(what)
+}
+
+// Deserialize - CBOR de-serializer wrapper: `from` -> `into`
+func (h *helper) Deserialize(from []byte, into interface{}) error {
+	ch := new(codec.CborHandle)
+	return codec.NewDecoderBytes(from, ch).Decode(into)
+}
+
+// MakeErrorSerializable converts errors satisfying error interface to foundation.Error
+func (h *helper) MakeErrorSerializable(e error) error {
+	return makeErrorSerializable(e)
+}
+
+func makeErrorSerializable(e error) error {
+	if e == nil || e == (*foundation.Error)(nil) {
+		return nil
+	}
+	if v := reflect.ValueOf(e); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	return &foundation.Error{S: e.Error()}
+}
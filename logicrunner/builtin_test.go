@@ -20,13 +20,19 @@ import (
 	"testing"
 
 	"github.com/insolar/insolar/pulsar/entropygenerator"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/logicrunner/builtin"
 	"github.com/insolar/insolar/logicrunner/builtin/helloworld"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+	"github.com/insolar/insolar/logicrunner/goplugin/proxyctx"
+	"github.com/insolar/insolar/messagebus/messagebustest"
 
 	"github.com/insolar/insolar/ledger/ledgertestutils"
 	"github.com/insolar/insolar/logicrunner/goplugin/goplugintestutils"
@@ -55,7 +61,8 @@ func TestBareHelloworld(t *testing.T) {
 		Ledger:     l,
 		MessageBus: eb,
 	}), "starting logicrunner")
-	lr.OnPulse(*pulsar.NewPulse(configuration.NewPulsar().NumberDelta, 0, &entropygenerator.StandardEntropyGenerator{}))
+	err = l.GetPulseManager().Set(*pulsar.NewPulse(configuration.NewPulsar().NumberDelta, 0, &entropygenerator.StandardEntropyGenerator{}))
+	assert.NoError(t, err)
 
 	hw := helloworld.NewHelloWorld()
 
@@ -97,3 +104,177 @@ func TestBareHelloworld(t *testing.T) {
 	assert.Equal(t, []interface{}([]interface{}{"Hello Ruz's world"}), r)
 	assert.Equal(t, map[interface{}]interface{}(map[interface{}]interface{}{"Greeted": uint64(2)}), d)
 }
+
+func TestHarnessHelloworld(t *testing.T) {
+	newLogicRunner := func(core.RecordRef) core.LogicRunner {
+		lr, err := NewLogicRunner(&configuration.LogicRunner{
+			BuiltIn: &configuration.BuiltIn{},
+		})
+		require.NoError(t, err)
+		return lr
+	}
+	h := messagebustest.NewHarness(t, 42, map[core.JetRole]messagebustest.Role{
+		core.RoleVirtualExecutor:  {Candidates: 1, Actors: 1, LogicRunner: newLogicRunner},
+		core.RoleVirtualValidator: {Candidates: 2, Actors: 2, LogicRunner: newLogicRunner},
+		core.RoleLightExecutor:    {Candidates: 1, Actors: 1},
+		core.RoleLightValidator:   {Candidates: 1, Actors: 1},
+	})
+	defer h.Stop()
+	executor := h.Role(core.RoleVirtualExecutor)[0]
+	caller := h.Role(core.RoleLightExecutor)[0]
+
+	am := executor.Ledger.GetArtifactManager()
+	domain := byteRecorRef(2)
+	request := byteRecorRef(3)
+	_, _, classRef, err := goplugintestutils.AMPublishCode(t, am, domain, request, core.MachineTypeBuiltin, []byte("helloworld"))
+	require.NoError(t, err)
+	contract, err := am.RegisterRequest(&message.CallConstructor{ClassRef: byteRecorRef(4)})
+	require.NoError(t, err)
+	_, err = am.ActivateObject(domain, *contract, *classRef, *am.GenesisRef(), goplugintestutils.CBORMarshal(t, helloworld.NewHelloWorld()))
+	require.NoError(t, err)
+
+	resp, err := caller.Bus.Send(&message.CallMethod{
+		ObjectRef: *contract,
+		Method:    "Greet",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{"Vany"}),
+	})
+	require.NoError(t, err)
+	r := goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, []interface{}{"Hello Vany's world"}, r)
+
+	_, err = h.NextPulse()
+	require.NoError(t, err)
+	lr := executor.LogicRunner.(*LogicRunner)
+	lr.validationsMutex.Lock()
+	defer lr.validationsMutex.Unlock()
+	require.Contains(t, lr.validations, *contract)
+	assert.True(t, lr.validations[*contract].done)
+	assert.True(t, lr.validations[*contract].confirmed)
+}
+
+type builtinChild struct {
+	foundation.BaseContract
+	N int
+}
+
+func newBuiltinChild(n int) *builtinChild {
+	return &builtinChild{N: n}
+}
+
+func (c *builtinChild) Get() int {
+	return c.N
+}
+
+type builtinParent struct {
+	foundation.BaseContract
+	Children []core.RecordRef
+	Sum      int
+}
+
+func newBuiltinParent(sum int) *builtinParent {
+	return &builtinParent{Sum: sum}
+}
+
+func (p *builtinParent) AddChild(class core.RecordRef, n int) (int, error) {
+	var args []byte
+	err := proxyctx.Current.Serialize([]interface{}{n}, &args)
+	if err != nil {
+		return 0, err
+	}
+	child, err := proxyctx.Current.SaveAsChild(p.GetReference(), class, "New", args)
+	if err != nil {
+		return 0, err
+	}
+	p.Children = append(p.Children, child)
+
+	err = proxyctx.Current.Serialize([]interface{}{}, &args)
+	if err != nil {
+		return 0, err
+	}
+	res, err := proxyctx.Current.RouteCall(child, true, true, "Get", args)
+	if err != nil {
+		return 0, err
+	}
+	var ret [1]int
+	err = proxyctx.Current.Deserialize(res, &ret)
+	if err != nil {
+		return 0, err
+	}
+	p.Sum += ret[0]
+	return p.Sum, nil
+}
+
+func (p *builtinParent) Reset() error {
+	p.Sum = 0
+	return errors.New("reset is not allowed")
+}
+
+func TestBuiltinContracts(t *testing.T) {
+	lr, err := NewLogicRunner(&configuration.LogicRunner{
+		BuiltIn: &configuration.BuiltIn{},
+	})
+	assert.NoError(t, err, "Initialize runner")
+
+	l, cleaner := ledgertestutils.TmpLedger(t, lr, "")
+	defer cleaner()
+	am := l.GetArtifactManager()
+
+	assert.NoError(t, lr.Start(core.Components{
+		Ledger:     l,
+		MessageBus: &testMessageBus{lr},
+	}), "starting logicrunner")
+	err = l.GetPulseManager().Set(*pulsar.NewPulse(configuration.NewPulsar().NumberDelta, 0, &entropygenerator.StandardEntropyGenerator{}))
+	assert.NoError(t, err)
+
+	executor, err := lr.GetExecutor(core.MachineTypeBuiltin)
+	assert.NoError(t, err)
+	bi := executor.(*builtin.BuiltIn)
+	err = bi.Register("parent", &builtinParent{}, map[string]interface{}{"New": newBuiltinParent})
+	assert.NoError(t, err)
+	err = bi.Register("child", &builtinChild{}, map[string]interface{}{"New": newBuiltinChild})
+	assert.NoError(t, err)
+	err = bi.Register("child", &builtinChild{}, nil)
+	assert.Error(t, err, "contract is registered twice")
+	err = bi.Register("wrong", &builtinChild{}, map[string]interface{}{"New": newBuiltinParent})
+	assert.Error(t, err, "constructor returns other contract")
+
+	domain := byteRecorRef(2)
+	_, _, parentClass, err := goplugintestutils.AMPublishCode(t, am, domain, byteRecorRef(3), core.MachineTypeBuiltin, []byte("parent"))
+	assert.NoError(t, err)
+	_, _, childClass, err := goplugintestutils.AMPublishCode(t, am, domain, byteRecorRef(4), core.MachineTypeBuiltin, []byte("child"))
+	assert.NoError(t, err)
+
+	resp, err := lr.Execute(&message.CallConstructor{
+		ClassRef:  *parentClass,
+		ParentRef: *am.GenesisRef(),
+		Name:      "New",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{10}),
+		SaveAs:    message.Child,
+	})
+	assert.NoError(t, err, "constructor call")
+	parent := *resp.(*reply.CallConstructor).Object
+
+	resp, err = lr.Execute(&message.CallMethod{
+		ObjectRef: parent,
+		Method:    "AddChild",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{*childClass, 5}),
+	})
+	assert.NoError(t, err, "contract call")
+	r := goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, []interface{}{uint64(15), nil}, r)
+
+	resp, err = lr.Execute(&message.CallMethod{
+		ObjectRef: parent,
+		Method:    "Reset",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{}),
+	})
+	assert.NoError(t, err, "contract call")
+	r = goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, []interface{}{map[interface{}]interface{}{"S": "reset is not allowed"}}, r)
+
+	objDesc, err := am.GetObject(parent, nil)
+	assert.NoError(t, err)
+	d := goplugintestutils.CBORUnMarshal(t, objDesc.Memory()).(map[interface{}]interface{})
+	assert.Equal(t, uint64(15), d["Sum"], "failed method shouldn't change the object")
+	assert.Len(t, d["Children"], 1)
+}
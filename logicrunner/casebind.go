@@ -20,8 +20,11 @@ package logicrunner
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
 	"github.com/pkg/errors"
 	"github.com/ugorji/go/codec"
@@ -39,17 +42,107 @@ func HashInterface(in interface{}) []byte {
 	return sh.Sum(s)
 }
 
-func (lr *LogicRunner) addObjectCaseRecord(ref core.RecordRef, cr core.CaseRecord) {
+// encodeCaseRecords serializes responses of case records for sending, responses decoded into interface would lose
+// their types otherwise.
+func encodeCaseRecords(records []core.CaseRecord) ([]core.CaseRecord, error) {
+	encoded := make([]core.CaseRecord, len(records))
+	for i, cr := range records {
+		encoded[i] = core.CaseRecord{Type: cr.Type, ReqSig: cr.ReqSig}
+		if cr.Resp == nil {
+			continue
+		}
+		var data []byte
+		var err error
+		switch cr.Type {
+		case core.CaseRecordTypeStart:
+			data, err = message.ToBytes(cr.Resp.(core.Message))
+		case core.CaseRecordTypeResult:
+			var buff io.Reader
+			buff, err = reply.Serialize(cr.Resp.(core.Reply))
+			if err == nil {
+				data, err = ioutil.ReadAll(buff)
+			}
+		default:
+			err = codec.NewEncoderBytes(&data, new(codec.CborHandle)).Encode(cr.Resp)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't encode case record #%d", i)
+		}
+		encoded[i].Resp = data
+	}
+	return encoded, nil
+}
+
+// decodeCaseRecords restores responses of case records serialized by encodeCaseRecords.
+func decodeCaseRecords(records []core.CaseRecord) ([]core.CaseRecord, error) {
+	decoded := make([]core.CaseRecord, len(records))
+	for i, cr := range records {
+		decoded[i] = core.CaseRecord{Type: cr.Type, ReqSig: cr.ReqSig}
+		if cr.Resp == nil {
+			continue
+		}
+		data, ok := cr.Resp.([]byte)
+		if !ok {
+			return nil, errors.Errorf("case record #%d is not encoded", i)
+		}
+		var err error
+		switch cr.Type {
+		case core.CaseRecordTypeStart:
+			decoded[i].Resp, err = message.Deserialize(bytes.NewBuffer(data))
+		case core.CaseRecordTypeResult:
+			decoded[i].Resp, err = reply.Deserialize(bytes.NewBuffer(data))
+		case core.CaseRecordTypeGetObject:
+			ob := &objectBody{}
+			err = decodeCaseRecordResp(data, ob)
+			decoded[i].Resp = ob
+		case core.CaseRecordTypeRouteCall:
+			var result core.Arguments
+			err = decodeCaseRecordResp(data, &result)
+			decoded[i].Resp = result
+		case core.CaseRecordTypeSaveAsChild, core.CaseRecordTypeSaveAsDelegate:
+			ref := &core.RecordRef{}
+			err = decodeCaseRecordResp(data, ref)
+			decoded[i].Resp = ref
+		case core.CaseRecordTypeGetObjChildren:
+			var children []core.RecordRef
+			err = decodeCaseRecordResp(data, &children)
+			decoded[i].Resp = children
+		case core.CaseRecordTypeGetDelegate:
+			var ref core.RecordRef
+			err = decodeCaseRecordResp(data, &ref)
+			decoded[i].Resp = ref
+		default:
+			err = errors.Errorf("unexpected response of case record type %d", cr.Type)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't decode case record #%d", i)
+		}
+	}
+	return decoded, nil
+}
+
+func decodeCaseRecordResp(data []byte, to interface{}) error {
+	return codec.NewDecoderBytes(data, new(codec.CborHandle)).Decode(to)
+}
+
+// addCallCaseRecord adds case record of the call, records of the call are added to case bind of the object together
+// when the call is completed, so records of concurrent calls of the object don't interleave
+func (lr *LogicRunner) addCallCaseRecord(call callKey, cr core.CaseRecord) {
 	lr.caseBindMutex.Lock()
-	lr.caseBind.Records[ref] = append(lr.caseBind.Records[ref], cr)
+	lr.callRecords[call] = append(lr.callRecords[call], cr)
 	lr.caseBindMutex.Unlock()
 }
 
-func (lr *LogicRunner) lastObjectCaseRecord(ref core.RecordRef) core.CaseRecord {
+// flushCallCaseRecords adds case records of the completed call to case bind of the object
+func (lr *LogicRunner) flushCallCaseRecords(call callKey) {
 	lr.caseBindMutex.Lock()
 	defer lr.caseBindMutex.Unlock()
-	list := lr.caseBind.Records[ref]
-	return list[len(list)-1]
+	records, ok := lr.callRecords[call]
+	if !ok {
+		return
+	}
+	lr.caseBind.Records[call.object] = append(lr.caseBind.Records[call.object], records...)
+	delete(lr.callRecords, call)
 }
 
 func (lr *LogicRunner) getNextValidationStep(ref core.RecordRef) (*core.CaseRecord, int) {
@@ -141,8 +234,8 @@ func (lr *LogicRunner) Validate(ref core.RecordRef, p core.Pulse, cr []core.Case
 
 // ValidationBehaviour is a special object that responsible for validation behavior of other methods.
 type ValidationBehaviour interface {
-	Begin(refs core.RecordRef, record core.CaseRecord)
-	End(refs core.RecordRef, record core.CaseRecord)
+	Begin(call callKey, record core.CaseRecord)
+	End(call callKey, record core.CaseRecord)
 	ModifyContext(ctx *core.LogicCallContext)
 	NeedSave() bool
 }
@@ -159,12 +252,12 @@ func (vb ValidationSaver) ModifyContext(ctx *core.LogicCallContext) {
 	// nothing need
 }
 
-func (vb ValidationSaver) Begin(refs core.RecordRef, record core.CaseRecord) {
-	vb.lr.addObjectCaseRecord(refs, record)
+func (vb ValidationSaver) Begin(call callKey, record core.CaseRecord) {
+	vb.lr.addCallCaseRecord(call, record)
 }
 
-func (vb ValidationSaver) End(refs core.RecordRef, record core.CaseRecord) {
-	vb.lr.addObjectCaseRecord(refs, record)
+func (vb ValidationSaver) End(call callKey, record core.CaseRecord) {
+	vb.lr.addCallCaseRecord(call, record)
 }
 
 type ValidationChecker struct {
@@ -180,10 +273,10 @@ func (vb ValidationChecker) ModifyContext(ctx *core.LogicCallContext) {
 	ctx.Pulse = vb.cb.Pulse
 }
 
-func (vb ValidationChecker) Begin(refs core.RecordRef, record core.CaseRecord) {
+func (vb ValidationChecker) Begin(call callKey, record core.CaseRecord) {
 	// do nothing, everything done in lr.Validate
 }
 
-func (vb ValidationChecker) End(refs core.RecordRef, record core.CaseRecord) {
+func (vb ValidationChecker) End(call callKey, record core.CaseRecord) {
 	// do nothing, everything done in lr.Validate
 }
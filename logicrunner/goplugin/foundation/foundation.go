@@ -94,6 +94,19 @@ func (bc *BaseContract) SelfDestruct() {
 	}
 }
 
+// Emit publishes event of the contract, events are stored in ledger when the call is completed
+func (bc *BaseContract) Emit(name string, payload interface{}) {
+	var data []byte
+	err := proxyctx.Current.Serialize(payload, &data)
+	if err != nil {
+		panic(err)
+	}
+	err = proxyctx.Current.Emit(name, data)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Error elementary string based error struct satisfying builtin error interface
 //    foundation.Error{"some err"}
 type Error struct {
@@ -0,0 +1,111 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package ginsider
+
+import (
+	"container/list"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"github.com/insolar/insolar/log"
+)
+
+// codeCache is an LRU cache of plugins stored on disk, files of evicted plugins are removed
+type codeCache struct {
+	dir     string
+	limit   int64 // total size of cached files, zero disables the limit
+	size    int64
+	order   *list.List // of *cacheEntry, most recently used are in front
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key    string
+	size   int64
+	plugin *plugin.Plugin // nil if plugin isn't opened yet
+}
+
+// newCodeCache creates cache of the directory, files already stored in it are added in order of modification
+func newCodeCache(dir string, limit int64) (*codeCache, error) {
+	c := &codeCache{
+		dir:     dir,
+		limit:   limit,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, f := range files {
+		if f.Mode().IsRegular() {
+			c.add(f.Name(), f.Size())
+		}
+	}
+	return c, nil
+}
+
+func (c *codeCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+// get returns cached entry and marks it as recently used
+func (c *codeCache) get(key string) *cacheEntry {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry)
+}
+
+// add puts a stored file into the cache and evicts least recently used files if cache is too big
+func (c *codeCache) add(key string, size int64) *cacheEntry {
+	c.remove(key)
+	e := &cacheEntry{key: key, size: size}
+	c.entries[key] = c.order.PushFront(e)
+	c.size += size
+
+	for c.limit > 0 && c.size > c.limit && c.order.Len() > 1 {
+		oldest := c.order.Back().Value.(*cacheEntry)
+		log.Debugf("evicting plugin %q from cache", oldest.key)
+		c.remove(oldest.key)
+		err := os.Remove(c.path(oldest.key))
+		if err != nil && !os.IsNotExist(err) {
+			log.Errorf("couldn't remove cached plugin %q: %s", oldest.key, err)
+		}
+	}
+	return e
+}
+
+// remove drops the entry from cache, file is left on disk
+func (c *codeCache) remove(key string) {
+	el, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.size -= el.Value.(*cacheEntry).size
+	c.order.Remove(el)
+	delete(c.entries, key)
+}
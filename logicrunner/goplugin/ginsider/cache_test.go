@@ -0,0 +1,119 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package ginsider
+
+import (
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
+	"github.com/insolar/insolar/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// testUpstream serves code of contracts to GoInsider
+type testUpstream struct {
+	codes   map[core.RecordRef][]byte
+	fetched int
+}
+
+func (u *testUpstream) GetCode(req rpctypes.UpGetCodeReq, reply *rpctypes.UpGetCodeResp) error {
+	u.fetched++
+	reply.Code = u.codes[req.Code]
+	return nil
+}
+
+func (u *testUpstream) GetCodeHash(req rpctypes.UpGetCodeHashReq, reply *rpctypes.UpGetCodeHashResp) error {
+	hash := sha3.Sum224(u.codes[req.Code])
+	reply.Hash = hash[:]
+	return nil
+}
+
+func startTestUpstream(t *testing.T, u *testUpstream) (string, func()) {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("RPC", u))
+	address := os.TempDir() + "/" + testutils.RandomString() + ".sock"
+	l, err := net.Listen("unix", address)
+	require.NoError(t, err)
+	go server.Accept(l)
+	return address, func() { l.Close() }
+}
+
+func TestCodeCache_Evicts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractcache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// files stored before start are cached in order of modification
+	for i, name := range []string{"old", "new"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte("code"), 0666))
+		mtime := time.Now().Add(time.Duration(i-2) * time.Hour)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	c, err := newCodeCache(dir, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), c.size)
+
+	c.add("third", 4)
+	assert.Nil(t, c.get("old"))
+	_, err = os.Stat(filepath.Join(dir, "old"))
+	assert.True(t, os.IsNotExist(err))
+
+	// recently used entry is kept
+	assert.NotNil(t, c.get("new"))
+	c.add("fourth", 4)
+	assert.NotNil(t, c.get("new"))
+	assert.Nil(t, c.get("third"))
+	assert.Equal(t, int64(8), c.size)
+}
+
+func TestGoInsider_ObtainCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contractcache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ref := testutils.RandomRef()
+	u := &testUpstream{codes: map[core.RecordRef][]byte{ref: []byte("plugin code")}}
+	address, stop := startTestUpstream(t, u)
+	defer stop()
+	gi, err := NewGoInsider(dir, "unix", address, 0)
+	require.NoError(t, err)
+
+	path, err := gi.ObtainCode(ref)
+	require.NoError(t, err)
+	_, err = gi.ObtainCode(ref)
+	require.NoError(t, err)
+	assert.Equal(t, 1, u.fetched)
+
+	// tampered file is fetched again
+	require.NoError(t, ioutil.WriteFile(path, []byte("evil code"), 0666))
+	_, err = gi.ObtainCode(ref)
+	require.NoError(t, err)
+	assert.Equal(t, 2, u.fetched)
+	code, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plugin code"), code)
+}
@@ -17,17 +17,20 @@
 package ginsider
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/rpc"
 	"os"
-	"path/filepath"
 	"plugin"
 	"reflect"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ugorji/go/codec"
+	"golang.org/x/crypto/sha3"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/log"
@@ -36,22 +39,36 @@ import (
 	"github.com/tylerb/gls"
 )
 
+const (
+	// limitCheckInterval is how often limits of a call are checked while its code is executed
+	limitCheckInterval = 10 * time.Millisecond
+	// abortDelay is time given to the runner to reply to the call exceeding its limits before the runner exits
+	abortDelay = 100 * time.Millisecond
+)
+
 // GoInsider is an RPC interface to run code of plugins
 type GoInsider struct {
-	dir              string
 	UpstreamProtocol string
 	UpstreamAddress  string
 	UpstreamClient   *rpc.Client
-	plugins          map[string]*plugin.Plugin
+	plugins          *codeCache
 	pluginsMutex     sync.Mutex
+
+	// execMutex is held by the call executing contract code, so allocations of the runner are made by the call
+	execMutex sync.Mutex
+	exit      func(code int)
 }
 
-// NewGoInsider creates a new GoInsider instance validating arguments
-func NewGoInsider(path, network, address string) *GoInsider {
+// NewGoInsider creates a new GoInsider instance validating arguments, cacheSize limits total size
+// of plugins stored in the path, zero disables the limit
+func NewGoInsider(path, network, address string, cacheSize int64) (*GoInsider, error) {
 	//TODO: check that path exist, it's a directory and writable
-	res := GoInsider{dir: path, UpstreamProtocol: network, UpstreamAddress: address}
-	res.plugins = make(map[string]*plugin.Plugin)
-	return &res
+	plugins, err := newCodeCache(path, cacheSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't read code cache")
+	}
+	res := GoInsider{UpstreamProtocol: network, UpstreamAddress: address, plugins: plugins, exit: os.Exit}
+	return &res, nil
 }
 
 // RPC struct with methods representing RPC interface of this code runner
@@ -96,15 +113,157 @@ func (t *RPC) CallMethod(args rpctypes.DownCallMethodReq, reply *rpctypes.DownCa
 		return errors.New("Wrapper with wrong signature")
 	}
 
-	gls.Set("ctx", args.Context)
-	state, result, err := wrapper(args.Data, args.Arguments) // may be entire args???
-	gls.Cleanup()
-
+	var state, result []byte
+	reply.Usage, err = t.GI.execute(args.Context, func() error {
+		var err error
+		state, result, err = wrapper(args.Data, args.Arguments) // may be entire args???
+		return err
+	})
 	if err != nil {
 		return errors.Wrapf(err, "Method call returned error")
 	}
 	reply.Data = state
 	reply.Ret = result
+	reply.Aborted = state == nil // wrapper discards state of failed method
+	return nil
+}
+
+// execute runs contract code of the call and checks limits of the call while the code is executed. Code can't be
+// interrupted, so the runner exits if the call exceeds its limits. Spawned runner is restarted by the node then.
+func (gi *GoInsider) execute(ctx *core.LogicCallContext, f func() error) (core.CallUsage, error) {
+	var limits core.CallLimits
+	if ctx != nil {
+		limits = ctx.Limits
+	}
+	meter := newCallMeter(&gi.execMutex)
+	done := make(chan error, 1)
+	go func() {
+		if ctx != nil {
+			gls.Set("ctx", ctx)
+		}
+		gls.Set("meter", meter)
+		defer gls.Cleanup()
+		meter.resume()
+		var err error
+		defer func() {
+			meter.pause()
+			done <- err
+		}()
+		defer recoverRPC(&err)
+		err = f()
+	}()
+
+	var check <-chan time.Time
+	if limits.Time > 0 || limits.Memory > 0 {
+		ticker := time.NewTicker(limitCheckInterval)
+		defer ticker.Stop()
+		check = ticker.C
+	}
+	for {
+		select {
+		case err := <-done:
+			usage := meter.Usage()
+			if err != nil {
+				return usage, err
+			}
+			return usage, checkLimits(limits, usage)
+		case <-check:
+			usage := meter.Usage()
+			if err := checkLimits(limits, usage); err != nil {
+				gi.abort(err)
+				return usage, err
+			}
+		}
+	}
+}
+
+// abort makes the runner exit shortly after it replies to the call which can't be stopped
+func (gi *GoInsider) abort(reason error) {
+	log.Errorf("runner exits as executed call can't be stopped: %s", reason)
+	time.AfterFunc(abortDelay, func() {
+		gi.exit(1)
+	})
+}
+
+// callMeter measures resources used by a call. The meter holds execution lock of the runner while the call executes
+// its code and releases it for nested calls, so allocations of the runner meanwhile are allocations of the call.
+type callMeter struct {
+	exec *sync.Mutex
+
+	mu      sync.Mutex // guards fields below, usage is checked while the call is executed
+	usage   core.CallUsage
+	running bool
+	start   time.Time // start of executed part of the call
+	alloc   uint64    // bytes allocated by the runner at the start of executed part
+}
+
+func newCallMeter(exec *sync.Mutex) *callMeter {
+	return &callMeter{exec: exec}
+}
+
+// resume continues execution of the call code when other calls leave their code
+func (m *callMeter) resume() {
+	m.exec.Lock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = true
+	m.start = time.Now()
+	m.alloc = totalAlloc()
+}
+
+// pause stops accounting of the call and lets other calls execute their code
+func (m *callMeter) pause() {
+	m.mu.Lock()
+	m.usage = m.current()
+	m.running = false
+	m.mu.Unlock()
+	m.exec.Unlock()
+}
+
+// current returns resources used by the call including executed part, m.mu should be held
+func (m *callMeter) current() core.CallUsage {
+	usage := m.usage
+	if m.running {
+		usage.Time += time.Since(m.start)
+		usage.Memory += totalAlloc() - m.alloc
+	}
+	return usage
+}
+
+// Usage returns resources used by the call, nested calls are not included
+func (m *callMeter) Usage() core.CallUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current()
+}
+
+func totalAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.TotalAlloc
+}
+
+// nestedCall accounts a nested call of the current contract, returned function should be called
+// when the nested call is completed
+func nestedCall() func() {
+	m, ok := gls.Get("meter").(*callMeter)
+	if !ok {
+		return func() {}
+	}
+	m.mu.Lock()
+	m.usage.Calls++
+	m.mu.Unlock()
+	m.pause()
+	return m.resume
+}
+
+func checkLimits(limits core.CallLimits, usage core.CallUsage) error {
+	if limits.Time > 0 && usage.Time > limits.Time {
+		return errors.Errorf("time limit exceeded: %s used, %s allowed", usage.Time, limits.Time)
+	}
+	if limits.Memory > 0 && usage.Memory > limits.Memory {
+		return errors.Errorf("memory limit exceeded: %d bytes allocated, %d allowed", usage.Memory, limits.Memory)
+	}
 	return nil
 }
 
@@ -129,7 +288,12 @@ func (t *RPC) CallConstructor(args rpctypes.DownCallConstructorReq, reply *rpcty
 		return errors.New("Wrapper with wrong signature")
 	}
 
-	resValues, err := f(args.Arguments)
+	var resValues []byte
+	_, err = t.GI.execute(nil, func() error {
+		var err error
+		resValues, err = f(args.Arguments)
+		return err
+	})
 	if err != nil {
 		return errors.Wrapf(err, "Can't call constructor %s", args.Name)
 	}
@@ -139,6 +303,12 @@ func (t *RPC) CallConstructor(args rpctypes.DownCallConstructorReq, reply *rpcty
 	return nil
 }
 
+// Ping is an RPC checking that the runner is alive, it returns nonce of the request
+func (t *RPC) Ping(args rpctypes.DownPingReq, reply *rpctypes.DownPingResp) error {
+	reply.Nonce = args.Nonce
+	return nil
+}
+
 // Upstream returns RPC client connected to upstream server (goplugin)
 func (gi *GoInsider) Upstream() (*rpc.Client, error) {
 	if gi.UpstreamClient != nil {
@@ -155,13 +325,25 @@ func (gi *GoInsider) Upstream() (*rpc.Client, error) {
 }
 
 // ObtainCode returns path on the file system to the plugin, fetches it from a provider
-// if it's not in the storage
+// if it's not in the storage or stored file doesn't match hash of the code
 func (gi *GoInsider) ObtainCode(ref core.RecordRef) (string, error) {
-	path := filepath.Join(gi.dir, ref.String())
-	_, err := os.Stat(path)
+	key := ref.String()
+	path := gi.plugins.path(key)
+	info, err := os.Stat(path)
 
 	if err == nil {
-		return path, nil
+		valid, err := gi.verifyCode(ref, path)
+		if err != nil {
+			return "", errors.Wrap(err, "couldn't verify cached code")
+		}
+		if valid {
+			if gi.plugins.get(key) == nil {
+				gi.plugins.add(key, info.Size())
+			}
+			return path, nil
+		}
+		log.Warnf("cached code %q doesn't match its hash, fetching it again", ref)
+		gi.plugins.remove(key)
 	} else if !os.IsNotExist(err) {
 		return "", errors.Wrap(err, "file !notexists()")
 	}
@@ -182,18 +364,40 @@ func (gi *GoInsider) ObtainCode(ref core.RecordRef) (string, error) {
 	if err != nil {
 		return "", errors.Wrap(err, "on writing file down")
 	}
+	gi.plugins.add(key, int64(len(res.Code)))
 
 	return path, nil
 }
 
+// verifyCode checks that stored file matches hash of the code provided by upstream
+func (gi *GoInsider) verifyCode(ref core.RecordRef, path string) (bool, error) {
+	client, err := gi.Upstream()
+	if err != nil {
+		return false, err
+	}
+	res := rpctypes.UpGetCodeHashResp{}
+	err = client.Call("RPC.GetCodeHash", rpctypes.UpGetCodeHashReq{Code: ref}, &res)
+	if err != nil {
+		return false, errors.Wrap(err, "on calling main API")
+	}
+
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrap(err, "on reading file")
+	}
+	hash := sha3.Sum224(code)
+	return bytes.Equal(hash[:], res.Hash), nil
+}
+
 // Plugin loads Go plugin by reference and returns `*plugin.Plugin`
-// ready to lookup symbols
+// ready to lookup symbols, code is verified every time it's opened. Go can't unload plugins,
+// so eviction from cache frees disk space only
 func (gi *GoInsider) Plugin(ref core.RecordRef) (*plugin.Plugin, error) {
 	gi.pluginsMutex.Lock()
 	defer gi.pluginsMutex.Unlock()
 	key := ref.String()
-	if gi.plugins[key] != nil {
-		return gi.plugins[key], nil
+	if e := gi.plugins.get(key); e != nil && e.plugin != nil {
+		return e.plugin, nil
 	}
 
 	path, err := gi.ObtainCode(ref)
@@ -207,7 +411,7 @@ func (gi *GoInsider) Plugin(ref core.RecordRef) (*plugin.Plugin, error) {
 		return nil, errors.Wrap(err, "couldn't open plugin")
 	}
 
-	gi.plugins[key] = p
+	gi.plugins.get(key).plugin = p
 	return p, nil
 }
 
@@ -215,24 +419,30 @@ func (gi *GoInsider) Plugin(ref core.RecordRef) (*plugin.Plugin, error) {
 func MakeUpBaseReq() rpctypes.UpBaseReq {
 	if ctx, ok := gls.Get("ctx").(*core.LogicCallContext); ok {
 		return rpctypes.UpBaseReq{
-			Me: *ctx.Callee,
+			Me:        *ctx.Callee,
+			Depth:     ctx.Depth,
+			Immutable: ctx.Immutable,
+			Trace:     ctx.Trace,
 		}
 	}
 	panic("Wrong or unexistent context")
 }
 
 // RouteCall ...
-func (gi *GoInsider) RouteCall(ref core.RecordRef, wait bool, method string, args []byte) ([]byte, error) {
+func (gi *GoInsider) RouteCall(ref core.RecordRef, wait bool, immutable bool, method string, args []byte) ([]byte, error) {
+	defer nestedCall()()
+
 	client, err := gi.Upstream()
 	if err != nil {
 		return nil, err
 	}
 	req := rpctypes.UpRouteReq{
-		UpBaseReq: MakeUpBaseReq(),
-		Wait:      wait,
-		Object:    ref,
-		Method:    method,
-		Arguments: args,
+		UpBaseReq:       MakeUpBaseReq(),
+		Wait:            wait,
+		ImmutableMethod: immutable,
+		Object:          ref,
+		Method:          method,
+		Arguments:       args,
 	}
 
 	res := rpctypes.UpRouteResp{}
@@ -246,6 +456,8 @@ func (gi *GoInsider) RouteCall(ref core.RecordRef, wait bool, method string, arg
 
 // SaveAsChild ...
 func (gi *GoInsider) SaveAsChild(parentRef, classRef core.RecordRef, constructorName string, argsSerialized []byte) (core.RecordRef, error) {
+	defer nestedCall()()
+
 	client, err := gi.Upstream()
 	if err != nil {
 		return core.NewRefFromBase58(""), err
@@ -291,6 +503,8 @@ func (gi *GoInsider) GetObjChildren(obj core.RecordRef, class core.RecordRef) ([
 
 // SaveAsDelegate ...
 func (gi *GoInsider) SaveAsDelegate(intoRef, classRef core.RecordRef, constructorName string, argsSerialized []byte) (core.RecordRef, error) {
+	defer nestedCall()()
+
 	client, err := gi.Upstream()
 	if err != nil {
 		return core.NewRefFromBase58(""), err
@@ -356,6 +570,28 @@ func (gi *GoInsider) DeactivateObject(object core.RecordRef) error {
 	return nil
 }
 
+// Emit publishes event of the current contract
+func (gi *GoInsider) Emit(name string, payload []byte) error {
+	client, err := gi.Upstream()
+	if err != nil {
+		return err
+	}
+
+	req := rpctypes.UpEmitReq{
+		UpBaseReq: MakeUpBaseReq(),
+		Name:      name,
+		Payload:   payload,
+	}
+
+	res := rpctypes.UpEmitResp{}
+	err = client.Call("RPC.Emit", req, &res)
+	if err != nil {
+		return errors.Wrap(err, "on calling main API")
+	}
+
+	return nil
+}
+
 // Serialize - CBOR serializer wrapper: `what` -> `to`
 func (gi *GoInsider) Serialize(what interface{}, to *[]byte) error {
 	ch := new(codec.CborHandle)
@@ -372,7 +608,10 @@ func (gi *GoInsider) Deserialize(from []byte, into interface{}) error {
 
 // MakeErrorSerializable converts errors satisfying error interface to foundation.Error
 func (gi *GoInsider) MakeErrorSerializable(e error) error {
-	if e == nil || e == (*foundation.Error)(nil) || reflect.ValueOf(e).IsNil() {
+	if e == nil || e == (*foundation.Error)(nil) {
+		return nil
+	}
+	if v := reflect.ValueOf(e); v.Kind() == reflect.Ptr && v.IsNil() {
 		return nil
 	}
 	return &foundation.Error{S: e.Error()}
@@ -0,0 +1,81 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package ginsider
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestInsider creates GoInsider which reports exit code to the channel instead of exiting
+func newTestInsider(t *testing.T) (*GoInsider, chan int) {
+	gi, err := NewGoInsider(os.TempDir(), "unix", "", 0)
+	require.NoError(t, err)
+	exited := make(chan int, 1)
+	gi.exit = func(code int) {
+		exited <- code
+	}
+	return gi, exited
+}
+
+func TestGoInsider_ExecuteLimits(t *testing.T) {
+	gi, _ := newTestInsider(t)
+	ctx := &core.LogicCallContext{Limits: core.CallLimits{Time: 200 * time.Millisecond, Memory: 1 << 20}}
+
+	usage, err := gi.execute(ctx, func() error {
+		defer nestedCall()()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Calls)
+
+	// calls exceeding limits are reported while they are executed and the runner exits
+	stop := make(chan struct{})
+	defer close(stop)
+	for name, f := range map[string]func() error{
+		"time limit exceeded": func() error {
+			<-stop
+			return nil
+		},
+		"memory limit exceeded": func() error {
+			var garbage [][]byte
+			for {
+				select {
+				case <-stop:
+					return nil
+				default:
+					garbage = append(garbage, make([]byte, 1024))
+				}
+			}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gi, exited := newTestInsider(t)
+			start := time.Now()
+			_, err := gi.execute(ctx, f)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), name)
+			assert.True(t, time.Since(start) < time.Second, "call should be aborted while executed")
+			assert.Equal(t, 1, <-exited)
+		})
+	}
+}
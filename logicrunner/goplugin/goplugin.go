@@ -19,7 +19,6 @@ package goplugin
 
 import (
 	"net/rpc"
-	"os/exec"
 	"time"
 
 	"github.com/insolar/insolar/configuration"
@@ -48,16 +47,20 @@ type GoPlugin struct {
 	Cfg             *configuration.LogicRunner
 	MessageBus      core.MessageBus
 	ArtifactManager core.ArtifactManager
-	runner          *exec.Cmd
-	client          *rpc.Client
+	runners         *runnerPool
 }
 
 // NewGoPlugin returns a new started GoPlugin
 func NewGoPlugin(conf *configuration.LogicRunner, eb core.MessageBus, am core.ArtifactManager) (*GoPlugin, error) {
+	runners, err := newRunnerPool(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't start runners")
+	}
 	gp := GoPlugin{
 		Cfg:             conf,
 		MessageBus:      eb,
 		ArtifactManager: am,
+		runners:         runners,
 	}
 
 	return &gp, nil
@@ -65,37 +68,49 @@ func NewGoPlugin(conf *configuration.LogicRunner, eb core.MessageBus, am core.Ar
 
 // Stop stops runner(s) and RPC service
 func (gp *GoPlugin) Stop() error {
+	gp.runners.Stop()
 	return nil
 }
 
-// Downstream returns a connection to `ginsider`
-func (gp *GoPlugin) Downstream() (*rpc.Client, error) {
-	if gp.client != nil {
-		return gp.client, nil
-	}
-
-	client, err := rpc.Dial(gp.Cfg.GoPlugin.RunnerProtocol, gp.Cfg.GoPlugin.RunnerListen)
+// downstreamCall runs an RPC on one of the runners, call fails immediately if connection to the runner is lost.
+// Runner executing the call longer than allowed is killed, other calls executed by it fail.
+func (gp *GoPlugin) downstreamCall(ctx *core.LogicCallContext, method string, req interface{}, res interface{}) error {
+	r, client, err := gp.runners.get()
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "couldn't dial '%s' over %s",
-			gp.Cfg.GoPlugin.RunnerListen, gp.Cfg.GoPlugin.RunnerProtocol,
-		)
+		return errors.Wrap(err, "problem with rpc connection")
 	}
 
-	gp.client = client
-	return gp.client, nil
+	select {
+	case call := <-client.Go(method, req, res, nil).Done:
+		if call.Error == nil {
+			return nil
+		}
+		if _, ok := call.Error.(rpc.ServerError); ok {
+			return errors.Wrap(call.Error, "problem with API call")
+		}
+		r.disconnect(client)
+		return errors.Wrapf(ErrRunnerDied, "runner %s: %s", r.address, call.Error)
+	case <-time.After(callTimeout(ctx)):
+		// the call can't be interrupted, so spawned runner is killed to stop it and restarted by the pool
+		r.kill()
+		r.disconnect(client)
+		return errors.Errorf("logicrunner execution timeout, runner %s is restarted", r.address)
+	}
 }
 
 const timeout = time.Minute * 10
 
+// callTimeout returns time limit of the call or default timeout if it's not limited
+func callTimeout(ctx *core.LogicCallContext) time.Duration {
+	if ctx.Limits.Time > 0 {
+		return ctx.Limits.Time
+	}
+	return timeout
+}
+
 // CallMethod runs a method on an object in controlled environment
 func (gp *GoPlugin) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
 	start := time.Now()
-	client, err := gp.Downstream()
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "problem with rpc connection")
-	}
-
 	res := rpctypes.DownCallMethodResp{}
 	req := rpctypes.DownCallMethodReq{
 		Context:   ctx,
@@ -105,35 +120,24 @@ func (gp *GoPlugin) CallMethod(ctx *core.LogicCallContext, code core.RecordRef,
 		Arguments: args,
 	}
 
-	select {
-	case call := <-client.Go("RPC.CallMethod", req, &res, nil).Done:
-		log.Debugf("CallMethod done work, time spend in here - %s", time.Since(start))
-		if call.Error != nil {
-			return nil, nil, errors.Wrap(call.Error, "problem with API call")
-		}
-	case <-time.After(timeout):
-		return nil, nil, errors.New("logicrunner execution timeout")
+	err := gp.downstreamCall(ctx, "RPC.CallMethod", req, &res)
+	if err != nil {
+		return nil, nil, err
 	}
+	log.Debugf("CallMethod done work, time spend in here - %s", time.Since(start))
+	ctx.Usage = res.Usage
+	ctx.Aborted = res.Aborted
 	return res.Data, res.Ret, nil
 }
 
 // CallConstructor runs a constructor of a contract in controlled environment
 func (gp *GoPlugin) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
-	client, err := gp.Downstream()
-	if err != nil {
-		return nil, errors.Wrap(err, "problem with rpc connection")
-	}
-
 	res := rpctypes.DownCallConstructorResp{}
 	req := rpctypes.DownCallConstructorReq{Code: code, Name: name, Arguments: args}
 
-	select {
-	case call := <-client.Go("RPC.CallConstructor", req, &res, nil).Done:
-		if call.Error != nil {
-			return nil, errors.Wrap(call.Error, "problem with API call")
-		}
-	case <-time.After(timeout):
-		return nil, errors.New("logicrunner execution timeout")
+	err := gp.downstreamCall(ctx, "RPC.CallConstructor", req, &res)
+	if err != nil {
+		return nil, err
 	}
 	return res.Ret, nil
 }
@@ -17,6 +17,8 @@
 package goplugintestutils
 
 import (
+	"context"
+
 	"github.com/insolar/insolar/core"
 )
 
@@ -41,6 +43,13 @@ func (eb *TestMessageBus) Send(msg core.Message) (resp core.Reply, err error) {
 	return eb.LogicRunner.Execute(msg)
 }
 
+// SendWithOptions executes message on LogicRunner, options are ignored.
+func (eb *TestMessageBus) SendWithOptions(
+	ctx context.Context, msg core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return eb.Send(msg)
+}
+
 // SendAsync sends message async
 func (*TestMessageBus) SendAsync(msg core.Message) {}
 
@@ -17,6 +17,7 @@
 package goplugintestutils
 
 import (
+	"fmt"
 	"go/build"
 	"io/ioutil"
 	"os"
@@ -97,9 +98,14 @@ func (t *TestCodeDescriptor) Code() ([]byte, error) {
 
 // TestClassDescriptor ...
 type TestClassDescriptor struct {
-	AM    *TestArtifactManager
-	ARef  *core.RecordRef
-	ACode *core.RecordRef
+	AM          *TestArtifactManager
+	ARef        *core.RecordRef
+	ACode       *core.RecordRef
+	AState      *core.RecordID
+	AMigrations []core.RecordRef
+	AOwner      string
+
+	prev *TestClassDescriptor
 }
 
 // HeadRef ...
@@ -109,7 +115,25 @@ func (t *TestClassDescriptor) HeadRef() *core.RecordRef {
 
 // StateID ...
 func (t *TestClassDescriptor) StateID() *core.RecordID {
-	panic("not implemented")
+	return t.AState
+}
+
+// PrevStateID ...
+func (t *TestClassDescriptor) PrevStateID() *core.RecordID {
+	if t.prev == nil {
+		return nil
+	}
+	return t.prev.AState
+}
+
+// Migrations ...
+func (t *TestClassDescriptor) Migrations() []core.RecordRef {
+	return t.AMigrations
+}
+
+// Owner ...
+func (t *TestClassDescriptor) Owner() string {
+	return t.AOwner
 }
 
 // CodeDescriptor ...≈
@@ -127,6 +151,7 @@ type TestObjectDescriptor struct {
 	Data              []byte
 	Code              *core.RecordRef
 	Class             *core.RecordRef
+	ClassState        *core.RecordID
 	Delegates         map[core.RecordRef]core.RecordRef
 	ChildrenContainer []core.RecordRef
 }
@@ -146,6 +171,11 @@ func (t *TestObjectDescriptor) Memory() []byte {
 	return t.Data
 }
 
+// ClassStateID implementation for tests
+func (t *TestObjectDescriptor) ClassStateID() *core.RecordID {
+	return t.ClassState
+}
+
 // Children implementation for tests
 func (t *TestObjectDescriptor) Children(pulse *core.PulseNumber) (core.RefIterator, error) {
 	panic("not implemented")
@@ -170,6 +200,9 @@ type TestArtifactManager struct {
 	Codes   map[core.RecordRef]*TestCodeDescriptor
 	Objects map[core.RecordRef]*TestObjectDescriptor
 	Classes map[core.RecordRef]*TestClassDescriptor
+	Events  map[core.RecordRef][]core.Event
+
+	ClassTypes map[core.RecordRef][]byte // Latest type declaration of the class
 }
 
 // GetChildren implementation for tests
@@ -183,6 +216,9 @@ func NewTestArtifactManager() *TestArtifactManager {
 		Codes:   make(map[core.RecordRef]*TestCodeDescriptor),
 		Objects: make(map[core.RecordRef]*TestObjectDescriptor),
 		Classes: make(map[core.RecordRef]*TestClassDescriptor),
+		Events:  make(map[core.RecordRef][]core.Event),
+
+		ClassTypes: make(map[core.RecordRef][]byte),
 	}
 }
 
@@ -207,7 +243,15 @@ func (t *TestArtifactManager) GetClass(object core.RecordRef, state *core.Record
 	if !ok {
 		return nil, errors.New("No object")
 	}
-	return res, nil
+	if state == nil {
+		return res, nil
+	}
+	for ; res != nil; res = res.prev {
+		if res.AState != nil && *res.AState == state.GetRecordID() {
+			return res, nil
+		}
+	}
+	return nil, errors.New("No class state")
 }
 
 // GetObject implementation for tests
@@ -235,8 +279,13 @@ func (t *TestArtifactManager) GetDelegate(head, asClass core.RecordRef) (*core.R
 }
 
 // DeclareType implementation for tests
-func (t *TestArtifactManager) DeclareType(domain core.RecordRef, request core.RecordRef, typeDec []byte) (*core.RecordRef, error) {
-	panic("not implemented")
+func (t *TestArtifactManager) DeclareType(domain core.RecordRef, request core.RecordRef, class core.RecordRef, typeDec []byte) (*core.RecordRef, error) {
+	if _, ok := t.Classes[class]; !ok {
+		return nil, errors.New("No class")
+	}
+	t.ClassTypes[class] = typeDec
+	ref := testutils.RandomRef()
+	return &ref, nil
 }
 
 // DeployCode implementation for tests
@@ -261,13 +310,16 @@ func (t *TestArtifactManager) GetCode(code core.RecordRef) (core.CodeDescriptor,
 }
 
 // ActivateClass implementation for tests
-func (t *TestArtifactManager) ActivateClass(domain core.RecordRef, request core.RecordRef, code core.RecordRef) (*core.RecordID, error) {
+func (t *TestArtifactManager) ActivateClass(domain core.RecordRef, request core.RecordRef, code core.RecordRef, owner string) (*core.RecordID, error) {
+	id := testutils.RandomID()
 	t.Classes[request] = &TestClassDescriptor{
-		AM:   t,
-		ARef: &request,
+		AM:     t,
+		ARef:   &request,
+		ACode:  &code,
+		AState: &id,
+		AOwner: owner,
 	}
 
-	id := testutils.RandomID()
 	return &id, nil
 }
 
@@ -282,8 +334,16 @@ func (t *TestArtifactManager) UpdateClass(domain core.RecordRef, request core.Re
 	if !ok {
 		return nil, errors.New("wrong class")
 	}
-	classDesc.ACode = &code
 	id := testutils.RandomID()
+	t.Classes[class] = &TestClassDescriptor{
+		AM:          t,
+		ARef:        classDesc.ARef,
+		ACode:       &code,
+		AState:      &id,
+		AMigrations: migrationRefs,
+		AOwner:      classDesc.AOwner,
+		prev:        classDesc,
+	}
 	return &id, nil
 }
 
@@ -292,11 +352,12 @@ func (t *TestArtifactManager) ActivateObject(domain core.RecordRef, request core
 	codeRef := t.Classes[class].ACode
 
 	t.Objects[request] = &TestObjectDescriptor{
-		AM:        t,
-		Data:      memory,
-		Code:      codeRef,
-		Class:     &class,
-		Delegates: make(map[core.RecordRef]core.RecordRef),
+		AM:         t,
+		Data:       memory,
+		Code:       codeRef,
+		Class:      &class,
+		ClassState: t.Classes[class].AState,
+		Delegates:  make(map[core.RecordRef]core.RecordRef),
 	}
 
 	id := testutils.RandomID()
@@ -322,7 +383,11 @@ func (t *TestArtifactManager) ActivateObjectDelegate(domain, request, class, par
 
 // DeactivateObject implementation for tests
 func (t *TestArtifactManager) DeactivateObject(domain core.RecordRef, request core.RecordRef, obj core.RecordRef) (*core.RecordID, error) {
-	panic("not implemented")
+	if _, ok := t.Objects[obj]; !ok {
+		return nil, errors.New("No object to deactivate")
+	}
+	delete(t.Objects, obj)
+	return &core.RecordID{}, nil
 }
 
 // UpdateObject implementation for tests
@@ -338,6 +403,24 @@ func (t *TestArtifactManager) UpdateObject(domain core.RecordRef, request core.R
 	return &core.RecordID{}, nil
 }
 
+// MigrateObject implementation for tests
+func (t *TestArtifactManager) MigrateObject(domain core.RecordRef, request core.RecordRef, obj core.RecordRef, classState core.RecordID, memory []byte) (*core.RecordID, error) {
+	objDesc, ok := t.Objects[obj]
+	if !ok {
+		return nil, errors.New("No object to migrate")
+	}
+
+	objDesc.Data = memory
+	objDesc.ClassState = &classState
+	return &core.RecordID{}, nil
+}
+
+// EmitEvent implementation for tests
+func (t *TestArtifactManager) EmitEvent(domain core.RecordRef, request core.RecordRef, obj core.RecordRef, name string, payload []byte) (*core.RecordID, error) {
+	t.Events[obj] = append(t.Events[obj], core.Event{Name: name, Payload: payload})
+	return &core.RecordID{}, nil
+}
+
 // CBORMarshal - testing serialize helper
 func CBORMarshal(t testing.TB, o interface{}) []byte {
 	ch := new(codec.CborHandle)
@@ -385,7 +468,7 @@ func AMPublishCode(
 	nonce := testutils.RandomRef()
 	classRef, err = am.RegisterRequest(&message.CallConstructor{ClassRef: nonce})
 	assert.NoError(t, err)
-	_, err = am.ActivateClass(domain, *classRef, *codeRef)
+	_, err = am.ActivateClass(domain, *classRef, *codeRef, "")
 	assert.NoError(t, err, "create template for contract data")
 
 	return typeRef, codeRef, classRef, err
@@ -399,6 +482,8 @@ type ContractsBuilder struct {
 	IccPath         string
 	Classes         map[string]*core.RecordRef
 	Codes           map[string]*core.RecordRef
+
+	versions map[string]int
 }
 
 // NewContractBuilder returns a new `ContractsBuilder`, takes in: path to tmp directory,
@@ -413,6 +498,7 @@ func NewContractBuilder(am core.ArtifactManager, icc string) *ContractsBuilder {
 		root:            tmpDir,
 		Classes:         make(map[string]*core.RecordRef),
 		Codes:           make(map[string]*core.RecordRef),
+		versions:        make(map[string]int),
 		ArtifactManager: am,
 		IccPath:         icc}
 	return cb
@@ -441,10 +527,8 @@ func (cb *ContractsBuilder) Build(contracts map[string]string) error {
 		cb.Classes[name] = class
 	}
 
-	re := regexp.MustCompile(`package\s+\S+`)
 	for name, code := range contracts {
-		code = re.ReplaceAllString(code, "package main")
-		err := WriteFile(filepath.Join(cb.root, "src/contract", name), "main.go", code)
+		err := cb.source(name, code)
 		if err != nil {
 			return err
 		}
@@ -459,40 +543,101 @@ func (cb *ContractsBuilder) Build(contracts map[string]string) error {
 	}
 
 	for name := range contracts {
-		log.Debugf("Building plugin for contract %q in %q", name, cb.root)
-		err := cb.plugin(name)
+		code, err := cb.deploy(name)
 		if err != nil {
 			return err
 		}
-		log.Debugf("Built plugin for contract %q", name)
+		cb.Codes[name] = code
 
-		pluginBinary, err := ioutil.ReadFile(filepath.Join(cb.root, "plugins", name+".so"))
+		_, err = cb.ArtifactManager.ActivateClass(
+			core.RecordRef{}, *cb.Classes[name],
+			*code, "",
+		)
 		if err != nil {
 			return err
 		}
+	}
 
-		code, err := cb.ArtifactManager.DeployCode(
-			core.RecordRef{}, core.RecordRef{},
-			pluginBinary, core.MachineTypeGoPlugin,
-		)
+	return nil
+}
+
+// Update builds new code of already built contract and migration code that converts memory of objects
+// to the new version, then updates the class
+func (cb *ContractsBuilder) Update(name string, code string, migration string) error {
+	class, ok := cb.Classes[name]
+	if !ok {
+		return errors.New("contract " + name + " is not built")
+	}
+
+	// plugins with the same package path can't be loaded twice, so every version has its own package
+	cb.versions[name]++
+	version := fmt.Sprintf("%s_v%d", name, cb.versions[name])
+	sources := map[string]string{
+		version:                code,
+		version + "_migration": migration,
+	}
+	refs := make(map[string]*core.RecordRef)
+	for pkg, src := range sources {
+		err := cb.source(pkg, src)
 		if err != nil {
 			return err
 		}
-		log.Debugf("Deployed code %q for contract %q in %q", code.String(), name, cb.root)
-		cb.Codes[name] = code
-
-		_, err = cb.ArtifactManager.ActivateClass(
-			core.RecordRef{}, *cb.Classes[name],
-			*code,
-		)
+		err = cb.wrapper(pkg)
+		if err != nil {
+			return err
+		}
+		refs[pkg], err = cb.deploy(pkg)
 		if err != nil {
 			return err
 		}
 	}
 
+	_, err := cb.ArtifactManager.UpdateClass(
+		core.RecordRef{}, core.RecordRef{}, *class,
+		*refs[version], []core.RecordRef{*refs[version+"_migration"]},
+	)
+	if err != nil {
+		return err
+	}
+	cb.Codes[name] = refs[version]
 	return nil
 }
 
+func (cb *ContractsBuilder) source(name string, code string) error {
+	code = regexp.MustCompile(`package\s+\S+`).ReplaceAllString(code, "package main")
+	return WriteFile(filepath.Join(cb.root, "src/contract", name), "main.go", code)
+}
+
+// deploy builds plugin and stores it on ledger
+func (cb *ContractsBuilder) deploy(name string) (*core.RecordRef, error) {
+	log.Debugf("Building plugin for contract %q in %q", name, cb.root)
+	err := cb.plugin(name)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Built plugin for contract %q", name)
+
+	pluginBinary, err := ioutil.ReadFile(filepath.Join(cb.root, "plugins", name+".so"))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := testutils.RandomRef()
+	request, err := cb.ArtifactManager.RegisterRequest(&message.CallConstructor{ClassRef: nonce})
+	if err != nil {
+		return nil, err
+	}
+	code, err := cb.ArtifactManager.DeployCode(
+		core.RecordRef{}, *request,
+		pluginBinary, core.MachineTypeGoPlugin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Deployed code %q for contract %q in %q", code.String(), name, cb.root)
+	return code, nil
+}
+
 func (cb *ContractsBuilder) proxy(name string) error {
 	dstDir := filepath.Join(cb.root, "src/github.com/insolar/insolar/application/proxy", name)
 
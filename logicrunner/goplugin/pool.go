@@ -0,0 +1,284 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package goplugin
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/log"
+	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
+	"github.com/pkg/errors"
+)
+
+// ErrRunnerDied is returned when connection to the runner is lost while a call is executed
+var ErrRunnerDied = errors.New("runner died while executing the call")
+
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	restartDelay        = time.Second
+	startTimeout        = 5 * time.Second
+	startPollInterval   = 50 * time.Millisecond
+)
+
+// runner is one `insgorund` process
+type runner struct {
+	protocol string
+	address  string
+	args     []string // arguments to spawn the runner with, nil if the runner is external
+
+	mu      sync.Mutex
+	client  *rpc.Client
+	process *os.Process
+}
+
+// connect returns connection to the runner, dials it if connection was lost
+func (r *runner) connect() (*rpc.Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		return r.client, nil
+	}
+
+	client, err := rpc.Dial(r.protocol, r.address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't dial '%s' over %s", r.address, r.protocol)
+	}
+	r.client = client
+	return client, nil
+}
+
+// disconnect closes connection to the runner, calls in progress fail immediately
+func (r *runner) disconnect(client *rpc.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client != nil && r.client != client {
+		return
+	}
+	if r.client != nil {
+		r.client.Close() // nolint: errcheck
+		r.client = nil
+	}
+}
+
+// ping checks that the runner responds
+func (r *runner) ping(client *rpc.Client) error {
+	nonce := uint64(time.Now().UnixNano())
+	res := rpctypes.DownPingResp{}
+	select {
+	case call := <-client.Go("RPC.Ping", rpctypes.DownPingReq{Nonce: nonce}, &res, nil).Done:
+		if call.Error != nil {
+			r.disconnect(client)
+			return call.Error
+		}
+	case <-time.After(healthCheckTimeout):
+		r.disconnect(client)
+		return errors.New("ping timeout")
+	}
+	if res.Nonce != nonce {
+		r.disconnect(client)
+		return errors.New("wrong ping response")
+	}
+	return nil
+}
+
+// kill terminates process of the spawned runner, it's restarted by the pool
+func (r *runner) kill() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.process != nil {
+		r.process.Kill() // nolint: errcheck
+	}
+}
+
+// runnerPool spreads calls across runners, spawns and restarts them if they are managed by the node
+type runnerPool struct {
+	runners []*runner
+	next    uint32
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// runnerAddress returns address of the runner with index i
+func runnerAddress(protocol, listen string, i int) (string, error) {
+	if i == 0 {
+		return listen, nil
+	}
+	if protocol == "unix" {
+		return fmt.Sprintf("%s.%d", listen, i), nil
+	}
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p+i)), nil
+}
+
+func newRunnerPool(cfg *configuration.LogicRunner) (*runnerPool, error) {
+	count := cfg.GoPlugin.RunnerCount
+	if count < 1 {
+		count = 1
+	}
+	p := &runnerPool{stop: make(chan struct{})}
+	for i := 0; i < count; i++ {
+		address, err := runnerAddress(cfg.GoPlugin.RunnerProtocol, cfg.GoPlugin.RunnerListen, i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't make address of runner #%d", i)
+		}
+		r := &runner{protocol: cfg.GoPlugin.RunnerProtocol, address: address}
+		if cfg.GoPlugin.RunnerPath != "" {
+			r.args = []string{
+				"-l", address, "--proto", r.protocol,
+				"--rpc", cfg.RPCListen, "--rpc-proto", cfg.RPCProtocol,
+			}
+		}
+		p.runners = append(p.runners, r)
+	}
+
+	for _, r := range p.runners {
+		if r.args != nil {
+			p.wg.Add(1)
+			go p.supervise(cfg.GoPlugin.RunnerPath, r)
+		}
+	}
+	p.wg.Add(1)
+	go p.healthCheck()
+	p.waitStarted()
+	return p, nil
+}
+
+// waitStarted waits until spawned runners accept connections
+func (p *runnerPool) waitStarted() {
+	deadline := time.Now().Add(startTimeout)
+	for _, r := range p.runners {
+		for r.args != nil && time.Now().Before(deadline) {
+			if _, err := r.connect(); err == nil {
+				break
+			}
+			time.Sleep(startPollInterval)
+		}
+	}
+}
+
+// supervise spawns the runner and restarts it when it exits
+func (p *runnerPool) supervise(path string, r *runner) {
+	defer p.wg.Done()
+	for {
+		if r.protocol == "unix" {
+			os.Remove(r.address) // nolint: errcheck
+		}
+		cmd := exec.Command(path, r.args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Start()
+		if err != nil {
+			log.Errorf("couldn't start runner %s: %s", r.address, err)
+		} else {
+			r.mu.Lock()
+			r.process = cmd.Process
+			r.mu.Unlock()
+
+			exited := make(chan error, 1)
+			go func() {
+				exited <- cmd.Wait()
+			}()
+			select {
+			case err := <-exited:
+				log.Errorf("runner %s exited: %v", r.address, err)
+			case <-p.stop:
+				cmd.Process.Kill() // nolint: errcheck
+				<-exited
+				return
+			}
+
+			r.mu.Lock()
+			r.process = nil
+			r.mu.Unlock()
+			r.disconnect(nil)
+		}
+
+		select {
+		case <-time.After(restartDelay):
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// healthCheck pings runners, drops connections to unresponsive ones and kills them if they are spawned
+func (p *runnerPool) healthCheck() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+		for _, r := range p.runners {
+			// runner could be starting, it's restarted by supervisor if it has exited
+			client, err := r.connect()
+			if err != nil {
+				log.Warnf("runner %s is unreachable: %s", r.address, err)
+				continue
+			}
+			err = r.ping(client)
+			if err != nil {
+				log.Warnf("runner %s failed health check: %s", r.address, err)
+				r.kill()
+			}
+		}
+	}
+}
+
+// get returns the next runner which can be connected to
+func (p *runnerPool) get() (*runner, *rpc.Client, error) {
+	start := atomic.AddUint32(&p.next, 1)
+	var lastErr error
+	for i := range p.runners {
+		r := p.runners[(int(start)+i)%len(p.runners)]
+		client, err := r.connect()
+		if err == nil {
+			return r, client, nil
+		}
+		lastErr = err
+	}
+	return nil, nil, errors.Wrap(lastErr, "no runners available")
+}
+
+// Stop stops spawned runners and closes connections
+func (p *runnerPool) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+	for _, r := range p.runners {
+		r.disconnect(nil)
+	}
+}
@@ -0,0 +1,279 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package goplugin
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
+	"github.com/insolar/insolar/testutils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as a spawned runner if it's requested by environment
+func TestMain(m *testing.M) {
+	if os.Getenv("INSOLAR_TEST_RUNNER") != "" {
+		serveSpawnedRunner(os.Args[2])
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// spawnedRPC returns pid of the runner process as a result of any call
+type spawnedRPC struct{}
+
+func (s *spawnedRPC) Ping(args rpctypes.DownPingReq, reply *rpctypes.DownPingResp) error {
+	reply.Nonce = args.Nonce
+	return nil
+}
+
+func (s *spawnedRPC) CallMethod(args rpctypes.DownCallMethodReq, reply *rpctypes.DownCallMethodResp) error {
+	if args.Method == "block" {
+		select {}
+	}
+	reply.Ret = core.Arguments(strconv.Itoa(os.Getpid()))
+	return nil
+}
+
+func serveSpawnedRunner(address string) {
+	server := rpc.NewServer()
+	server.RegisterName("RPC", &spawnedRPC{}) // nolint: errcheck
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		os.Exit(1)
+	}
+	server.Accept(l)
+}
+
+// fakeRunner serves RPC of `insgorund`
+type fakeRunner struct {
+	listener net.Listener
+	calls    chan string
+	release  chan struct{}
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+type fakeRPC struct {
+	r *fakeRunner
+}
+
+func (f *fakeRPC) Ping(args rpctypes.DownPingReq, reply *rpctypes.DownPingResp) error {
+	reply.Nonce = args.Nonce
+	return nil
+}
+
+func (f *fakeRPC) CallMethod(args rpctypes.DownCallMethodReq, reply *rpctypes.DownCallMethodResp) error {
+	f.r.calls <- args.Method
+	if args.Method == "block" {
+		<-f.r.release
+	}
+	reply.Ret = core.Arguments(args.Method)
+	return nil
+}
+
+func startFakeRunner(t *testing.T, address string) *fakeRunner {
+	server := rpc.NewServer()
+	r := &fakeRunner{calls: make(chan string, 10), release: make(chan struct{})}
+	require.NoError(t, server.RegisterName("RPC", &fakeRPC{r: r}))
+	l, err := net.Listen("unix", address)
+	require.NoError(t, err)
+	r.listener = l
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.conns = append(r.conns, conn)
+			r.mu.Unlock()
+			go server.ServeConn(conn)
+		}
+	}()
+	return r
+}
+
+// crash closes listener and all connections of the runner
+func (r *fakeRunner) crash() {
+	r.listener.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+}
+
+func newTestGoPlugin(t *testing.T, address string, count int) *GoPlugin {
+	gp, err := NewGoPlugin(&configuration.LogicRunner{
+		GoPlugin: &configuration.GoPlugin{
+			RunnerListen:   address,
+			RunnerProtocol: "unix",
+			RunnerCount:    count,
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	return gp
+}
+
+func TestRunnerAddress(t *testing.T) {
+	address, err := runnerAddress("tcp", "127.0.0.1:7777", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:7777", address)
+	address, err = runnerAddress("tcp", "127.0.0.1:7777", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:7779", address)
+	address, err = runnerAddress("unix", "/tmp/runner.sock", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/runner.sock.1", address)
+	_, err = runnerAddress("tcp", "127.0.0.1", 1)
+	assert.Error(t, err)
+}
+
+func TestGoPlugin_RunnerCrash(t *testing.T) {
+	address := os.TempDir() + "/" + testutils.RandomString() + ".sock"
+	runner := startFakeRunner(t, address)
+	gp := newTestGoPlugin(t, address, 1)
+	defer gp.Stop()
+	ctx := &core.LogicCallContext{}
+
+	_, ret, err := gp.CallMethod(ctx, core.RecordRef{}, nil, "first", nil)
+	require.NoError(t, err)
+	assert.Equal(t, core.Arguments("first"), ret)
+	assert.Equal(t, "first", <-runner.calls)
+
+	// call in progress fails as soon as the runner dies
+	errs := make(chan error)
+	go func() {
+		_, _, err := gp.CallMethod(ctx, core.RecordRef{}, nil, "block", nil)
+		errs <- err
+	}()
+	assert.Equal(t, "block", <-runner.calls)
+	runner.crash()
+	defer close(runner.release)
+	select {
+	case err := <-errs:
+		assert.Equal(t, ErrRunnerDied, errors.Cause(err))
+	case <-time.After(time.Second):
+		t.Fatal("call to dead runner didn't fail")
+	}
+
+	// runner is connected again after restart
+	runner = startFakeRunner(t, address)
+	defer runner.crash()
+	_, ret, err = gp.CallMethod(ctx, core.RecordRef{}, nil, "second", nil)
+	require.NoError(t, err)
+	assert.Equal(t, core.Arguments("second"), ret)
+}
+
+func TestGoPlugin_RestartsRunner(t *testing.T) {
+	os.Setenv("INSOLAR_TEST_RUNNER", "1")
+	defer os.Unsetenv("INSOLAR_TEST_RUNNER")
+	address := os.TempDir() + "/" + testutils.RandomString() + ".sock"
+	gp, err := NewGoPlugin(&configuration.LogicRunner{
+		GoPlugin: &configuration.GoPlugin{
+			RunnerListen:   address,
+			RunnerProtocol: "unix",
+			RunnerPath:     os.Args[0],
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	defer gp.Stop()
+	ctx := &core.LogicCallContext{}
+
+	_, ret, err := gp.CallMethod(ctx, core.RecordRef{}, nil, "pid", nil)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(string(ret))
+	require.NoError(t, err)
+
+	require.NoError(t, syscall.Kill(pid, syscall.SIGKILL))
+	spawnedRunnerPid(t, gp, strconv.Itoa(pid))
+}
+
+// spawnedRunnerPid returns pid of the runner which isn't equal to provided one
+func spawnedRunnerPid(t *testing.T, gp *GoPlugin, not string) string {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, ret, err := gp.CallMethod(&core.LogicCallContext{}, core.RecordRef{}, nil, "pid", nil)
+		if err == nil && string(ret) != not {
+			return string(ret)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("runner wasn't restarted")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestGoPlugin_TimeoutRestartsRunner(t *testing.T) {
+	os.Setenv("INSOLAR_TEST_RUNNER", "1")
+	defer os.Unsetenv("INSOLAR_TEST_RUNNER")
+	address := os.TempDir() + "/" + testutils.RandomString() + ".sock"
+	gp, err := NewGoPlugin(&configuration.LogicRunner{
+		GoPlugin: &configuration.GoPlugin{
+			RunnerListen:   address,
+			RunnerProtocol: "unix",
+			RunnerPath:     os.Args[0],
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	defer gp.Stop()
+	pid := spawnedRunnerPid(t, gp, "")
+
+	ctx := &core.LogicCallContext{Limits: core.CallLimits{Time: 100 * time.Millisecond}}
+	_, _, err = gp.CallMethod(ctx, core.RecordRef{}, nil, "block", nil)
+	assert.Error(t, err)
+	spawnedRunnerPid(t, gp, pid)
+}
+
+func TestGoPlugin_SpreadsCalls(t *testing.T) {
+	address := os.TempDir() + "/" + testutils.RandomString() + ".sock"
+	first := startFakeRunner(t, address)
+	defer first.crash()
+	second := startFakeRunner(t, address+".1")
+	defer second.crash()
+	gp := newTestGoPlugin(t, address, 2)
+	defer gp.Stop()
+
+	for i := 0; i < 4; i++ {
+		_, _, err := gp.CallMethod(&core.LogicCallContext{}, core.RecordRef{}, nil, "call", nil)
+		require.NoError(t, err)
+	}
+	assert.Len(t, first.calls, 2)
+	assert.Len(t, second.calls, 2)
+
+	// calls go to alive runner if another one is down
+	second.crash()
+	gp.runners.runners[1].disconnect(nil)
+	for i := 0; i < 2; i++ {
+		_, _, err := gp.CallMethod(&core.LogicCallContext{}, core.RecordRef{}, nil, "call", nil)
+		require.NoError(t, err)
+	}
+	assert.Len(t, first.calls, 4)
+}
@@ -0,0 +1,99 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package preprocessor
+
+import (
+	"encoding/json"
+	"go/ast"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ABI is machine-readable description of the contract's interface, it's deployed to ledger as the class type
+type ABI struct {
+	Contract     string      `json:"contract"`
+	Constructors []ABIMethod `json:"constructors"`
+	Methods      []ABIMethod `json:"methods"`
+}
+
+// ABIMethod is signature of a constructor or a method
+type ABIMethod struct {
+	Name      string     `json:"name"`
+	Arguments []ABIField `json:"arguments"`
+	Results   []ABIField `json:"results"`
+	Immutable bool       `json:"immutable,omitempty"`
+}
+
+// ABIField is an argument or a result, type is as written in the contract's source code
+type ABIField struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+// ABI returns description of the contract's constructors and exported methods
+func (pf *ParsedFile) ABI() *ABI {
+	return &ABI{
+		Contract:     pf.contract,
+		Constructors: pf.abiMethods(pf.constructors[pf.contract]),
+		Methods:      pf.abiMethods(pf.methods[pf.contract]),
+	}
+}
+
+// WriteABI writes ABI of the contract as JSON
+func (pf *ParsedFile) WriteABI(out io.Writer) error {
+	data, err := json.MarshalIndent(pf.ABI(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "couldn't marshal ABI")
+	}
+	_, err = out.Write(append(data, '\n'))
+	if err != nil {
+		return errors.Wrap(err, "couldn't write ABI to output")
+	}
+	return nil
+}
+
+func (pf *ParsedFile) abiMethods(list []*ast.FuncDecl) []ABIMethod {
+	res := []ABIMethod{}
+	for _, fun := range list {
+		res = append(res, ABIMethod{
+			Name:      fun.Name.Name,
+			Arguments: pf.abiFields(fun.Type.Params),
+			Results:   pf.abiFields(fun.Type.Results),
+			Immutable: isImmutable(fun),
+		})
+	}
+	return res
+}
+
+func (pf *ParsedFile) abiFields(list *ast.FieldList) []ABIField {
+	res := []ABIField{}
+	if list == nil {
+		return res
+	}
+	for _, field := range list.List {
+		typ := pf.codeOfNode(field.Type)
+		if len(field.Names) == 0 {
+			res = append(res, ABIField{Type: typ})
+			continue
+		}
+		for _, name := range field.Names {
+			res = append(res, ABIField{Name: name.Name, Type: typ})
+		}
+	}
+	return res
+}
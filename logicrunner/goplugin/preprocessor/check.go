@@ -0,0 +1,254 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package preprocessor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AllowedImports is a list of packages contracts are allowed to import.
+// Entries ending with "/" allow every package under the prefix, sources of
+// such packages are checked as the contract itself.
+var AllowedImports = []string{
+	"bytes",
+	"crypto/sha256",
+	"crypto/sha512",
+	"encoding/base64",
+	"encoding/binary",
+	"encoding/hex",
+	"encoding/json",
+	"errors",
+	"fmt",
+	"math",
+	"math/big",
+	"sort",
+	"strconv",
+	"strings",
+	"time",
+	"unicode",
+	"unicode/utf8",
+
+	corePath,
+	foundationPath,
+	proxyctxPath,
+	"github.com/insolar/insolar/application/contract/",
+	"github.com/insolar/insolar/application/proxy/",
+	"github.com/insolar/insolar/cryptohelpers/ecdsa",
+	"github.com/ugorji/go/codec",
+}
+
+// forbiddenCalls lists functions of allowed packages that depend on the
+// environment of the node and can't be re-executed deterministically.
+var forbiddenCalls = map[string]map[string]bool{
+	"time": {
+		"After":        true,
+		"AfterFunc":    true,
+		"LoadLocation": true,
+		"NewTicker":    true,
+		"NewTimer":     true,
+		"Now":          true,
+		"Since":        true,
+		"Sleep":        true,
+		"Tick":         true,
+		"Until":        true,
+	},
+}
+
+// buildContext is used to find sources of imported packages
+var buildContext = build.Default
+
+// Diagnostic is a problem found in contract's source code
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String formats diagnostic as `file:line:column: message`
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Message
+}
+
+func isImportAllowed(importPath string) bool {
+	for _, allowed := range AllowedImports {
+		if importPath == allowed {
+			return true
+		}
+	}
+	return isImportedByPrefix(importPath)
+}
+
+// isImportedByPrefix checks if the package is allowed by one of prefixes of `AllowedImports`
+func isImportedByPrefix(importPath string) bool {
+	for _, allowed := range AllowedImports {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(importPath, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check walks through the contract's source code and reports constructs
+// that are unsafe or break deterministic execution of the contract:
+// imports outside of `AllowedImports`, dot imports, goroutines, channels,
+// ranging over maps and calls reading time of the node. Packages imported
+// by prefixes of `AllowedImports` are compiled into the contract, so their
+// sources are checked too.
+func (pf *ParsedFile) Check() []Diagnostic {
+	c := &checker{fileSet: pf.fileSet, checked: make(map[string]bool)}
+	c.checkPackage(filepath.Dir(pf.name), pf.node.Name.Name, []*ast.File{pf.node})
+	return c.res
+}
+
+// checker collects diagnostics of the contract and packages it imports
+type checker struct {
+	fileSet *token.FileSet
+	checked map[string]bool // imported packages which sources are already checked
+	res     []Diagnostic
+}
+
+func (c *checker) report(pos token.Pos, format string, args ...interface{}) {
+	c.res = append(c.res, Diagnostic{
+		Pos:     c.fileSet.Position(pos),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkPackage checks files of the package from the directory and sources of packages they import
+func (c *checker) checkPackage(dir string, name string, files []*ast.File) {
+	info := typesInfo(c.fileSet, name, files)
+	for _, file := range files {
+		from := len(c.res)
+		c.checkFile(file, info)
+		fileRes := c.res[from:]
+		sort.SliceStable(fileRes, func(i, j int) bool {
+			return fileRes[i].Pos.Offset < fileRes[j].Pos.Offset
+		})
+	}
+
+	for _, file := range files {
+		for _, imp := range file.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err == nil && isImportedByPrefix(importPath) {
+				c.checkImported(dir, importPath, imp.Pos())
+			}
+		}
+	}
+}
+
+// checkImported checks sources of the package imported from the directory
+func (c *checker) checkImported(dir string, importPath string, pos token.Pos) {
+	if c.checked[importPath] {
+		return
+	}
+	c.checked[importPath] = true
+
+	pkg, err := buildContext.Import(importPath, dir, 0)
+	if err != nil {
+		c.report(pos, "couldn't find sources of %q: %s", importPath, err)
+		return
+	}
+	if len(pkg.SFiles) > 0 {
+		c.report(pos, "assembly in %q is not allowed", importPath)
+	}
+	var files []*ast.File
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		file, err := parser.ParseFile(c.fileSet, filepath.Join(pkg.Dir, name), nil, 0)
+		if err != nil {
+			c.report(pos, "couldn't parse sources of %q: %s", importPath, err)
+			return
+		}
+		files = append(files, file)
+	}
+	c.checkPackage(pkg.Dir, pkg.Name, files)
+}
+
+func (c *checker) checkFile(file *ast.File, info *types.Info) {
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !isImportAllowed(importPath) {
+			c.report(imp.Pos(), "import of %s is not allowed", imp.Path.Value)
+		}
+		if imp.Name != nil && imp.Name.Name == "." {
+			c.report(imp.Pos(), "dot import of %s is not allowed", imp.Path.Value)
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			c.report(n.Pos(), "goroutines are not allowed")
+		case *ast.SelectStmt:
+			c.report(n.Pos(), "select statements are not allowed")
+		case *ast.ChanType:
+			c.report(n.Pos(), "channels are not allowed")
+		case *ast.RangeStmt:
+			if tv, ok := info.Types[n.X]; ok && tv.Type != nil {
+				if _, isMap := tv.Type.Underlying().(*types.Map); isMap {
+					c.report(n.Pos(), "range over map has random iteration order, sort keys first")
+				}
+			}
+		case *ast.SelectorExpr:
+			ident, ok := n.X.(*ast.Ident)
+			if !ok {
+				break
+			}
+			pkg, ok := info.Uses[ident].(*types.PkgName)
+			if !ok {
+				break
+			}
+			importPath := pkg.Imported().Path()
+			if forbiddenCalls[importPath][n.Sel.Name] {
+				c.report(n.Pos(), "%s.%s is not allowed", importPath, n.Sel.Name)
+			}
+		}
+		return true
+	})
+}
+
+// typesInfo type checks files of the package without resolving imported
+// packages, so types are known only for things declared in the package itself.
+func typesInfo(fileSet *token.FileSet, name string, files []*ast.File) *types.Info {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: stubImporter{},
+		Error:    func(error) {}, // unresolved imports produce lots of errors
+	}
+	conf.Check(name, fileSet, files, info) // nolint: errcheck
+	return info
+}
+
+// stubImporter provides empty packages for every import
+type stubImporter struct{}
+
+func (stubImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
@@ -0,0 +1,221 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package preprocessor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/insolar/insolar/logicrunner/goplugin/goplugintestutils"
+)
+
+func checkContract(t *testing.T, code string) []string {
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+
+	err = goplugintestutils.WriteFile(tmpDir, "main.go", code)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(filepath.Join(tmpDir, "main.go"))
+	assert.NoError(t, err)
+
+	var res []string
+	for _, d := range parsed.Check() {
+		assert.Equal(t, filepath.Join(tmpDir, "main.go"), d.Pos.Filename)
+		res = append(res, d.Pos.String()[len(tmpDir)+1:]+": "+d.Message)
+	}
+	return res
+}
+
+func TestCheckSafeContract(t *testing.T) {
+	t.Parallel()
+	diags := checkContract(t, `
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/insolar/insolar/application/proxy/wallet"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+type A struct {
+	foundation.BaseContract
+	Balances map[string]uint
+}
+
+func (a *A) Total(names []string) uint {
+	var keys []string
+	for k := range names {
+		keys = append(keys, names[k])
+	}
+	sort.Strings(keys)
+	total := uint(0)
+	for _, k := range keys {
+		total += a.Balances[k]
+	}
+	return total
+}
+
+func (a *A) Expired(deadline int64) bool {
+	return a.GetContext().Time.After(time.Unix(deadline, 0))
+}
+
+func (a *A) Wallet() *wallet.Wallet {
+	return nil
+}
+`)
+	assert.Empty(t, diags)
+}
+
+func TestCheckUnsafeContract(t *testing.T) {
+	t.Parallel()
+	diags := checkContract(t, `
+package main
+
+import (
+	"os"
+	"time"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+type A struct {
+	foundation.BaseContract
+	Balances map[string]uint
+}
+
+func (a *A) Total() uint {
+	total := uint(0)
+	for _, b := range a.Balances {
+		total += b
+	}
+	return total
+}
+
+func (a *A) Hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+func (a *A) Background() {
+	done := make(chan bool)
+	go func() {
+		time.Sleep(time.Second)
+		done <- true
+	}()
+	select {
+	case <-done:
+	}
+}
+
+func (a *A) Now() int64 {
+	now := time.Now
+	return now().Unix()
+}
+`)
+	assert.Equal(t, []string{
+		`main.go:5:2: import of "os" is not allowed`,
+		`main.go:17:2: range over map has random iteration order, sort keys first`,
+		`main.go:29:15: channels are not allowed`,
+		`main.go:30:2: goroutines are not allowed`,
+		`main.go:31:3: time.Sleep is not allowed`,
+		`main.go:34:2: select statements are not allowed`,
+		`main.go:40:9: time.Now is not allowed`,
+	}, diags)
+}
+
+func TestCheckDotImport(t *testing.T) {
+	t.Parallel()
+	diags := checkContract(t, `
+package main
+
+import (
+	. "time"
+
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+type A struct {
+	foundation.BaseContract
+}
+
+func (a *A) Now() int64 {
+	return Now().Unix()
+}
+`)
+	assert.Equal(t, []string{`main.go:5:2: dot import of "time" is not allowed`}, diags)
+}
+
+func TestCheckImportedContract(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "gopath-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(gopath) // nolint: errcheck
+
+	evil := filepath.Join(gopath, "src/github.com/insolar/insolar/application/contract/evil")
+	err = goplugintestutils.WriteFile(evil, "evil.go", `
+package evil
+
+import "os"
+
+func Home() string {
+	return os.Getenv("HOME")
+}
+`)
+	assert.NoError(t, err)
+
+	t.Setenv("GO111MODULE", "off")
+	defaultContext := buildContext
+	defer func() { buildContext = defaultContext }()
+	buildContext.GOPATH = gopath
+
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+	err = goplugintestutils.WriteFile(tmpDir, "main.go", `
+package main
+
+import (
+	"github.com/insolar/insolar/application/contract/evil"
+	"github.com/insolar/insolar/application/contract/missing"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+)
+
+type A struct {
+	foundation.BaseContract
+}
+
+func (a *A) Home() string {
+	return evil.Home() + missing.Home()
+}
+`)
+	assert.NoError(t, err)
+	parsed, err := ParseFile(filepath.Join(tmpDir, "main.go"))
+	assert.NoError(t, err)
+
+	diags := parsed.Check()
+	assert.Len(t, diags, 2)
+	assert.Equal(t, filepath.Join(evil, "evil.go"), diags[0].Pos.Filename)
+	assert.Equal(t, `import of "os" is not allowed`, diags[0].Message)
+	assert.Equal(t, filepath.Join(tmpDir, "main.go"), diags[1].Pos.Filename)
+	assert.Contains(t, diags[1].Message, `couldn't find sources of "github.com/insolar/insolar/application/contract/missing"`)
+}
@@ -32,6 +32,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -60,14 +61,20 @@ type ParsedFile struct {
 // ParseFile parses a file as Go source code of a smart contract
 // and returns it as `ParsedFile`
 func ParseFile(fileName string) (*ParsedFile, error) {
-	res := &ParsedFile{
-		name: fileName,
-	}
 	sourceCode, err := slurpFile(fileName)
 	if err != nil {
 		return nil, errors.Wrap(err, "Can't read file")
 	}
-	res.code = sourceCode
+	return ParseSource(fileName, sourceCode)
+}
+
+// ParseSource parses Go source code of a smart contract read from `fileName`
+// and returns it as `ParsedFile`
+func ParseSource(fileName string, sourceCode []byte) (*ParsedFile, error) {
+	res := &ParsedFile{
+		name: fileName,
+		code: sourceCode,
+	}
 
 	res.fileSet = token.NewFileSet()
 	node, err := parser.ParseFile(res.fileSet, res.name, res.code, parser.ParseComments)
@@ -147,7 +154,8 @@ func (pf *ParsedFile) parseFunctionsAndMethods() error {
 }
 
 func (pf *ParsedFile) parseConstructor(fd *ast.FuncDecl) {
-	if !strings.HasPrefix(fd.Name.Name, "New") {
+	// "Migrate" of migration code converts memory of the previous class state and is called as constructor
+	if !strings.HasPrefix(fd.Name.Name, "New") && fd.Name.Name != "Migrate" {
 		return // doesn't look like a constructor
 	}
 
@@ -226,6 +234,7 @@ func (pf *ParsedFile) functionInfoForWrapper(list []*ast.FuncDecl) []map[string]
 			"Arguments":           numberedVars(fun.Type.Params, "args"),
 			"Results":             numberedVars(fun.Type.Results, "ret"),
 			"ErrorInterfaceInRes": typeIndexes(pf, fun.Type.Results, "error"),
+			"ErrorResult":         lastErrorResult(pf, fun.Type.Results),
 		}
 		res = append(res, info)
 	}
@@ -274,6 +283,64 @@ func (pf *ParsedFile) WriteProxy(classReference string, out io.Writer) error {
 	return nil
 }
 
+// WriteClient generates typed client of the contract for calling it from outside of the network
+func (pf *ParsedFile) WriteClient(out io.Writer) error {
+	packageName, err := pf.ProxyPackageName()
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := openTemplate("templates/client.go.tpl")
+	if err != nil {
+		return errors.Wrap(err, "couldn't open template file for client")
+	}
+
+	data := map[string]interface{}{
+		"PackageName":  packageName,
+		"Types":        generateTypes(pf),
+		"ContractType": pf.contract,
+		"Methods":      pf.functionInfoForClient(pf.methods[pf.contract]),
+		"Imports":      pf.generateClientImports(),
+	}
+
+	var buff bytes.Buffer
+
+	err = tmpl.Execute(&buff, data)
+	if err != nil {
+		return errors.Wrap(err, "couldn't write code output handle")
+	}
+
+	fmtOut, err := format.Source(buff.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "couldn't format code")
+	}
+
+	_, err = out.Write(fmtOut)
+	if err != nil {
+		return errors.Wrap(err, "couldn't write code to output")
+	}
+
+	return nil
+}
+
+func (pf *ParsedFile) functionInfoForClient(list []*ast.FuncDecl) []map[string]string {
+	var res []map[string]string
+
+	for _, fun := range list {
+		info := map[string]string{
+			"Name":           fun.Name.Name,
+			"Arguments":      genFieldList(pf, fun.Type.Params, true),
+			"ArgumentsNames": argumentsNames(fun.Type.Params),
+			"ResultZeroList": generateZeroListOfTypes(pf, "ret", fun.Type.Results),
+			"ResultsTypes":   genFieldList(pf, fun.Type.Results, false),
+			"Immutable":      strconv.FormatBool(isImmutable(fun)),
+		}
+		pf.addErrorResult(fun.Type.Results, info, "toError(%s)", "err")
+		res = append(res, info)
+	}
+	return res
+}
+
 func (pf *ParsedFile) functionInfoForProxy(list []*ast.FuncDecl) []map[string]string {
 	var res []map[string]string
 
@@ -285,12 +352,76 @@ func (pf *ParsedFile) functionInfoForProxy(list []*ast.FuncDecl) []map[string]st
 			"ResultZeroList": generateZeroListOfTypes(pf, "ret", fun.Type.Results),
 			"Results":        numberedVars(fun.Type.Results, "ret"),
 			"ResultsTypes":   genFieldList(pf, fun.Type.Results, false),
+			"Immutable":      strconv.FormatBool(isImmutable(fun)),
 		}
+		pf.addProxyErrorResult(fun.Type.Results, info)
 		res = append(res, info)
 	}
 	return res
 }
 
+// isImmutable checks if the method is marked with `ins:immutable` annotation in its doc comment
+func isImmutable(fun *ast.FuncDecl) bool {
+	if fun.Doc == nil {
+		return false
+	}
+	for _, comment := range fun.Doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")) == "ins:immutable" {
+			return true
+		}
+	}
+	return false
+}
+
+// lastErrorResult returns name of the last result if it's error
+func lastErrorResult(pf *ParsedFile, list *ast.FieldList) string {
+	if list == nil {
+		return ""
+	}
+	n := list.NumFields()
+	errIndexes := typeIndexes(pf, list, "error")
+	if len(errIndexes) == 0 || errIndexes[len(errIndexes)-1] != n-1 {
+		return ""
+	}
+	return fmt.Sprintf("ret%d", n-1)
+}
+
+// addProxyErrorResult makes proxy method return error of the call as the last result. If the method already
+// returns error as the last result then it's reused for errors of the call, otherwise error is appended.
+func (pf *ParsedFile) addProxyErrorResult(list *ast.FieldList, info map[string]string) {
+	pf.addErrorResult(list, info, "proxyctx.Current.MakeErrorSerializable(%s)", "proxyctx.Current.MakeErrorSerializable(err)")
+}
+
+// addErrorResult is addProxyErrorResult with error results converted by `convertErr` format
+// and `callErr` returned as the error of the call
+func (pf *ParsedFile) addErrorResult(list *ast.FieldList, info map[string]string, convertErr string, callErr string) {
+	var results []string
+	errIndexes := typeIndexes(pf, list, "error")
+	n := 0
+	if list != nil {
+		n = list.NumFields()
+	}
+	if errResult := lastErrorResult(pf, list); errResult != "" {
+		info["ErrorResult"] = errResult
+		n--
+	} else if info["ResultsTypes"] == "" {
+		info["ResultsTypes"] = "error"
+	} else {
+		info["ResultsTypes"] += ", error"
+	}
+
+	for i := 0; i < n; i++ {
+		v := fmt.Sprintf("ret%d", i)
+		for _, e := range errIndexes {
+			if e == i {
+				v = fmt.Sprintf(convertErr, v)
+			}
+		}
+		results = append(results, v)
+	}
+	info["ResultsWithErr"] = strings.Join(append(results, callErr), ", ")
+}
+
 // ChangePackageToMain changes package of the parsed code to "main"
 func (pf *ParsedFile) ChangePackageToMain() {
 	pf.node.Name.Name = "main"
@@ -327,6 +458,17 @@ func (pf *ParsedFile) generateImports(wrapper bool) map[string]bool {
 	return imports
 }
 
+func (pf *ParsedFile) generateClientImports() map[string]bool {
+	imports := make(map[string]bool)
+	imports[fmt.Sprintf(`"%s"`, corePath)] = true
+	imports[fmt.Sprintf(`"%s"`, foundationPath)] = true
+	for _, method := range pf.methods[pf.contract] {
+		extendImportsMap(pf, method.Type.Params, imports)
+		extendImportsMap(pf, method.Type.Results, imports)
+	}
+	return imports
+}
+
 func openTemplate(fileName string) (*template.Template, error) {
 	_, currentFile, _, ok := runtime.Caller(0)
 	if !ok {
@@ -346,7 +488,7 @@ func numberedVars(list *ast.FieldList, name string) string {
 	}
 
 	rets := make([]string, list.NumFields())
-	for i := range list.List {
+	for i := range expandFields(list) {
 		rets[i] = fmt.Sprintf("%s%d", name, i)
 	}
 	return strings.Join(rets, ", ")
@@ -358,7 +500,7 @@ func typeIndexes(parsed *ParsedFile, list *ast.FieldList, t string) []int {
 	}
 
 	rets := []int{}
-	for i, e := range list.List {
+	for i, e := range expandFields(list) {
 		if parsed.codeOfNode(e.Type) == t {
 			rets = append(rets, i)
 		}
@@ -457,7 +599,7 @@ func generateZeroListOfTypes(parsed *ParsedFile, name string, list *ast.FieldLis
 
 	text := fmt.Sprintf("%s := [%d]interface{}{}\n", name, list.NumFields())
 
-	for i, arg := range list.List {
+	for i, arg := range expandFields(list) {
 		tname := parsed.codeOfNode(arg.Type)
 		if tname == "error" {
 			tname = "*foundation.Error"
@@ -475,7 +617,7 @@ func genFieldList(parsed *ParsedFile, params *ast.FieldList, withNames bool) str
 	if params == nil {
 		return res
 	}
-	for i, e := range params.List {
+	for i, e := range expandFields(params) {
 		if i > 0 {
 			res += ", "
 		}
@@ -487,10 +629,40 @@ func genFieldList(parsed *ParsedFile, params *ast.FieldList, withNames bool) str
 	return res
 }
 
+// expandFields returns a field for every name in the list, so fields can be counted like list.NumFields() does.
+// Unnamed and blank parameters are named by their position, generated code passes them to other calls.
+func expandFields(list *ast.FieldList) []*ast.Field {
+	var res []*ast.Field
+	if list == nil {
+		return res
+	}
+	for _, field := range list.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("_")}
+		}
+		for _, name := range names {
+			if name.Name == "_" {
+				name = ast.NewIdent(fmt.Sprintf("arg%d", len(res)))
+			}
+			res = append(res, &ast.Field{Names: []*ast.Ident{name}, Type: field.Type})
+		}
+	}
+	return res
+}
+
+func argumentsNames(list *ast.FieldList) string {
+	var names []string
+	for _, arg := range expandFields(list) {
+		names = append(names, arg.Names[0].Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 func generateInitArguments(list *ast.FieldList) string {
 	initArgs := ""
 	initArgs += fmt.Sprintf("var args [%d]interface{}\n", list.NumFields())
-	for i, arg := range list.List {
+	for i, arg := range expandFields(list) {
 		initArgs += fmt.Sprintf("\targs[%d] = %s\n", i, arg.Names[0].Name)
 	}
 	return initArgs
@@ -271,6 +271,247 @@ func ( A ) Get(){
 	assert.Contains(t, bufWrapper.String(), "    self.Get(  )")
 }
 
+func TestProxyReturnsError(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	testContract := "/test.go"
+	err = goplugintestutils.WriteFile(tmpDir, testContract, `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type A struct{
+	foundation.BaseContract
+}
+
+func ( A ) Get() int {
+	return 1
+}
+
+func ( A ) Check() (int, error) {
+	return 1, nil
+}
+
+func ( A ) Do() {
+}
+`)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(tmpDir + testContract)
+	assert.NoError(t, err)
+
+	var bufProxy bytes.Buffer
+	err = parsed.WriteProxy("testRef", &bufProxy)
+	assert.NoError(t, err)
+	proxy := bufProxy.String()
+	assert.Contains(t, proxy, "func (r *A) Get() (int, error) {")
+	assert.Contains(t, proxy, "func (r *A) Check() (int, error) {")
+	assert.Contains(t, proxy, "if err == nil && ret1 != nil {")
+	assert.Contains(t, proxy, "func (r *A) Do() error {")
+	assert.Contains(t, proxy, "func (r *A) DoNoWait() error {")
+	assert.Contains(t, proxy, "func GetImplementationFrom(object core.RecordRef) (*A, error) {")
+	assert.NotContains(t, proxy, "panic(")
+}
+
+func TestClientGeneration(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	testContract := "/test.go"
+	err = goplugintestutils.WriteFile(tmpDir, testContract, `
+package main
+
+import (
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
+	"some/test/import/path"
+)
+
+type A struct{
+	foundation.BaseContract
+}
+
+func NewA() (*A, error) {
+	return &A{}, nil
+}
+
+func ( A ) Get(i path.SomeType) int {
+	return 1
+}
+
+// ins:immutable
+func ( A ) Check(s string, n int) (int, error) {
+	return 1, nil
+}
+
+func ( A ) Do() (error, bool) {
+	return nil, false
+}
+
+func ( A ) Move(from, to string) bool {
+	return true
+}
+
+func ( A ) Put(string, int) bool {
+	return true
+}
+`)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(tmpDir + testContract)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = parsed.WriteClient(&buf)
+	assert.NoError(t, err)
+	client := buf.String()
+	assert.Contains(t, client, `"some/test/import/path"`)
+	assert.Contains(t, client, "func NewA(caller Caller, ref core.RecordRef) *A {")
+	assert.Contains(t, client, "func (c *A) Get(i path.SomeType) (int, error) {")
+	assert.Contains(t, client, "func (c *A) Check(s string, n int) (int, error) {")
+	assert.Contains(t, client, "core.MarshalArgs(s, n)")
+	assert.Contains(t, client, `c.caller.CallMethod(c.Reference, true, "Check", args)`)
+	assert.Contains(t, client, "if err == nil && ret1 != nil {")
+	assert.Contains(t, client, "func (c *A) Do() (error, bool, error) {")
+	assert.Contains(t, client, "return toError(ret0), ret1, err")
+	assert.Contains(t, client, "func (c *A) Move(from string, to string) (bool, error) {")
+	assert.Contains(t, client, "core.MarshalArgs(from, to)")
+	assert.Contains(t, client, "func (c *A) Put(arg0 string, arg1 int) (bool, error) {")
+	assert.Contains(t, client, "core.MarshalArgs(arg0, arg1)")
+	assert.NotContains(t, client, "proxyctx")
+}
+
+func TestABI(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	testContract := "/test.go"
+	err = goplugintestutils.WriteFile(tmpDir, testContract, `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type A struct{
+	foundation.BaseContract
+}
+
+func NewA(name string) *A {
+	return &A{}
+}
+
+// ins:immutable
+func ( A ) Get(from, to int) (int, error) {
+	return 1, nil
+}
+
+func ( A ) Do() {
+}
+`)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(tmpDir + testContract)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &ABI{
+		Contract: "A",
+		Constructors: []ABIMethod{{
+			Name:      "NewA",
+			Arguments: []ABIField{{Name: "name", Type: "string"}},
+			Results:   []ABIField{{Type: "*A"}},
+		}},
+		Methods: []ABIMethod{{
+			Name:      "Get",
+			Arguments: []ABIField{{Name: "from", Type: "int"}, {Name: "to", Type: "int"}},
+			Results:   []ABIField{{Type: "int"}, {Type: "error"}},
+			Immutable: true,
+		}, {
+			Name:      "Do",
+			Arguments: []ABIField{},
+			Results:   []ABIField{},
+		}},
+	}, parsed.ABI())
+
+	var buf bytes.Buffer
+	err = parsed.WriteABI(&buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"name": "NewA"`)
+}
+
+func TestProxyImmutableMethod(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	testContract := "/test.go"
+	err = goplugintestutils.WriteFile(tmpDir, testContract, `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type A struct{
+	foundation.BaseContract
+}
+
+// Get returns value
+// ins:immutable
+func ( A ) Get() int {
+	return 1
+}
+
+func ( A ) Set() {
+}
+`)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(tmpDir + testContract)
+	assert.NoError(t, err)
+
+	var bufProxy bytes.Buffer
+	err = parsed.WriteProxy("testRef", &bufProxy)
+	assert.NoError(t, err)
+	assert.Contains(t, bufProxy.String(), `RouteCall(r.Reference, true, true, "Get", argsSerialized)`)
+	assert.Contains(t, bufProxy.String(), `RouteCall(r.Reference, true, false, "Set", argsSerialized)`)
+}
+
+func TestWrapperDiscardsStateOnError(t *testing.T) {
+	t.Parallel()
+	tmpDir, err := ioutil.TempDir("", "test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	testContract := "/test.go"
+	err = goplugintestutils.WriteFile(tmpDir, testContract, `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type A struct{
+	foundation.BaseContract
+}
+
+func ( A ) Check() (int, error) {
+	return 1, nil
+}
+`)
+	assert.NoError(t, err)
+
+	parsed, err := ParseFile(tmpDir + testContract)
+	assert.NoError(t, err)
+
+	var bufWrapper bytes.Buffer
+	err = parsed.WriteWrapper(&bufWrapper)
+	assert.NoError(t, err)
+	assert.Contains(t, bufWrapper.String(), "if ret1 != nil {")
+	assert.Contains(t, bufWrapper.String(), "state = nil")
+}
+
 func TestFailIfThereAreNoContract(t *testing.T) {
 	t.Parallel()
 	tmpDir, err := ioutil.TempDir("", "test-")
@@ -0,0 +1,65 @@
+package {{ .PackageName }}
+
+import (
+	{{- range $import, $i := .Imports }}
+		{{$import}}
+	{{- end }}
+)
+
+{{ range $typeStruct := .Types }}
+	{{- $typeStruct }}
+{{ end }}
+
+// Caller sends call of contract's method to the network and returns serialized results
+type Caller interface {
+	CallMethod(ref core.RecordRef, immutable bool, method string, args core.Arguments) ([]byte, error)
+}
+
+// {{ .ContractType }} is typed client of the contract's object
+type {{ .ContractType }} struct {
+	Reference core.RecordRef
+	caller    Caller
+}
+
+// New{{ .ContractType }} returns client of the object calling it with the caller
+func New{{ .ContractType }}(caller Caller, ref core.RecordRef) *{{ .ContractType }} {
+	return &{{ .ContractType }}{Reference: ref, caller: caller}
+}
+
+// GetReference returns reference of the object
+func (c *{{ .ContractType }}) GetReference() core.RecordRef {
+	return c.Reference
+}
+
+{{ range $method := .Methods }}
+// {{ $method.Name }} calls {{ $method.Name }} method of the object
+func (c *{{ $.ContractType }}) {{ $method.Name }}( {{ $method.Arguments }} ) ( {{ $method.ResultsTypes }} ) {
+	{{ $method.ResultZeroList }}
+
+	args, err := core.MarshalArgs({{ $method.ArgumentsNames }})
+	if err != nil {
+		return {{ $method.ResultsWithErr }}
+	}
+
+	res, err := c.caller.CallMethod(c.Reference, {{ $method.Immutable }}, "{{ $method.Name }}", args)
+	if err != nil {
+		return {{ $method.ResultsWithErr }}
+	}
+
+	_, err = core.UnMarshalResponse(res, ret[:])
+	{{- if $method.ErrorResult }}
+	if err == nil && {{ $method.ErrorResult }} != nil {
+		err = {{ $method.ErrorResult }}
+	}
+	{{- end }}
+	return {{ $method.ResultsWithErr }}
+}
+{{ end }}
+
+// toError converts error returned by contract to error interface keeping nil as nil
+func toError(err *foundation.Error) error {
+	if err == nil {
+		return nil
+	}
+	return err
+}
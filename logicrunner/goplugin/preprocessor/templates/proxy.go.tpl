@@ -22,24 +22,31 @@ type {{ .ContractType }} struct {
 type ContractConstructorHolder struct {
 	constructorName string
 	argsSerialized []byte
+	err error
 }
 
 // AsChild saves object as child
-func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) *{{ .ContractType }} {
+func (r *ContractConstructorHolder) AsChild(objRef core.RecordRef) (*{{ .ContractType }}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsChild(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-	panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &{{ .ContractType }}{Reference: ref}
+	return &{{ .ContractType }}{Reference: ref}, nil
 }
 
 // AsDelegate saves object as delegate
-func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) *{{ .ContractType }} {
+func (r *ContractConstructorHolder) AsDelegate(objRef core.RecordRef) (*{{ .ContractType }}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
 	ref, err := proxyctx.Current.SaveAsDelegate(objRef, ClassReference, r.constructorName, r.argsSerialized)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return &{{ .ContractType }}{Reference: ref}
+	return &{{ .ContractType }}{Reference: ref}, nil
 }
 
 // GetObject returns proxy object
@@ -53,12 +60,12 @@ func GetClass() core.RecordRef {
 }
 
 // GetImplementationFrom returns proxy to delegate of given type
-func GetImplementationFrom(object core.RecordRef) *{{ .ContractType }} {
+func GetImplementationFrom(object core.RecordRef) (*{{ .ContractType }}, error) {
 	ref, err := proxyctx.Current.GetDelegate(object, ClassReference)
 	if err != nil {
-		panic(err)
+		return nil, proxyctx.Current.MakeErrorSerializable(err)
 	}
-	return GetObject(ref)
+	return GetObject(ref), nil
 }
 
 {{ range $func := .ConstructorsProxies }}
@@ -69,7 +76,7 @@ func {{ $func.Name }}( {{ $func.Arguments }} ) *ContractConstructorHolder {
 	var argsSerialized []byte
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return &ContractConstructorHolder{err: proxyctx.Current.MakeErrorSerializable(err)}
 	}
 
 	return &ContractConstructorHolder{constructorName: "{{ $func.Name }}", argsSerialized: argsSerialized}
@@ -92,38 +99,38 @@ func (r *{{ $.ContractType }}) {{ $method.Name }}( {{ $method.Arguments }} ) ( {
 	{{ $method.InitArgs }}
 	var argsSerialized []byte
 
+	{{ $method.ResultZeroList }}
+
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return {{ $method.ResultsWithErr }}
 	}
 
-	res, err := proxyctx.Current.RouteCall(r.Reference, true, "{{ $method.Name }}", argsSerialized)
+	res, err := proxyctx.Current.RouteCall(r.Reference, true, {{ $method.Immutable }}, "{{ $method.Name }}", argsSerialized)
 	if err != nil {
-   		panic(err)
+		return {{ $method.ResultsWithErr }}
 	}
 
-	{{ $method.ResultZeroList }}
 	err = proxyctx.Current.Deserialize(res, &ret)
-	if err != nil {
-		panic(err)
+	{{- if $method.ErrorResult }}
+	if err == nil && {{ $method.ErrorResult }} != nil {
+		err = {{ $method.ErrorResult }}
 	}
-
-	return {{ $method.Results }}
+	{{- end }}
+	return {{ $method.ResultsWithErr }}
 }
 
 // {{ $method.Name }}NoWait is proxy generated method
-func (r *{{ $.ContractType }}) {{ $method.Name }}NoWait( {{ $method.Arguments }} ) {
+func (r *{{ $.ContractType }}) {{ $method.Name }}NoWait( {{ $method.Arguments }} ) error {
 	{{ $method.InitArgs }}
 	var argsSerialized []byte
 
 	err := proxyctx.Current.Serialize(args, &argsSerialized)
 	if err != nil {
-		panic(err)
+		return proxyctx.Current.MakeErrorSerializable(err)
 	}
 
-	_, err = proxyctx.Current.RouteCall(r.Reference, false, "{{ $method.Name }}", argsSerialized)
-	if err != nil {
-		panic(err)
-	}
+	_, err = proxyctx.Current.RouteCall(r.Reference, false, {{ $method.Immutable }}, "{{ $method.Name }}", argsSerialized)
+	return proxyctx.Current.MakeErrorSerializable(err)
 }
 {{ end }}
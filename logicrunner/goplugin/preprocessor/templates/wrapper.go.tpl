@@ -34,7 +34,12 @@ func INSMETHOD_{{ $method.Name }}(object []byte, data []byte) ([]byte, []byte, e
     if err != nil {
         return nil, nil, err
     }
-
+{{ if $method.ErrorResult }}
+    if {{ $method.ErrorResult }} != nil {
+        // method failed, changes of the call are discarded
+        state = nil
+    }
+{{ end }}
 {{ range $i := $method.ErrorInterfaceInRes }}
     ret{{ $i }} = ph.MakeErrorSerializable(ret{{ $i }})
 {{ end }}
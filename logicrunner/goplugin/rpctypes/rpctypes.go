@@ -35,8 +35,10 @@ type DownCallMethodReq struct { // todo it may use foundation.Context
 
 // DownCallMethodResp is response from CallMethod RPC in the runner
 type DownCallMethodResp struct {
-	Data []byte
-	Ret  core.Arguments
+	Data    []byte
+	Ret     core.Arguments
+	Usage   core.CallUsage
+	Aborted bool // method returned error and its changes are discarded
 }
 
 // DownCallConstructorReq is a set of arguments for CallConstructor RPC
@@ -52,9 +54,24 @@ type DownCallConstructorResp struct {
 	Ret core.Arguments
 }
 
+// DownPingReq is a set of arguments for Ping RPC in the runner
+type DownPingReq struct {
+	Nonce uint64
+}
+
+// DownPingResp is response from Ping RPC in the runner
+type DownPingResp struct {
+	Nonce uint64
+}
+
 // UpBaseReq  is a base type for all insgorund -> logicrunner requests
 type UpBaseReq struct {
-	Me core.RecordRef
+	Me        core.RecordRef
+	Depth     int
+	Immutable bool // request is made by immutable method
+	// Trace is a trace of the call making request, it's not hashed into case records as root of the trace is
+	// unique on every node executing the call
+	Trace core.CallTrace `codec:"-"`
 }
 
 // UpRespIface interface for UpBaseReq descendant responses
@@ -72,13 +89,25 @@ type UpGetCodeResp struct {
 	Code []byte
 }
 
+// UpGetCodeHashReq is a set of arguments for GetCodeHash RPC in goplugin
+type UpGetCodeHashReq struct {
+	UpBaseReq
+	Code core.RecordRef
+}
+
+// UpGetCodeHashResp is response from GetCodeHash RPC in goplugin
+type UpGetCodeHashResp struct {
+	Hash []byte
+}
+
 // UpRouteReq is a set of arguments for Send RPC in goplugin
 type UpRouteReq struct {
 	UpBaseReq
-	Wait      bool
-	Object    core.RecordRef
-	Method    string
-	Arguments core.Arguments
+	Wait            bool
+	ImmutableMethod bool
+	Object          core.RecordRef
+	Method          string
+	Arguments       core.Arguments
 }
 
 // UpRouteResp is response from Send RPC in goplugin
@@ -147,3 +176,14 @@ type UpDeactivateObjectReq struct {
 // UpDeactivateObjectResp is response from DeactivateObject RPC in goplugin
 type UpDeactivateObjectResp struct {
 }
+
+// UpEmitReq is a set of arguments for Emit RPC in goplugin
+type UpEmitReq struct {
+	UpBaseReq
+	Name    string
+	Payload []byte
+}
+
+// UpEmitResp is response from Emit RPC in goplugin
+type UpEmitResp struct {
+}
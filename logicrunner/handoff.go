@@ -0,0 +1,380 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/log"
+	"github.com/pkg/errors"
+)
+
+// acquireContext waits until the object is free to execute the message of the call tree with the root. Messages for
+// the same object are executed in FIFO order, ErrQueueIsFull is returned if too many messages are already waiting
+// and ErrQueueTimeout if the message waits too long. False is returned if executor role of the object moved to
+// another node while message was waiting, message should be sent to the new executor. Immutable calls are executed
+// concurrently unless mutable call is running or waiting. Object changed by a call tree is held by it until the tree
+// is completed, only calls of the same tree are executed meanwhile. The tree can't call the object it's already
+// executing, ErrReentrantCall is returned as the call would wait for itself.
+func (lr *LogicRunner) acquireContext(ref Ref, msg *message.CallMethod, root core.TraceStep) (bool, error) {
+	lr.contextMutex.Lock()
+	ec, ok := lr.context[ref]
+	if !ok {
+		ec = &ExecutionContext{}
+		lr.context[ref] = ec
+		ec.start(msg)
+		ec.enter(root)
+		lr.contextMutex.Unlock()
+		return true, nil
+	}
+	if ec.moved {
+		lr.contextMutex.Unlock()
+		return false, nil
+	}
+	if ec.trees[root] > 0 {
+		lr.contextMutex.Unlock()
+		return false, ErrReentrantCall
+	}
+	if ec.held != nil && *ec.held == root && !ec.running && ec.readers == 0 {
+		ec.start(msg)
+		ec.enter(root)
+		lr.contextMutex.Unlock()
+		return true, nil
+	}
+	if isImmutable(msg) && ec.readers > 0 && ec.held == nil && !ec.awaiting && len(ec.Queue) == 0 {
+		ec.start(msg)
+		ec.enter(root)
+		lr.contextMutex.Unlock()
+		return true, nil
+	}
+	if len(ec.Queue) >= lr.Cfg.QueueLength {
+		lr.contextMutex.Unlock()
+		return false, ErrQueueIsFull
+	}
+	ready := make(chan bool, 1)
+	ec.Queue = append(ec.Queue, msg)
+	ec.ready = append(ec.ready, ready)
+	lr.contextMutex.Unlock()
+
+	return lr.waitContext(ec, ready, root)
+}
+
+// waitContext waits until the queued message is started or handed off, the message is removed from the queue on
+// timeout. Trees holding objects may wait for each other, the timeout breaks such deadlocks.
+func (lr *LogicRunner) waitContext(ec *ExecutionContext, ready chan bool, root core.TraceStep) (bool, error) {
+	var timeout <-chan time.Time
+	if lr.Cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(time.Duration(lr.Cfg.QueueTimeout) * time.Millisecond)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case executeHere := <-ready:
+		if executeHere {
+			lr.contextMutex.Lock()
+			ec.enter(root)
+			lr.contextMutex.Unlock()
+		}
+		return executeHere, nil
+	case <-timeout:
+	}
+
+	lr.contextMutex.Lock()
+	defer lr.contextMutex.Unlock()
+	for i := range ec.ready {
+		if ec.ready[i] == ready {
+			ec.Queue = append(ec.Queue[:i:i], ec.Queue[i+1:]...)
+			ec.ready = append(ec.ready[:i:i], ec.ready[i+1:]...)
+			return false, ErrQueueTimeout
+		}
+	}
+	// message was started or handed off right before the timeout
+	executeHere := <-ready
+	if executeHere {
+		ec.enter(root)
+	}
+	return executeHere, nil
+}
+
+// releaseContext passes the object to the next queued message or frees it if the queue is empty.
+func (lr *LogicRunner) releaseContext(ref Ref, msg core.Message, root core.TraceStep) {
+	lr.contextMutex.Lock()
+	ec := lr.context[ref]
+	if isImmutable(msg) {
+		ec.readers--
+	} else {
+		ec.running = false
+	}
+	ec.leave(root)
+	completed := lr.nextInContext(ref, ec)
+	lr.contextMutex.Unlock()
+
+	if completed {
+		lr.sendExecutionCompleted(ref)
+	}
+}
+
+// nextInContext starts the next queued message if the object is free. Context is deleted if the queue is empty,
+// true is returned if the new executor should be notified about completion. Context mutex should be locked.
+func (lr *LogicRunner) nextInContext(ref Ref, ec *ExecutionContext) bool {
+	if ec.running || ec.awaiting || ec.held != nil {
+		return false
+	}
+	for len(ec.Queue) > 0 && (ec.readers == 0 || isImmutable(ec.Queue[0])) {
+		ready := ec.ready[0]
+		ec.start(ec.Queue[0])
+		ec.Queue = ec.Queue[1:]
+		ec.ready = ec.ready[1:]
+		ready <- true
+		if ec.running {
+			return false
+		}
+	}
+	if ec.readers > 0 {
+		return false
+	}
+	delete(lr.context, ref)
+	return ec.moved
+}
+
+// start marks the message as being executed.
+func (ec *ExecutionContext) start(msg core.Message) {
+	if isImmutable(msg) {
+		ec.readers++
+	} else {
+		ec.running = true
+	}
+}
+
+// enter marks the object as being executed by the call tree with the root.
+func (ec *ExecutionContext) enter(root core.TraceStep) {
+	if ec.trees == nil {
+		ec.trees = make(map[core.TraceStep]int)
+	}
+	ec.trees[root]++
+}
+
+// leave marks the call of the tree with the root as completed.
+func (ec *ExecutionContext) leave(root core.TraceStep) {
+	ec.trees[root]--
+	if ec.trees[root] == 0 {
+		delete(ec.trees, root)
+	}
+}
+
+// isImmutable checks if the message is a call of immutable method.
+func isImmutable(msg core.Message) bool {
+	m, ok := msg.(*message.CallMethod)
+	return ok && m.Immutable
+}
+
+func (lr *LogicRunner) sendExecutionCompleted(ref Ref) {
+	_, err := lr.MessageBus.Send(&message.ExecutionCompleted{RecordRef: ref})
+	if err != nil {
+		log.Errorf("failed to notify new executor of %s about completed execution: %s", ref, err)
+	}
+}
+
+// executorMoved checks if executor role of the object moved to another node on pulse change.
+func (lr *LogicRunner) executorMoved(ref Ref, oldPulse, pulse core.PulseNumber) (bool, error) {
+	oldExecutors, err := lr.JetCoordinator.QueryRole(core.RoleVirtualExecutor, ref, oldPulse)
+	if err != nil {
+		return false, err
+	}
+	executors, err := lr.JetCoordinator.QueryRole(core.RoleVirtualExecutor, ref, pulse)
+	if err != nil {
+		return false, err
+	}
+	if len(oldExecutors) == 0 || len(executors) == 0 {
+		return false, errors.Errorf("no executors for %s", ref)
+	}
+	return !oldExecutors[0].Equal(executors[0]), nil
+}
+
+// checkPreviousExecutor returns error if the sender wasn't Executor of the object on the previous pulse. Message can
+// arrive before this node switched to the new pulse, so Executor of the current pulse is accepted too.
+func (lr *LogicRunner) checkPreviousExecutor(ref Ref, sender core.RecordRef) error {
+	lr.caseBindMutex.Lock()
+	pulses := []core.PulseNumber{lr.prevPulse, lr.caseBind.Pulse.PulseNumber}
+	lr.caseBindMutex.Unlock()
+
+	for _, pulse := range pulses {
+		if pulse == 0 {
+			continue
+		}
+		authorized, err := lr.JetCoordinator.IsAuthorized(core.RoleVirtualExecutor, ref, pulse, sender)
+		if err != nil {
+			return errors.Wrap(err, "failed to check sender")
+		}
+		if authorized {
+			return nil
+		}
+	}
+	return errors.Errorf("%s wasn't executor of %s on the previous pulse", sender, ref)
+}
+
+// handOffContexts notifies new executors of objects which executor role moved to another node. The handoff carries
+// no execution context, messages queued here are re-sent to the new executors by their callers. Objects which are
+// still executed by this node are carried over to the new pulse with their queued messages.
+// Objects awaiting previous executor for the whole pulse are resumed, so lost ExecutionCompleted doesn't block them.
+// Objects held by a call tree for the whole pulse are reverted and released, so lost ApplyJournal doesn't block them.
+func (lr *LogicRunner) handOffContexts(oldPulse, pulse core.PulseNumber) error {
+	if oldPulse == 0 {
+		return nil
+	}
+
+	lr.contextMutex.Lock()
+	refs := make([]Ref, 0, len(lr.context))
+	var resumed []Ref
+	expired := make(map[Ref]core.TraceStep)
+	for ref, ec := range lr.context {
+		if ec.awaiting && ec.awaitedPulse < oldPulse {
+			log.Warnf("previous executor of %s didn't complete execution in time", ref)
+			ec.awaiting = false
+			if lr.nextInContext(ref, ec) {
+				resumed = append(resumed, ref)
+			}
+		}
+		if ec.held != nil && ec.heldPulse < oldPulse && ec.trees[*ec.held] == 0 {
+			expired[ref] = *ec.held
+		}
+		if !ec.moved {
+			refs = append(refs, ref)
+		}
+	}
+	lr.contextMutex.Unlock()
+
+	for _, ref := range resumed {
+		lr.sendExecutionCompleted(ref)
+	}
+	for ref, root := range expired {
+		log.Warnf("call tree holding %s didn't complete in time, its changes are reverted", ref)
+		if err := lr.revertJournal(ref, core.CallTrace{root}); err != nil {
+			log.Errorf("failed to revert changes of %s: %s", ref, err)
+			lr.releaseHeld(ref, core.CallTrace{root})
+		}
+	}
+
+	for _, ref := range refs {
+		moved, err := lr.executorMoved(ref, oldPulse, pulse)
+		if err != nil {
+			return errors.Wrap(err, "failed to check executor of the object")
+		}
+
+		lr.contextMutex.Lock()
+		ec, ok := lr.context[ref]
+		if !ok {
+			lr.contextMutex.Unlock()
+			continue
+		}
+		if !moved {
+			lr.contextMutex.Unlock()
+			continue
+		}
+		handoff := &message.ExecutorHandoff{RecordRef: ref, TraceID: ec.TraceID}
+		lr.contextMutex.Unlock()
+
+		// handoff is sent before queued messages, so the new executor holds them until execution is completed here
+		_, err = lr.MessageBus.Send(handoff)
+		if err != nil {
+			log.Errorf("failed to hand off execution context of %s: %s", ref, err)
+		}
+
+		lr.contextMutex.Lock()
+		ec, ok = lr.context[ref]
+		if !ok {
+			// execution is completed while handoff was being sent
+			lr.contextMutex.Unlock()
+			lr.sendExecutionCompleted(ref)
+			continue
+		}
+		ec.moved = true
+		queued := ec.ready
+		ec.Queue = nil
+		ec.ready = nil
+		completed := lr.nextInContext(ref, ec)
+		lr.contextMutex.Unlock()
+
+		for _, ready := range queued {
+			ready <- false
+		}
+		if completed {
+			lr.sendExecutionCompleted(ref)
+		}
+	}
+	return nil
+}
+
+// ExecutorHandoff makes the object pending until previous executor completes its execution. Messages for the object
+// are queued meanwhile, the object is resumed on the next pulse if the previous executor doesn't complete in time.
+// Handoff is accepted only from the Executor of the object on the previous pulse.
+func (lr *LogicRunner) ExecutorHandoff(signed core.SignedMessage) (core.Reply, error) {
+	msg, ok := signed.Message().(*message.ExecutorHandoff)
+	if !ok {
+		return nil, errors.New("ExecutorHandoff( ! message.ExecutorHandoff )")
+	}
+	if err := lr.checkPreviousExecutor(msg.RecordRef, signed.GetSender()); err != nil {
+		return nil, err
+	}
+
+	lr.caseBindMutex.Lock()
+	pulse := lr.caseBind.Pulse.PulseNumber
+	lr.caseBindMutex.Unlock()
+
+	lr.contextMutex.Lock()
+	defer lr.contextMutex.Unlock()
+	ec, ok := lr.context[msg.RecordRef]
+	if !ok {
+		ec = &ExecutionContext{}
+		lr.context[msg.RecordRef] = ec
+	}
+	ec.awaiting = true
+	ec.awaitedPulse = pulse
+	ec.TraceID = msg.TraceID
+	return &reply.OK{}, nil
+}
+
+// ExecutionCompleted resumes execution of the pending object. It's accepted only from the Executor of the object on
+// the previous pulse.
+func (lr *LogicRunner) ExecutionCompleted(signed core.SignedMessage) (core.Reply, error) {
+	msg, ok := signed.Message().(*message.ExecutionCompleted)
+	if !ok {
+		return nil, errors.New("ExecutionCompleted( ! message.ExecutionCompleted )")
+	}
+	if err := lr.checkPreviousExecutor(msg.RecordRef, signed.GetSender()); err != nil {
+		return nil, err
+	}
+
+	lr.contextMutex.Lock()
+	ec, ok := lr.context[msg.RecordRef]
+	if !ok || !ec.awaiting {
+		lr.contextMutex.Unlock()
+		return &reply.OK{}, nil
+	}
+	ec.awaiting = false
+	completed := lr.nextInContext(msg.RecordRef, ec)
+	lr.contextMutex.Unlock()
+
+	if completed {
+		lr.sendExecutionCompleted(msg.RecordRef)
+	}
+	return &reply.OK{}, nil
+}
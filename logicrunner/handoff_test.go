@@ -0,0 +1,179 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/logicrunner/goplugin/goplugintestutils"
+	"github.com/insolar/insolar/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handoffBus delivers messages of the previous executor to the new one.
+type handoffBus struct {
+	from core.RecordRef
+	to   *LogicRunner
+}
+
+func (b *handoffBus) Send(msg core.Message) (core.Reply, error) {
+	switch m := msg.(type) {
+	case *message.ExecutorHandoff:
+		return b.to.ExecutorHandoff(&message.SignedMessage{Msg: m, Sender: b.from})
+	case *message.ExecutionCompleted:
+		return b.to.ExecutionCompleted(&message.SignedMessage{Msg: m, Sender: b.from})
+	case *message.ApplyJournal:
+		return b.to.ApplyJournal(&message.SignedMessage{Msg: m, Sender: testNode})
+	}
+	return b.to.Execute(msg)
+}
+
+func (b *handoffBus) SendWithOptions(
+	ctx context.Context, msg core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return b.Send(msg)
+}
+
+func (b *handoffBus) SendAsync(msg core.Message)                                     {}
+func (b *handoffBus) Register(p core.MessageType, handler core.MessageHandler) error { return nil }
+func (b *handoffBus) MustRegister(p core.MessageType, handler core.MessageHandler)   {}
+
+func TestLogicRunner_HandOffContexts(t *testing.T) {
+	am := goplugintestutils.NewTestArtifactManager()
+	codeRef := core.NewRefFromBase58("someCode")
+	dataRef := core.NewRefFromBase58("someObject")
+	classRef := core.NewRefFromBase58("someClass")
+	am.Objects[dataRef] = &goplugintestutils.TestObjectDescriptor{
+		AM:    am,
+		Data:  []byte("origData"),
+		Code:  &codeRef,
+		Class: &classRef,
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	oldPulse := core.PulseNumber(core.FirstPulseNumber)
+	newPulse := oldPulse + 1
+	prevNode := testutils.RandomRef()
+	jc := &testJetCoordinator{executors: map[core.PulseNumber][]core.RecordRef{
+		oldPulse: {prevNode},
+		newPulse: {testutils.RandomRef()},
+	}}
+	newLR := func(bus core.MessageBus) (*LogicRunner, *blockingExecutor) {
+		lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 10})
+		require.NoError(t, err)
+		err = lr.Start(core.Components{Ledger: &testLedger{am: am, jc: jc}, MessageBus: bus})
+		require.NoError(t, err)
+		be := &blockingExecutor{started: make(chan string, 2), release: make(chan struct{})}
+		err = lr.RegisterExecutor(core.MachineTypeGoPlugin, be)
+		require.NoError(t, err)
+		return lr, be
+	}
+	next, nextExecutor := newLR(&testMessageBus{})
+	prev, prevExecutor := newLR(&handoffBus{from: prevNode, to: next})
+	next.refreshCaseBind(core.Pulse{PulseNumber: oldPulse})
+	next.refreshCaseBind(core.Pulse{PulseNumber: newPulse})
+
+	results := make(chan string, 2)
+	call := func(method string) {
+		resp, err := prev.Execute(&message.CallMethod{ObjectRef: dataRef, Method: method})
+		assert.NoError(t, err)
+		results <- string(resp.(*reply.CallMethod).Result)
+	}
+	go call("first")
+	assert.Equal(t, "first", <-prevExecutor.started)
+	go call("second")
+	waitQueue := func(lr *LogicRunner, length int) {
+		for {
+			ec, _ := lr.GetContext(dataRef)
+			if len(ec.Queue) == length {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitQueue(prev, 1)
+
+	err := prev.handOffContexts(oldPulse, newPulse)
+	require.NoError(t, err)
+	// queued message is re-sent to the new executor and waits there for completion on the previous one
+	waitQueue(next, 1)
+	ec, _ := next.GetContext(dataRef)
+	assert.True(t, ec.awaiting)
+
+	prevExecutor.release <- struct{}{}
+	assert.Equal(t, "first", <-results)
+	assert.Equal(t, "second", <-nextExecutor.started)
+	nextExecutor.release <- struct{}{}
+	assert.Equal(t, "second", <-results)
+
+	_, ok := prev.GetContext(dataRef)
+	assert.False(t, ok)
+	_, ok = next.GetContext(dataRef)
+	assert.False(t, ok)
+}
+
+func TestLogicRunner_ExecutorHandoff(t *testing.T) {
+	oldPulse := core.PulseNumber(core.FirstPulseNumber)
+	newPulse := oldPulse + 1
+	prevNode := testutils.RandomRef()
+	node := testutils.RandomRef()
+	jc := &testJetCoordinator{executors: map[core.PulseNumber][]core.RecordRef{
+		oldPulse:     {prevNode},
+		newPulse:     {node},
+		newPulse + 1: {node},
+		newPulse + 2: {node},
+	}}
+	lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 10})
+	require.NoError(t, err)
+	err = lr.Start(core.Components{Ledger: &testLedger{am: goplugintestutils.NewTestArtifactManager(), jc: jc}, MessageBus: &testMessageBus{}})
+	require.NoError(t, err)
+	lr.refreshCaseBind(core.Pulse{PulseNumber: oldPulse})
+	lr.refreshCaseBind(core.Pulse{PulseNumber: newPulse})
+
+	ref := testutils.RandomRef()
+	handoff := &message.ExecutorHandoff{RecordRef: ref}
+	_, err = lr.ExecutorHandoff(&message.SignedMessage{Msg: handoff, Sender: testutils.RandomRef()})
+	assert.Error(t, err, "handoff is accepted only from previous executor")
+	_, ok := lr.GetContext(ref)
+	assert.False(t, ok)
+
+	_, err = lr.ExecutorHandoff(&message.SignedMessage{Msg: handoff, Sender: prevNode})
+	require.NoError(t, err)
+	completed := &message.ExecutionCompleted{RecordRef: ref}
+	_, err = lr.ExecutionCompleted(&message.SignedMessage{Msg: completed, Sender: testutils.RandomRef()})
+	assert.Error(t, err, "completion is accepted only from previous executor")
+	ec, _ := lr.GetContext(ref)
+	assert.True(t, ec.awaiting)
+
+	// previous executor has the whole pulse to complete execution
+	err = lr.handOffContexts(newPulse, newPulse+1)
+	require.NoError(t, err)
+	ec, _ = lr.GetContext(ref)
+	assert.True(t, ec.awaiting)
+	err = lr.handOffContexts(newPulse+1, newPulse+2)
+	require.NoError(t, err)
+	_, ok = lr.GetContext(ref)
+	assert.False(t, ok, "object should be resumed when previous executor doesn't complete in time")
+}
@@ -0,0 +1,325 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/pkg/errors"
+)
+
+// All ledger changes made by one call tree are committed or reverted together. Objects called by the tree are held
+// by it until the root call is completed, so calls of other trees can't interleave with changes of the tree. Every
+// Executor keeps changes of its objects: updates and activations are stored immediately and compensated on revert,
+// deactivations and events are deferred until commit. Nested call reports objects it changed to the caller, root
+// call commits changes of the whole tree and failed call reverts changes of its nested calls. ApplyJournal message
+// only tells which call is completed, so Executors never apply changes received from other nodes. Calls without
+// waiting for result are roots of their own trees.
+
+// tracedEntry is a change of the object made by the call with the trace
+type tracedEntry struct {
+	core.JournalEntry
+	trace core.CallTrace
+}
+
+// callKey identifies a running call of the object. A call tree can't execute the object it's already executing, so
+// the object and the root of the tree distinguish concurrent calls of the object, e.g. immutable ones.
+type callKey struct {
+	object Ref
+	root   core.TraceStep
+}
+
+// callOf returns key of the call of the object with the trace, calls outside of call trees are keyed by the object
+func callOf(ref Ref, trace core.CallTrace) callKey {
+	call := callKey{object: ref}
+	if len(trace) > 0 {
+		call.root = trace[0]
+	}
+	return call
+}
+
+// callJournal collects changes made during the call of the object
+type callJournal struct {
+	entries []core.JournalEntry // deferred changes of the object
+	pending []Ref               // objects changed by successful nested calls
+}
+
+// methodTrace returns trace of the method call in its call tree, calls without waiting for result and calls made
+// outside of contracts start new trees. Root of a new tree gets unique nonce as external calls don't set it.
+func methodTrace(m *message.CallMethod) core.CallTrace {
+	step := core.TraceStep{Object: m.ObjectRef, Nonce: m.Nonce}
+	if m.ReturnMode == message.ReturnNoWait || len(m.Trace) == 0 {
+		step.Nonce = atomicLoadAndIncrementUint64(&serial)
+		return core.CallTrace{step}
+	}
+	return m.Trace.Extend(step)
+}
+
+// appendJournal adds changes made during the call of the object
+func (lr *LogicRunner) appendJournal(call callKey, entries ...core.JournalEntry) {
+	lr.journalMutex.Lock()
+	defer lr.journalMutex.Unlock()
+	j := lr.callJournal(call)
+	j.entries = append(j.entries, entries...)
+}
+
+// appendPending adds objects changed by the nested call of the call of the object
+func (lr *LogicRunner) appendPending(call callKey, pending ...Ref) {
+	lr.journalMutex.Lock()
+	defer lr.journalMutex.Unlock()
+	j := lr.callJournal(call)
+	for _, obj := range pending {
+		if !containsRef(j.pending, obj) {
+			j.pending = append(j.pending, obj)
+		}
+	}
+}
+
+// callJournal returns journal of the call of the object, journal mutex should be locked
+func (lr *LogicRunner) callJournal(call callKey) *callJournal {
+	j, ok := lr.journal[call]
+	if !ok {
+		j = &callJournal{}
+		lr.journal[call] = j
+	}
+	return j
+}
+
+// takeJournal returns changes made during the call of the object and forgets them
+func (lr *LogicRunner) takeJournal(call callKey) *callJournal {
+	lr.journalMutex.Lock()
+	defer lr.journalMutex.Unlock()
+	j, ok := lr.journal[call]
+	if !ok {
+		return &callJournal{}
+	}
+	delete(lr.journal, call)
+	return j
+}
+
+// keepJournal remembers changes of the object made by the call, the object is held by the call tree until changes
+// are committed or reverted or the holding expires
+func (lr *LogicRunner) keepJournal(ref Ref, trace core.CallTrace, entries ...core.JournalEntry) {
+	lr.caseBindMutex.Lock()
+	pulse := lr.caseBind.Pulse.PulseNumber
+	lr.caseBindMutex.Unlock()
+
+	lr.contextMutex.Lock()
+	defer lr.contextMutex.Unlock()
+	ec, ok := lr.context[ref]
+	if !ok {
+		// object is created by the call
+		ec = &ExecutionContext{}
+		lr.context[ref] = ec
+	}
+	root := trace[0]
+	if ec.held == nil || *ec.held != root {
+		ec.heldPulse = pulse
+	}
+	ec.held = &root
+	for _, entry := range entries {
+		ec.journal = append(ec.journal, tracedEntry{JournalEntry: entry, trace: trace})
+	}
+}
+
+// commitCall stores changes of the root call and sends commit to executors of other objects changed by the tree
+func (lr *LogicRunner) commitCall(trace core.CallTrace, journal *callJournal) error {
+	for _, entry := range journal.entries {
+		if err := lr.applyEntry(entry, true); err != nil {
+			return err
+		}
+	}
+	return lr.sendJournal(trace, journal.pending, true)
+}
+
+// revertCall reverts changes of the failed call made by its nested calls
+func (lr *LogicRunner) revertCall(vb ValidationBehaviour, trace core.CallTrace, journal *callJournal) error {
+	if !vb.NeedSave() {
+		return nil
+	}
+	return lr.sendJournal(trace, journal.pending, false)
+}
+
+// sendJournal tells executors of changed objects that the call is completed. Journal is sent to all of them even if
+// some sends fail, objects which executors didn't apply the journal are released when their holding expires.
+func (lr *LogicRunner) sendJournal(trace core.CallTrace, pending []Ref, commit bool) error {
+	var failed []string
+	for _, obj := range pending {
+		_, err := lr.MessageBus.Send(&message.ApplyJournal{RecordRef: obj, Trace: trace, Commit: commit})
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", obj, err))
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf(
+			"couldn't apply journal of %d of %d objects: %s", len(failed), len(pending), strings.Join(failed, "; "),
+		)
+	}
+	return nil
+}
+
+// ApplyJournal commits changes of the object made by the call tree or reverts changes made by the failed call of
+// the tree. Commit is accepted only from the Executor of the root call and revert only from the Executor of the
+// failed call. It's not queued with calls of the object as it's sent while the call tree is still executed.
+func (lr *LogicRunner) ApplyJournal(signed core.SignedMessage) (core.Reply, error) {
+	msg, ok := signed.Message().(*message.ApplyJournal)
+	if !ok {
+		return nil, errors.New("ApplyJournal( ! message.ApplyJournal )")
+	}
+	if len(msg.Trace) == 0 || (msg.Commit && len(msg.Trace) != 1) {
+		return nil, errors.New("journal can be committed only by the root call and reverted by any call")
+	}
+
+	caller := msg.Trace[len(msg.Trace)-1].Object
+	lr.caseBindMutex.Lock()
+	pulse := lr.caseBind.Pulse.PulseNumber
+	lr.caseBindMutex.Unlock()
+	authorized, err := lr.JetCoordinator.IsAuthorized(core.RoleVirtualExecutor, caller, pulse, signed.GetSender())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check sender of journal")
+	}
+	if !authorized {
+		return nil, errors.Errorf("journal of %s is applied not by executor of %s", msg.RecordRef, caller)
+	}
+
+	if msg.Commit {
+		err = lr.commitJournal(msg.RecordRef, msg.Trace)
+	} else {
+		err = lr.revertJournal(msg.RecordRef, msg.Trace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reply.OK{}, nil
+}
+
+// commitJournal stores deferred changes of the object made by the call tree and releases the object
+func (lr *LogicRunner) commitJournal(ref Ref, trace core.CallTrace) error {
+	journal := lr.heldJournal(ref, trace, false)
+	for _, entry := range journal {
+		if err := lr.applyEntry(entry.JournalEntry, true); err != nil {
+			return err
+		}
+	}
+	lr.releaseHeld(ref, trace)
+	return nil
+}
+
+// revertJournal reverts changes of the object made by the call and its nested calls, changes are reverted from the
+// latest. The object is released if the tree holding it has no more changes of it.
+func (lr *LogicRunner) revertJournal(ref Ref, trace core.CallTrace) error {
+	journal := lr.heldJournal(ref, trace, true)
+	for i := len(journal) - 1; i >= 0; i-- {
+		if err := lr.applyEntry(journal[i].JournalEntry, false); err != nil {
+			return err
+		}
+	}
+	lr.releaseHeld(ref, trace)
+	return nil
+}
+
+// heldJournal returns changes of the object made by the call with the trace and its nested calls, returned changes
+// are removed from the journal if `remove` is set
+func (lr *LogicRunner) heldJournal(ref Ref, trace core.CallTrace, remove bool) []tracedEntry {
+	lr.contextMutex.Lock()
+	defer lr.contextMutex.Unlock()
+	ec, ok := lr.context[ref]
+	if !ok || ec.held == nil || *ec.held != trace[0] {
+		return nil
+	}
+	var res, kept []tracedEntry
+	for _, entry := range ec.journal {
+		if entry.trace.HasPrefix(trace) {
+			res = append(res, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	if remove {
+		ec.journal = kept
+	}
+	return res
+}
+
+// releaseHeld releases the object held by the call tree if the call is the root of the tree or the tree has no more
+// changes of the object
+func (lr *LogicRunner) releaseHeld(ref Ref, trace core.CallTrace) {
+	lr.contextMutex.Lock()
+	ec, ok := lr.context[ref]
+	if !ok || ec.held == nil || *ec.held != trace[0] || (len(trace) > 1 && len(ec.journal) > 0) {
+		lr.contextMutex.Unlock()
+		return
+	}
+	ec.held = nil
+	ec.journal = nil
+	completed := lr.nextInContext(ref, ec)
+	lr.contextMutex.Unlock()
+
+	if completed {
+		lr.sendExecutionCompleted(ref)
+	}
+}
+
+// applyEntry stores deferred change on commit or compensates immediate change on revert
+func (lr *LogicRunner) applyEntry(entry core.JournalEntry, commit bool) error {
+	if isDeferred(entry) != commit {
+		// nothing to do, change is already stored or was never stored
+		return nil
+	}
+	am := lr.ArtifactManager
+	var err error
+	switch entry.Type {
+	case core.JournalEntryUpdate:
+		_, err = am.UpdateObject(core.RecordRef{}, core.RecordRef{}, entry.Object, entry.Memory)
+	case core.JournalEntryActivate, core.JournalEntryDeactivate:
+		_, err = am.DeactivateObject(core.RecordRef{}, core.RecordRef{}, entry.Object)
+	case core.JournalEntryEmit:
+		_, err = am.EmitEvent(core.RecordRef{}, core.RecordRef{}, entry.Object, entry.Name, entry.Payload)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "couldn't apply journal entry of type %d", entry.Type)
+	}
+	return nil
+}
+
+// isDeferred checks if the change is stored on commit, otherwise it's stored immediately and compensated on revert
+func isDeferred(entry core.JournalEntry) bool {
+	return entry.Type == core.JournalEntryDeactivate || entry.Type == core.JournalEntryEmit
+}
+
+// deactivates checks if the object is destroyed by changes of the call
+func deactivates(journal []core.JournalEntry, ref Ref) bool {
+	for _, entry := range journal {
+		if entry.Type == core.JournalEntryDeactivate && entry.Object == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRef(refs []Ref, ref Ref) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
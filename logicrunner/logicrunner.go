@@ -37,11 +37,39 @@ import (
 
 type Ref = core.RecordRef
 
+// ErrQueueIsFull is returned when too many calls are waiting for execution of the object.
+var ErrQueueIsFull = errors.New("object execution queue is full")
+
+// ErrQueueTimeout is returned when a call waits for execution of the object too long.
+var ErrQueueTimeout = errors.New("timeout of waiting for execution of the object")
+
+// ErrReentrantCall is returned when a call tree calls the object it's already executing.
+var ErrReentrantCall = errors.New("object is already executed by the same call tree")
+
+// ErrCallDepthExceeded is returned when nested calls are deeper than configured limit.
+var ErrCallDepthExceeded = errors.New("nested calls depth limit exceeded")
+
+// ErrImmutableCallChangesState is returned when immutable method tries to change state.
+var ErrImmutableCallChangesState = errors.New("immutable method can't change state")
+
 // Context of one contract execution
 type ExecutionContext struct {
-	Pending bool           // execution moved from previous pulse
 	TraceID []byte         // TraceID
 	Queue   []core.Message // queued requests
+
+	ready    []chan bool // receives true when queued request can be executed, false when it is handed off
+	running  bool        // request is being executed right now
+	readers  int         // count of immutable requests being executed concurrently right now
+	awaiting bool        // previous executor has not completed execution yet
+	moved    bool        // executor role moved to another node
+
+	awaitedPulse core.PulseNumber // pulse when previous executor handed off the object
+
+	trees map[core.TraceStep]int // roots of call trees executing the object right now
+
+	held      *core.TraceStep  // root call of the tree which changed the object and is not completed yet
+	heldPulse core.PulseNumber // pulse when the tree started holding the object
+	journal   []tracedEntry    // changes of the object made by the holding tree
 }
 
 // LogicRunner is a general interface of contract executor
@@ -49,16 +77,23 @@ type LogicRunner struct {
 	Executors       [core.MachineTypesLastID]core.MachineLogicExecutor
 	ArtifactManager core.ArtifactManager
 	MessageBus      core.MessageBus
+	JetCoordinator  core.JetCoordinator
 	machinePrefs    []core.MachineType
 	Cfg             *configuration.LogicRunner
-	context         map[Ref]ExecutionContext // if object exists, we are validating or executing it right now
+	context         map[Ref]*ExecutionContext // if object exists, we are validating or executing it right now
 	contextMutex    sync.Mutex
 
 	// TODO refactor caseBind and caseBindReplays to one clear structure
 	caseBind             core.CaseBind
+	prevPulse            core.PulseNumber // pulse before the current one, guarded by caseBindMutex
 	caseBindMutex        sync.Mutex
 	caseBindReplays      map[Ref]core.CaseBindReplay
 	caseBindReplaysMutex sync.Mutex
+	validations          map[Ref]*validation
+	validationsMutex     sync.Mutex
+	callRecords          map[callKey][]core.CaseRecord // case records of current calls, guarded by caseBindMutex
+	journal              map[callKey]*callJournal      // changes made by objects during current calls
+	journalMutex         sync.Mutex
 	sock                 net.Listener
 }
 
@@ -70,9 +105,12 @@ func NewLogicRunner(cfg *configuration.LogicRunner) (*LogicRunner, error) {
 	res := LogicRunner{
 		ArtifactManager: nil,
 		Cfg:             cfg,
-		context:         make(map[Ref]ExecutionContext),
+		context:         make(map[Ref]*ExecutionContext),
 		caseBind:        core.CaseBind{Pulse: core.Pulse{}, Records: make(map[Ref][]core.CaseRecord)},
 		caseBindReplays: make(map[Ref]core.CaseBindReplay),
+		validations:     make(map[Ref]*validation),
+		callRecords:     make(map[callKey][]core.CaseRecord),
+		journal:         make(map[callKey]*callJournal),
 	}
 	return &res, nil
 }
@@ -81,11 +119,12 @@ func NewLogicRunner(cfg *configuration.LogicRunner) (*LogicRunner, error) {
 func (lr *LogicRunner) Start(c core.Components) error {
 	am := c.Ledger.GetArtifactManager()
 	lr.ArtifactManager = am
+	lr.JetCoordinator = c.Ledger.GetJetCoordinator()
 	messageBus := c.MessageBus
 	lr.MessageBus = messageBus
 
 	if lr.Cfg.BuiltIn != nil {
-		bi := builtin.NewBuiltIn(messageBus, am)
+		bi := builtin.NewBuiltIn(messageBus, am, &RPC{lr: lr})
 		if err := lr.RegisterExecutor(core.MachineTypeBuiltin, bi); err != nil {
 			return err
 		}
@@ -108,19 +147,32 @@ func (lr *LogicRunner) Start(c core.Components) error {
 	}
 
 	// TODO: use separate handlers
-	if err := messageBus.Register(core.TypeCallMethod, lr.Execute); err != nil {
+	if err := messageBus.Register(core.TypeCallMethod, messageHandler(lr.Execute)); err != nil {
 		return err
 	}
-	if err := messageBus.Register(core.TypeCallConstructor, lr.Execute); err != nil {
+	if err := messageBus.Register(core.TypeCallConstructor, messageHandler(lr.Execute)); err != nil {
 		return err
 	}
 
+	// executor results are handled with sender to check it is the previous executor
 	if err := messageBus.Register(core.TypeExecutorResults, lr.ExecutorResults); err != nil {
 		return err
 	}
-	if err := messageBus.Register(core.TypeValidateCaseBind, lr.ValidateCaseBind); err != nil {
+	// handoff is accepted only from previous executor, so it's handled with sender
+	if err := messageBus.Register(core.TypeExecutorHandoff, lr.ExecutorHandoff); err != nil {
 		return err
 	}
+	if err := messageBus.Register(core.TypeExecutionCompleted, lr.ExecutionCompleted); err != nil {
+		return err
+	}
+	// journal is applied only by executor of the call, so it's handled with sender
+	if err := messageBus.Register(core.TypeApplyJournal, lr.ApplyJournal); err != nil {
+		return err
+	}
+	if err := messageBus.Register(core.TypeValidateCaseBind, messageHandler(lr.ValidateCaseBind)); err != nil {
+		return err
+	}
+	// validation results are handled with sender to check it is a validator
 	if err := messageBus.Register(core.TypeValidationResults, lr.ProcessValidationResults); err != nil {
 		return err
 	}
@@ -128,6 +180,13 @@ func (lr *LogicRunner) Start(c core.Components) error {
 	return nil
 }
 
+// messageHandler adapts logic runner method to message bus handler.
+func messageHandler(f func(core.Message) (core.Reply, error)) core.MessageHandler {
+	return func(msg core.SignedMessage) (core.Reply, error) {
+		return f(msg.Message())
+	}
+}
+
 // Stop stops logic runner component and its executors
 func (lr *LogicRunner) Stop() error {
 	reterr := error(nil)
@@ -170,7 +229,10 @@ func (lr *LogicRunner) GetContext(ref Ref) (ExecutionContext, bool) {
 	lr.contextMutex.Lock()
 	defer lr.contextMutex.Unlock()
 	ret, ok := lr.context[ref]
-	return ret, ok
+	if !ok {
+		return ExecutionContext{}, false
+	}
+	return *ret, ok
 }
 
 func (lr *LogicRunner) SetContext(ref Ref, ec ExecutionContext) bool {
@@ -179,10 +241,23 @@ func (lr *LogicRunner) SetContext(ref Ref, ec ExecutionContext) bool {
 	if _, ok := lr.context[ref]; ok {
 		return false
 	}
-	lr.context[ref] = ec
+	lr.context[ref] = &ec
 	return true
 }
 
+// callLimits returns resources available to one contract call
+func (lr *LogicRunner) callLimits() core.CallLimits {
+	limits := lr.Cfg.Limits
+	if limits == nil {
+		return core.CallLimits{}
+	}
+	return core.CallLimits{
+		Time:   time.Duration(limits.CallTime) * time.Millisecond,
+		Memory: limits.Memory,
+		Depth:  limits.CallDepth,
+	}
+}
+
 // Execute runs a method on an object, ATM just thin proxy to `GoPlugin.Exec`
 func (lr *LogicRunner) Execute(inmsg core.Message) (core.Reply, error) {
 	// TODO do not pass here message.ValidateCaseBind and message.ExecutorResults
@@ -207,21 +282,33 @@ func (lr *LogicRunner) Execute(inmsg core.Message) (core.Reply, error) {
 		Caller: msg.GetCaller(),
 		Time:   time.Now(), // TODO: probably we should take it from e
 		Pulse:  lr.caseBind.Pulse,
+		Limits: lr.callLimits(),
 	}
 
 	switch m := msg.(type) {
 	case *message.CallMethod:
-		ec := ExecutionContext{}
-		if !lr.SetContext(ref, ec) {
-			return nil, errors.New("Object already exeuting")
+		ctx.Depth = m.Depth
+		if ctx.Limits.Depth > 0 && ctx.Depth > ctx.Limits.Depth {
+			return nil, ErrCallDepthExceeded
 		}
-		re, err := lr.executeMethodCall(ctx, m, vb)
-		lr.contextMutex.Lock()
-		defer lr.contextMutex.Unlock()
-		delete(lr.context, ref)
-		return re, err
+		ctx.Trace = methodTrace(m)
+		executeHere, err := lr.acquireContext(ref, m, ctx.Trace[0])
+		if err != nil {
+			return nil, err
+		}
+		if !executeHere {
+			// executor role moved to another node on pulse change
+			return lr.MessageBus.Send(m)
+		}
+		defer lr.releaseContext(ref, m, ctx.Trace[0])
+		ctx.Pulse = lr.caseBind.Pulse // pulse could change while message was queued
+		return lr.executeMethodCall(ctx, m, vb)
 
 	case *message.CallConstructor:
+		ctx.Depth = m.Depth
+		if ctx.Limits.Depth > 0 && ctx.Depth > ctx.Limits.Depth {
+			return nil, ErrCallDepthExceeded
+		}
 		re, err := lr.executeConstructorCall(ctx, m, vb)
 		return re, err
 
@@ -236,36 +323,37 @@ func (lr *LogicRunner) ValidateCaseBind(inmsg core.Message) (core.Reply, error)
 		return nil, errors.New("Execute( ! message.ValidateCaseBindInterface )")
 	}
 
-	passedStepsCount, validationError := lr.Validate(msg.GetReference(), msg.GetPulse(), msg.GetCaseRecords())
-	_, err := lr.MessageBus.Send(&message.ValidationResults{
+	records, err := decodeCaseRecords(msg.GetCaseRecords())
+	if err != nil {
+		return nil, err
+	}
+	passedStepsCount, validationError := lr.Validate(msg.GetReference(), msg.GetPulse(), records)
+	results := &message.ValidationResults{
 		RecordRef:        msg.GetReference(),
 		PassedStepsCount: passedStepsCount,
-		Error:            validationError,
-	})
-
-	return nil, err
-}
-
-func (lr *LogicRunner) ProcessValidationResults(inmsg core.Message) (core.Reply, error) {
-	// Handle all validators Request
-	// Do some staff if request don't come for a long time
-	// Compare results of different validators and previous Executor
-	return nil, nil
-}
+	}
+	if validationError != nil {
+		results.Error = validationError.Error()
+	}
+	_, err = lr.MessageBus.Send(results)
+	if err != nil {
+		return nil, err
+	}
 
-func (lr *LogicRunner) ExecutorResults(inmsg core.Message) (core.Reply, error) {
-	// Coordinate this with ProcessValidationResults
-	return nil, nil
+	return &reply.OK{}, nil
 }
 
 type objectBody struct {
 	Body        []byte
 	Code        core.RecordRef
 	Class       core.RecordRef
+	ClassState  *core.RecordID
+	Migrations  []core.RecordRef
 	MachineType core.MachineType
 }
 
-func (lr *LogicRunner) getObjectMessage(objref core.RecordRef) (*objectBody, error) {
+func (lr *LogicRunner) getObjectMessage(call callKey) (*objectBody, error) {
+	objref := call.object
 	cr, step := lr.getNextValidationStep(objref)
 	if step >= 0 { // validate
 		if core.CaseRecordTypeGetObject != cr.Type {
@@ -288,14 +376,21 @@ func (lr *LogicRunner) getObjectMessage(objref core.RecordRef) (*objectBody, err
 		return nil, errors.Wrap(err, "couldn't get object's class")
 	}
 
+	migrations, err := lr.pendingMigrations(objDesc, classDesc)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get object's migrations")
+	}
+
 	codeDesc := classDesc.CodeDescriptor()
 	ob := &objectBody{
 		Body:        objDesc.Memory(),
 		Code:        *codeDesc.Ref(),
 		Class:       *classDesc.HeadRef(),
+		ClassState:  classDesc.StateID(),
+		Migrations:  migrations,
 		MachineType: codeDesc.MachineType(),
 	}
-	lr.addObjectCaseRecord(objref, core.CaseRecord{
+	lr.addCallCaseRecord(call, core.CaseRecord{
 		Type:   core.CaseRecordTypeGetObject,
 		ReqSig: HashInterface(objref),
 		Resp:   ob,
@@ -304,18 +399,26 @@ func (lr *LogicRunner) getObjectMessage(objref core.RecordRef) (*objectBody, err
 }
 
 func (lr *LogicRunner) executeMethodCall(ctx core.LogicCallContext, e *message.CallMethod, vb ValidationBehaviour) (core.Reply, error) {
-	vb.Begin(e.ObjectRef, core.CaseRecord{
+	call := callOf(e.ObjectRef, ctx.Trace)
+	async := false // case records of the call without waiting are added when it's completed
+	defer func() {
+		if !async {
+			lr.flushCallCaseRecords(call)
+		}
+	}()
+	vb.Begin(call, core.CaseRecord{
 		Type: core.CaseRecordTypeStart,
 		Resp: e,
 	})
 
-	objbody, err := lr.getObjectMessage(e.ObjectRef)
+	objbody, err := lr.getObjectMessage(call)
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't get object message")
 	}
 
 	ctx.Callee = &e.ObjectRef
 	ctx.Class = &objbody.Class
+	ctx.Immutable = e.Immutable
 	vb.ModifyContext(&ctx)
 
 	executor, err := lr.GetExecutor(objbody.MachineType)
@@ -323,39 +426,95 @@ func (lr *LogicRunner) executeMethodCall(ctx core.LogicCallContext, e *message.C
 		return nil, errors.Wrap(err, "no executor registered")
 	}
 
+	trace := ctx.Trace
+	root := len(trace) == 1
+
 	executer := func() (*reply.CallMethod, error) {
+		body := objbody.Body
+		if len(objbody.Migrations) > 0 {
+			migrated, err := migrate(ctx, executor, objbody.Migrations, body)
+			if err != nil {
+				return nil, errors.Wrap(err, "couldn't migrate object")
+			}
+			body = migrated
+			if vb.NeedSave() && !e.Immutable {
+				_, err = lr.ArtifactManager.MigrateObject(
+					core.RecordRef{}, core.RecordRef{}, e.ObjectRef, *objbody.ClassState, body,
+				)
+				if err != nil {
+					return nil, errors.Wrap(err, "couldn't save migrated object")
+				}
+			}
+		}
+
 		newData, result, err := executor.CallMethod(
-			&ctx, objbody.Code, objbody.Body, e.Method, e.Arguments,
+			&ctx, objbody.Code, body, e.Method, e.Arguments,
 		)
+		journal := lr.takeJournal(call)
 		if err != nil {
-			return nil, errors.Wrap(err, "executor error")
+			return nil, lr.abortCall(vb, trace, journal, errors.Wrap(err, "executor error"))
 		}
 
-		// TODO: deactivation should be handled way better here
-		if vb.NeedSave() && lr.lastObjectCaseRecord(e.ObjectRef).Type != core.CaseRecordTypeDeactivateObject {
+		changed := len(journal.entries) > 0 || len(journal.pending) > 0
+		if e.Immutable && !ctx.Aborted && (changed || !bytes.Equal(newData, body)) {
+			return nil, lr.abortCall(vb, trace, journal, ErrImmutableCallChangesState)
+		}
+
+		if ctx.Aborted {
+			// method returned error, object keeps its state
+			newData = body
+			err = lr.revertCall(vb, trace, journal)
+			if err != nil {
+				return nil, errors.Wrap(err, "couldn't revert changes of the call")
+			}
+			journal = &callJournal{}
+		} else if vb.NeedSave() && !e.Immutable && !deactivates(journal.entries, e.ObjectRef) {
 			_, err = lr.ArtifactManager.UpdateObject(
 				core.RecordRef{}, core.RecordRef{}, e.ObjectRef, newData,
 			)
 			if err != nil {
-				return nil, errors.Wrap(err, "couldn't update object")
+				return nil, lr.abortCall(vb, trace, journal, errors.Wrap(err, "couldn't update object"))
 			}
+			journal.entries = append(journal.entries, core.JournalEntry{
+				Type: core.JournalEntryUpdate, Object: e.ObjectRef, Memory: body,
+			})
 		}
 
-		re := &reply.CallMethod{Data: newData, Result: result}
+		re := &reply.CallMethod{Data: newData, Result: result, Usage: ctx.Usage}
 
-		vb.End(e.ObjectRef, core.CaseRecord{
+		vb.End(call, core.CaseRecord{
 			Type: core.CaseRecordTypeResult,
 			Resp: re,
 		})
 
-		return re, nil
+		if !vb.NeedSave() {
+			return re, nil
+		}
+		if root {
+			err = lr.commitCall(trace, journal)
+			if err != nil {
+				return nil, errors.Wrap(err, "couldn't commit changes of the call")
+			}
+			return re, nil
+		}
+		withPending := *re
+		withPending.Pending = journal.pending
+		if len(journal.entries) > 0 {
+			lr.keepJournal(e.ObjectRef, trace, journal.entries...)
+			if !containsRef(withPending.Pending, e.ObjectRef) {
+				withPending.Pending = append(withPending.Pending, e.ObjectRef)
+			}
+		}
+		return &withPending, nil
 	}
 
 	switch e.ReturnMode {
 	case message.ReturnResult:
 		return executer()
 	case message.ReturnNoWait:
+		async = true
 		go func() {
+			defer lr.flushCallCaseRecords(call)
 			_, err := executer()
 			if err != nil {
 				log.Error(err)
@@ -367,7 +526,9 @@ func (lr *LogicRunner) executeMethodCall(ctx core.LogicCallContext, e *message.C
 }
 
 func (lr *LogicRunner) executeConstructorCall(ctx core.LogicCallContext, m *message.CallConstructor, vb ValidationBehaviour) (core.Reply, error) {
-	vb.Begin(m.ClassRef, core.CaseRecord{
+	call := callOf(m.ClassRef, m.Trace)
+	defer lr.flushCallCaseRecords(call)
+	vb.Begin(call, core.CaseRecord{
 		Type: core.CaseRecordTypeStart,
 		Resp: m,
 	})
@@ -402,12 +563,12 @@ func (lr *LogicRunner) executeConstructorCall(ctx core.LogicCallContext, m *mess
 				core.RecordRef{}, *ref, m.ClassRef, m.ParentRef, newData,
 			)
 		}
-		vb.End(m.ClassRef, core.CaseRecord{
+		vb.End(call, core.CaseRecord{
 			Type: core.CaseRecordTypeResult,
 			Resp: &reply.CallConstructor{Object: ref},
 		})
 
-		return &reply.CallConstructor{Object: ref}, err
+		return lr.constructorReply(vb, m, ref, err)
 	case message.Delegate:
 		ref, err := lr.ArtifactManager.RegisterRequest(m)
 		if err != nil {
@@ -418,20 +579,50 @@ func (lr *LogicRunner) executeConstructorCall(ctx core.LogicCallContext, m *mess
 				core.RecordRef{}, *ref, m.ClassRef, m.ParentRef, newData,
 			)
 		}
-		vb.End(m.ClassRef, core.CaseRecord{
+		vb.End(call, core.CaseRecord{
 			Type: core.CaseRecordTypeResult,
 			Resp: &reply.CallConstructor{Object: ref},
 		})
 
-		return &reply.CallConstructor{Object: ref}, err
+		return lr.constructorReply(vb, m, ref, err)
 	default:
 		return nil, errors.New("unsupported type of save object")
 	}
 }
 
+// constructorReply makes reply of the constructor call, object created by the nested call is held by the call tree
+// and deactivated if the tree fails
+func (lr *LogicRunner) constructorReply(
+	vb ValidationBehaviour, m *message.CallConstructor, ref *core.RecordRef, err error,
+) (core.Reply, error) {
+	re := &reply.CallConstructor{Object: ref}
+	if err != nil || !vb.NeedSave() || len(m.Trace) == 0 {
+		return re, err
+	}
+	trace := m.Trace.Extend(core.TraceStep{Object: *ref, Nonce: m.Nonce})
+	lr.keepJournal(*ref, trace, core.JournalEntry{Type: core.JournalEntryActivate, Object: *ref})
+	re.Pending = []core.RecordRef{*ref}
+	return re, nil
+}
+
+// abortCall reverts changes of the failed call made by its nested calls
+func (lr *LogicRunner) abortCall(vb ValidationBehaviour, trace core.CallTrace, journal *callJournal, err error) error {
+	if revertErr := lr.revertCall(vb, trace, journal); revertErr != nil {
+		return errors.Wrapf(err, "couldn't revert changes of the call (%s)", revertErr)
+	}
+	return err
+}
+
 func (lr *LogicRunner) OnPulse(pulse core.Pulse) error {
+	lr.caseBindMutex.Lock()
+	oldPulse := lr.caseBind.Pulse
+	lr.caseBindMutex.Unlock()
 	// start of new Pulse, lock CaseBind data, copy it, clean original, unlock original
 	objectsRecords := lr.refreshCaseBind(pulse)
+	lr.refreshValidations()
+	if err := lr.handOffContexts(oldPulse.PulseNumber, pulse.PulseNumber); err != nil {
+		return err
+	}
 
 	if len(objectsRecords) == 0 {
 		return nil
@@ -439,15 +630,22 @@ func (lr *LogicRunner) OnPulse(pulse core.Pulse) error {
 
 	// send copy for validation
 	for ref, records := range objectsRecords {
-		_, err := lr.MessageBus.Send(&message.ValidateCaseBind{RecordRef: ref, CaseRecords: records, Pulse: pulse})
+		encoded, err := encodeCaseRecords(records)
+		if err != nil {
+			log.Errorf("failed to encode caseBind data of %s: %s", ref, err)
+			continue
+		}
+		rep, err := lr.MessageBus.Send(&message.ValidateCaseBind{RecordRef: ref, CaseRecords: encoded, Pulse: pulse})
 		if err != nil {
-			panic("Error while sending caseBind data to validators: " + err.Error())
+			log.Errorf("failed to send caseBind data of %s to validators: %s", ref, err)
+		} else if aggregated, ok := rep.(*reply.Aggregated); ok && aggregated.Partial() {
+			log.Warnf("caseBind data of %s is not delivered to all validators: %s", ref, aggregated.Err())
 		}
 
-		temp := message.ExecutorResults{RecordRef: ref, CaseRecords: records}
+		temp := message.ExecutorResults{RecordRef: ref, CaseRecords: encoded, Pulse: pulse}
 		_, err = lr.MessageBus.Send(&temp)
 		if err != nil {
-			return errors.New("error while sending caseBind data to new executor")
+			log.Errorf("failed to send caseBind data of %s to new executor: %s", ref, err)
 		}
 	}
 
@@ -461,6 +659,7 @@ func (lr *LogicRunner) refreshCaseBind(pulse core.Pulse) map[core.RecordRef][]co
 
 	oldObjectsRecords := lr.caseBind.Records
 
+	lr.prevPulse = lr.caseBind.Pulse.PulseNumber
 	lr.caseBind = core.CaseBind{
 		Pulse:   pulse,
 		Records: make(map[core.RecordRef][]core.CaseRecord),
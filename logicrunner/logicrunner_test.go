@@ -18,6 +18,7 @@ package logicrunner
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/rand"
 	"fmt"
@@ -26,6 +27,7 @@ import (
 	"os/exec"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/insolar/insolar/application/contract/member"
 	"github.com/insolar/insolar/application/contract/member/signer"
@@ -45,6 +47,7 @@ import (
 	"github.com/insolar/insolar/testutils"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ugorji/go/codec"
 )
 
@@ -52,6 +55,9 @@ var icc = ""
 var runnerbin = ""
 var parallel = true
 
+// testNode is a reference of the node running logic runner in tests
+var testNode = testutils.RandomRef()
+
 func TestMain(m *testing.M) {
 	var err error
 	err = log.SetLevel("Debug")
@@ -202,6 +208,7 @@ func TestBasics(t *testing.T) {
 
 type testLedger struct {
 	am core.ArtifactManager
+	jc core.JetCoordinator
 }
 
 func (r *testLedger) GetPulseManager() core.PulseManager {
@@ -209,7 +216,11 @@ func (r *testLedger) GetPulseManager() core.PulseManager {
 }
 
 func (r *testLedger) GetJetCoordinator() core.JetCoordinator {
-	panic("implement me")
+	if r.jc == nil {
+		// the only node has all roles
+		return &testJetCoordinator{validators: []core.RecordRef{testNode}}
+	}
+	return r.jc
 }
 
 func (r *testLedger) Start(components core.Components) error   { return nil }
@@ -224,6 +235,15 @@ type testMessageBus struct {
 	LogicRunner core.LogicRunner
 }
 
+// roleNode returns the node acting in the role in tests
+func roleNode(lr *LogicRunner, role core.JetRole) core.RecordRef {
+	nodes, err := lr.JetCoordinator.QueryRoleNodes(role, lr.caseBind.Pulse.PulseNumber)
+	if err != nil || len(nodes) == 0 {
+		return testNode
+	}
+	return nodes[0]
+}
+
 func (eb *testMessageBus) Register(p core.MessageType, handler core.MessageHandler) error {
 	return nil
 }
@@ -242,15 +262,26 @@ func (eb *testMessageBus) Send(event core.Message) (resp core.Reply, err error)
 	case core.TypeValidateCaseBind:
 		return eb.LogicRunner.ValidateCaseBind(event)
 	case core.TypeValidationResults:
-		return eb.LogicRunner.ProcessValidationResults(event)
+		lr := eb.LogicRunner.(*LogicRunner)
+		return lr.ProcessValidationResults(&message.SignedMessage{Msg: event, Sender: roleNode(lr, core.RoleVirtualValidator)})
 	case core.TypeExecutorResults:
-		return eb.LogicRunner.ExecutorResults(event)
+		lr := eb.LogicRunner.(*LogicRunner)
+		return lr.ExecutorResults(&message.SignedMessage{Msg: event, Sender: roleNode(lr, core.RoleVirtualExecutor)})
+	case core.TypeApplyJournal:
+		lr := eb.LogicRunner.(*LogicRunner)
+		return lr.ApplyJournal(&message.SignedMessage{Msg: event, Sender: roleNode(lr, core.RoleVirtualExecutor)})
 	}
 
 	return eb.LogicRunner.Execute(event)
 }
 func (*testMessageBus) SendAsync(msg core.Message) {}
 
+func (eb *testMessageBus) SendWithOptions(
+	ctx context.Context, msg core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return eb.Send(msg)
+}
+
 func TestExecution(t *testing.T) {
 	if parallel {
 		t.Parallel()
@@ -295,6 +326,704 @@ func TestExecution(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+type blockingExecutor struct {
+	started chan string
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Stop() error { return nil }
+
+func (e *blockingExecutor) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
+	e.started <- method
+	<-e.release
+	return data, core.Arguments(method), nil
+}
+
+func (e *blockingExecutor) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
+	return nil, nil
+}
+
+func TestExecutionQueue(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 1})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am, jc: &testJetCoordinator{validators: []core.RecordRef{testutils.RandomRef()}}},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := core.NewRefFromBase58("someCode")
+	dataRef := core.NewRefFromBase58("someObject")
+	classRef := core.NewRefFromBase58("someClass")
+	am.Objects[dataRef] = &goplugintestutils.TestObjectDescriptor{
+		AM:    am,
+		Data:  []byte("origData"),
+		Code:  &codeRef,
+		Class: &classRef,
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	be := &blockingExecutor{started: make(chan string, 2), release: make(chan struct{})}
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, be)
+	assert.NoError(t, err)
+
+	results := make(chan string, 2)
+	call := func(method string) {
+		resp, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: method})
+		assert.NoError(t, err)
+		results <- string(resp.(*reply.CallMethod).Result)
+	}
+	go call("first")
+	assert.Equal(t, "first", <-be.started)
+	go call("second")
+	for {
+		ec, _ := lr.GetContext(dataRef)
+		if len(ec.Queue) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "third"})
+	assert.Equal(t, ErrQueueIsFull, err)
+
+	err = lr.handOffContexts(core.FirstPulseNumber, core.FirstPulseNumber+1)
+	assert.NoError(t, err)
+	ec, _ := lr.GetContext(dataRef)
+	assert.Len(t, ec.Queue, 1, "queued message should be carried over to the new pulse")
+
+	be.release <- struct{}{}
+	assert.Equal(t, "first", <-results)
+	assert.Equal(t, "second", <-be.started)
+	be.release <- struct{}{}
+	assert.Equal(t, "second", <-results)
+
+	_, ok := lr.GetContext(dataRef)
+	assert.False(t, ok)
+}
+
+func TestExecutionQueueTimeout(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 1, QueueTimeout: 10})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := core.NewRefFromBase58("someCode")
+	dataRef := core.NewRefFromBase58("someObject")
+	classRef := core.NewRefFromBase58("someClass")
+	am.Objects[dataRef] = &goplugintestutils.TestObjectDescriptor{
+		AM:    am,
+		Data:  []byte("origData"),
+		Code:  &codeRef,
+		Class: &classRef,
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	be := &blockingExecutor{started: make(chan string, 1), release: make(chan struct{})}
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, be)
+	assert.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "first"})
+		done <- err
+	}()
+	assert.Equal(t, "first", <-be.started)
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "second"})
+	assert.Equal(t, ErrQueueTimeout, err)
+	ec, _ := lr.GetContext(dataRef)
+	assert.Empty(t, ec.Queue, "message should be removed from the queue on timeout")
+
+	be.release <- struct{}{}
+	assert.NoError(t, <-done)
+	_, ok := lr.GetContext(dataRef)
+	assert.False(t, ok)
+}
+
+func TestImmutableCalls(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 2})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := core.NewRefFromBase58("someCode")
+	dataRef := core.NewRefFromBase58("someObject")
+	classRef := core.NewRefFromBase58("someClass")
+	am.Objects[dataRef] = &goplugintestutils.TestObjectDescriptor{
+		AM:    am,
+		Data:  []byte("origData"),
+		Code:  &codeRef,
+		Class: &classRef,
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	be := &blockingExecutor{started: make(chan string, 4), release: make(chan struct{})}
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, be)
+	assert.NoError(t, err)
+
+	results := make(chan string, 4)
+	call := func(method string, immutable bool) {
+		resp, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: method, Immutable: immutable})
+		assert.NoError(t, err)
+		results <- string(resp.(*reply.CallMethod).Result)
+	}
+	waitQueue := func(length int) {
+		for {
+			ec, _ := lr.GetContext(dataRef)
+			if len(ec.Queue) == length {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	go call("read1", true)
+	assert.Equal(t, "read1", <-be.started)
+	go call("read2", true)
+	assert.Equal(t, "read2", <-be.started, "immutable calls should be executed concurrently")
+	go call("write", false)
+	waitQueue(1)
+	go call("read3", true)
+	waitQueue(2)
+
+	be.release <- struct{}{}
+	be.release <- struct{}{}
+	<-results
+	<-results
+	assert.Equal(t, "write", <-be.started)
+	be.release <- struct{}{}
+	assert.Equal(t, "write", <-results)
+	assert.Equal(t, "read3", <-be.started)
+	be.release <- struct{}{}
+	assert.Equal(t, "read3", <-results)
+
+	_, ok := lr.GetContext(dataRef)
+	assert.False(t, ok)
+}
+
+type meteringExecutor struct {
+	ctx core.LogicCallContext
+}
+
+func (e *meteringExecutor) Stop() error { return nil }
+
+func (e *meteringExecutor) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
+	ctx.Usage = core.CallUsage{Time: time.Second, Memory: 1024, Calls: 2}
+	e.ctx = *ctx
+	return data, nil, nil
+}
+
+func (e *meteringExecutor) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
+	return nil, nil
+}
+
+func TestExecutionLimits(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{
+		Limits: &configuration.Limits{CallTime: 1000, Memory: 2048, CallDepth: 2},
+	})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := core.NewRefFromBase58("someCode")
+	dataRef := core.NewRefFromBase58("someObject")
+	classRef := core.NewRefFromBase58("someClass")
+	am.Objects[dataRef] = &goplugintestutils.TestObjectDescriptor{
+		AM:    am,
+		Data:  []byte("origData"),
+		Code:  &codeRef,
+		Class: &classRef,
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	me := &meteringExecutor{}
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, me)
+	assert.NoError(t, err)
+
+	resp, err := lr.Execute(&message.CallMethod{
+		BaseLogicMessage: message.BaseLogicMessage{Depth: 2},
+		ObjectRef:        dataRef,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.CallUsage{Time: time.Second, Memory: 1024, Calls: 2}, resp.(*reply.CallMethod).Usage)
+	assert.Equal(t, core.CallLimits{Time: time.Second, Memory: 2048, Depth: 2}, me.ctx.Limits)
+	assert.Equal(t, 2, me.ctx.Depth)
+
+	_, err = lr.Execute(&message.CallMethod{
+		BaseLogicMessage: message.BaseLogicMessage{Depth: 3},
+		ObjectRef:        dataRef,
+	})
+	assert.Equal(t, ErrCallDepthExceeded, err)
+	_, err = lr.Execute(&message.CallConstructor{
+		BaseLogicMessage: message.BaseLogicMessage{Depth: 3},
+		ClassRef:         classRef,
+	})
+	assert.Equal(t, ErrCallDepthExceeded, err)
+}
+
+// journalExecutor emulates contracts changing ledger through RPC of the logic runner
+type journalExecutor struct {
+	lr      *LogicRunner
+	nested  core.RecordRef
+	class   core.RecordRef
+	child   *core.RecordRef
+	inside  chan struct{}
+	release chan struct{}
+}
+
+func (e *journalExecutor) Stop() error { return nil }
+
+func (e *journalExecutor) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
+	e.lr.appendJournal(callOf(*ctx.Callee, ctx.Trace), core.JournalEntry{
+		Type: core.JournalEntryEmit, Object: *ctx.Callee, Name: method, Payload: []byte{1},
+	})
+	switch method {
+	case "Change":
+		return []byte("changed"), nil, nil
+	case "Failed":
+		return nil, nil, errors.New("call failed")
+	case "Reenter", "CallBack":
+		// calls back the caller through the nested object
+		target := e.nested
+		if method == "CallBack" {
+			target = *ctx.Caller
+		}
+		base := message.BaseLogicMessage{Caller: *ctx.Callee, Depth: ctx.Depth + 1, Trace: ctx.Trace}
+		_, err := e.lr.MessageBus.Send(&message.CallMethod{BaseLogicMessage: base, ObjectRef: target, Method: "CallBack"})
+		if err != nil {
+			return nil, nil, err
+		}
+	case "Outer", "OuterFailed", "OuterAborted", "OuterHeld":
+		base := message.BaseLogicMessage{Caller: *ctx.Callee, Depth: ctx.Depth + 1, Trace: ctx.Trace}
+		res, err := e.lr.MessageBus.Send(&message.CallMethod{BaseLogicMessage: base, ObjectRef: e.nested, Method: "Change"})
+		if err != nil {
+			return nil, nil, err
+		}
+		e.lr.appendPending(callOf(*ctx.Callee, ctx.Trace), res.(*reply.CallMethod).Pending...)
+		if method == "OuterHeld" {
+			e.inside <- struct{}{}
+			<-e.release
+			return data, nil, nil
+		}
+		res, err = e.lr.MessageBus.Send(&message.CallConstructor{
+			BaseLogicMessage: base, ClassRef: e.class, ParentRef: *ctx.Callee, SaveAs: message.Child,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		e.child = res.(*reply.CallConstructor).Object
+		e.lr.appendPending(callOf(*ctx.Callee, ctx.Trace), res.(*reply.CallConstructor).Pending...)
+		if method == "OuterFailed" {
+			return nil, nil, errors.New("call failed")
+		}
+		ctx.Aborted = method == "OuterAborted"
+	}
+	return data, nil, nil
+}
+
+func (e *journalExecutor) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
+	return []byte("child"), nil
+}
+
+func prepareJournalTest(t *testing.T) (*LogicRunner, *goplugintestutils.TestArtifactManager, *journalExecutor) {
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{QueueLength: 1})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := core.NewRefFromBase58("someCode")
+	classRef := core.NewRefFromBase58("someClass")
+	je := &journalExecutor{
+		lr: lr, nested: testutils.RandomRef(), class: classRef, inside: make(chan struct{}), release: make(chan struct{}),
+	}
+	for _, ref := range []core.RecordRef{core.NewRefFromBase58("someObject"), je.nested} {
+		am.Objects[ref] = &goplugintestutils.TestObjectDescriptor{
+			AM:        am,
+			Data:      []byte("origData"),
+			Code:      &codeRef,
+			Class:     &classRef,
+			Delegates: make(map[core.RecordRef]core.RecordRef),
+		}
+	}
+	am.Classes[classRef] = &goplugintestutils.TestClassDescriptor{AM: am, ARef: &classRef, ACode: &codeRef}
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, je)
+	assert.NoError(t, err)
+	return lr, am, je
+}
+
+func TestEmitEvents(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, _ := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+
+	_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Transfer"})
+	assert.NoError(t, err)
+	assert.Equal(t, []core.Event{{Name: "Transfer", Payload: []byte{1}}}, am.Events[dataRef])
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Failed"})
+	assert.Error(t, err)
+	assert.Len(t, am.Events[dataRef], 1, "events of failed call shouldn't be stored")
+	assert.Empty(t, lr.journal)
+}
+
+func TestImmutableCallChangesState(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+
+	_, err := lr.Execute(&message.CallMethod{ObjectRef: je.nested, Method: "Change", Immutable: true})
+	assert.Equal(t, ErrImmutableCallChangesState, err)
+	assert.Equal(t, []byte("origData"), am.Objects[je.nested].Data)
+	assert.Empty(t, am.Events)
+}
+
+// readersExecutor records case records of immutable calls running concurrently, "Changing" call changes state
+type readersExecutor struct {
+	lr      *LogicRunner
+	inside  chan struct{}
+	release map[string]chan struct{}
+}
+
+func (e *readersExecutor) Stop() error { return nil }
+
+func (e *readersExecutor) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
+	call := callOf(*ctx.Callee, ctx.Trace)
+	e.lr.addCallCaseRecord(call, core.CaseRecord{Type: core.CaseRecordTypeGetDelegate, Resp: method})
+	if method == "Changing" {
+		e.lr.appendPending(call, testutils.RandomRef())
+	}
+	e.inside <- struct{}{}
+	<-e.release[method]
+	e.lr.addCallCaseRecord(call, core.CaseRecord{Type: core.CaseRecordTypeGetDelegate, Resp: method})
+	return data, nil, nil
+}
+
+func (e *readersExecutor) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
+	return nil, nil
+}
+
+func TestConcurrentImmutableCallsState(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, _, _ := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+	re := &readersExecutor{lr: lr, inside: make(chan struct{}, 2), release: map[string]chan struct{}{
+		"Reading": make(chan struct{}), "Changing": make(chan struct{}),
+	}}
+	err := lr.RegisterExecutor(core.MachineTypeGoPlugin, re)
+	require.NoError(t, err)
+
+	done := make(map[string]chan error)
+	for _, method := range []string{"Changing", "Reading"} {
+		done[method] = make(chan error, 1)
+		go func(method string) {
+			_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: method, Immutable: true})
+			done[method] <- err
+		}(method)
+		<-re.inside
+	}
+
+	close(re.release["Reading"])
+	assert.NoError(t, <-done["Reading"], "changes of the concurrent call shouldn't be taken by the call")
+	close(re.release["Changing"])
+	assert.Equal(t, ErrImmutableCallChangesState, <-done["Changing"])
+
+	var steps []string
+	for _, cr := range lr.caseBind.Records[dataRef] {
+		switch cr.Type {
+		case core.CaseRecordTypeStart:
+			steps = append(steps, "Start "+cr.Resp.(*message.CallMethod).Method)
+		case core.CaseRecordTypeGetObject:
+			steps = append(steps, "GetObject")
+		case core.CaseRecordTypeResult:
+			steps = append(steps, "Result")
+		default:
+			steps = append(steps, cr.Resp.(string))
+		}
+	}
+	assert.Equal(t, []string{
+		"Start Reading", "GetObject", "Reading", "Reading", "Result",
+		"Start Changing", "GetObject", "Changing", "Changing",
+	}, steps, "case records of concurrent calls shouldn't interleave")
+	assert.Empty(t, lr.journal)
+}
+
+func TestCallTreeRollback(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+	nestedRef := je.nested
+
+	_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "OuterFailed"})
+	assert.Error(t, err)
+	assert.Equal(t, []byte("origData"), am.Objects[nestedRef].Data, "changes of nested call should be reverted")
+	assert.NotContains(t, am.Objects, *je.child, "object created by nested call should be deactivated")
+	assert.Empty(t, am.Events)
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "OuterAborted"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("origData"), am.Objects[nestedRef].Data, "changes of aborted call should be reverted")
+	assert.NotContains(t, am.Objects, *je.child)
+	assert.Empty(t, am.Events)
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Outer"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("changed"), am.Objects[nestedRef].Data)
+	assert.Contains(t, am.Objects, *je.child)
+	assert.Equal(t, []core.Event{{Name: "Outer", Payload: []byte{1}}}, am.Events[dataRef])
+	assert.Equal(t, []core.Event{{Name: "Change", Payload: []byte{1}}}, am.Events[nestedRef])
+	_, ok := lr.GetContext(nestedRef)
+	assert.False(t, ok, "object should be released when the call tree is completed")
+}
+
+func TestCallTreeHoldsObjects(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "OuterHeld"})
+		done <- err
+	}()
+	<-je.inside
+
+	// call of another tree waits until changes of the first tree are committed
+	go func() {
+		_, err := lr.Execute(&message.CallMethod{ObjectRef: je.nested, Method: "Change"})
+		done <- err
+	}()
+	for {
+		ec, _ := lr.GetContext(je.nested)
+		if len(ec.Queue) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Len(t, am.Events[je.nested], 0)
+
+	je.release <- struct{}{}
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+	assert.Len(t, am.Events[je.nested], 2)
+}
+
+func TestReentrantCall(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+
+	_, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Reenter"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrReentrantCall.Error())
+	assert.Equal(t, []byte("origData"), am.Objects[je.nested].Data)
+	assert.Empty(t, am.Events)
+	_, ok := lr.GetContext(dataRef)
+	assert.False(t, ok)
+	_, ok = lr.GetContext(je.nested)
+	assert.False(t, ok)
+}
+
+func TestApplyJournalAuthorization(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+
+	trace := core.CallTrace{{Object: dataRef}}
+	lr.keepJournal(je.nested, trace.Extend(core.TraceStep{Object: je.nested}), core.JournalEntry{
+		Type: core.JournalEntryUpdate, Object: je.nested, Memory: []byte("oldData"),
+	})
+	apply := func(sender core.RecordRef, trace core.CallTrace, commit bool) error {
+		msg := &message.ApplyJournal{RecordRef: je.nested, Trace: trace, Commit: commit}
+		_, err := lr.ApplyJournal(&message.SignedMessage{Msg: msg, Sender: sender})
+		return err
+	}
+
+	assert.Error(t, apply(testutils.RandomRef(), trace, false), "journal is reverted only by executor of the call")
+	assert.Error(t, apply(testNode, trace.Extend(core.TraceStep{Object: je.nested}), true), "only root commits")
+	assert.Equal(t, []byte("origData"), am.Objects[je.nested].Data)
+
+	assert.NoError(t, apply(testNode, core.CallTrace{{Object: testutils.RandomRef()}}, false))
+	assert.Equal(t, []byte("origData"), am.Objects[je.nested].Data, "journal of another tree isn't reverted")
+
+	assert.NoError(t, apply(testNode, trace, false))
+	assert.Equal(t, []byte("oldData"), am.Objects[je.nested].Data)
+	_, ok := lr.GetContext(je.nested)
+	assert.False(t, ok)
+}
+
+// journalDownBus fails to deliver journal of the object to its executor
+type journalDownBus struct {
+	testMessageBus
+	down core.RecordRef
+}
+
+func (b *journalDownBus) Send(msg core.Message) (core.Reply, error) {
+	if m, ok := msg.(*message.ApplyJournal); ok && m.RecordRef == b.down {
+		return nil, errors.New("executor is unavailable")
+	}
+	return b.testMessageBus.Send(msg)
+}
+
+func TestSendJournalContinuesOnError(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	dataRef := core.NewRefFromBase58("someObject")
+	lr.MessageBus = &journalDownBus{testMessageBus: testMessageBus{LogicRunner: lr}, down: dataRef}
+
+	trace := core.CallTrace{{Object: testutils.RandomRef()}}
+	for _, ref := range []core.RecordRef{dataRef, je.nested} {
+		lr.keepJournal(ref, trace.Extend(core.TraceStep{Object: ref}), core.JournalEntry{
+			Type: core.JournalEntryUpdate, Object: ref, Memory: []byte("oldData"),
+		})
+	}
+
+	err := lr.sendJournal(trace, []core.RecordRef{dataRef, je.nested}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), dataRef.String())
+	assert.Equal(t, []byte("oldData"), am.Objects[je.nested].Data, "journal should be sent after failed send")
+	_, ok := lr.GetContext(je.nested)
+	assert.False(t, ok)
+}
+
+func TestHeldObjectExpires(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	lr, am, je := prepareJournalTest(t)
+	pulse := core.PulseNumber(core.FirstPulseNumber)
+	lr.refreshCaseBind(core.Pulse{PulseNumber: pulse})
+
+	trace := core.CallTrace{{Object: testutils.RandomRef()}, {Object: je.nested}}
+	lr.keepJournal(je.nested, trace, core.JournalEntry{
+		Type: core.JournalEntryUpdate, Object: je.nested, Memory: []byte("oldData"),
+	})
+
+	err := lr.handOffContexts(pulse, pulse+1)
+	require.NoError(t, err)
+	_, ok := lr.GetContext(je.nested)
+	assert.True(t, ok, "object is held until the end of the next pulse")
+
+	err = lr.handOffContexts(pulse+1, pulse+2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("oldData"), am.Objects[je.nested].Data, "changes of expired tree should be reverted")
+	_, ok = lr.GetContext(je.nested)
+	assert.False(t, ok, "object should be released when holding expires")
+}
+
+type migrationExecutor struct {
+	t          *testing.T
+	migrations map[core.RecordRef]string
+	migrated   []string
+}
+
+func (e *migrationExecutor) Stop() error { return nil }
+
+func (e *migrationExecutor) CallMethod(ctx *core.LogicCallContext, code core.RecordRef, data []byte, method string, args core.Arguments) ([]byte, core.Arguments, error) {
+	return data, data, nil
+}
+
+func (e *migrationExecutor) CallConstructor(ctx *core.LogicCallContext, code core.RecordRef, name string, args core.Arguments) ([]byte, error) {
+	assert.Equal(e.t, MigrationFunc, name)
+	old := goplugintestutils.CBORUnMarshal(e.t, args).([]interface{})[0].(string)
+	e.migrated = append(e.migrated, e.migrations[code])
+	return goplugintestutils.CBORMarshal(e.t, old+" "+e.migrations[code]), nil
+}
+
+func TestObjectMigration(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	am := goplugintestutils.NewTestArtifactManager()
+	lr, err := NewLogicRunner(&configuration.LogicRunner{})
+	assert.NoError(t, err)
+	lr.Start(core.Components{
+		Ledger:     &testLedger{am: am},
+		MessageBus: &testMessageBus{LogicRunner: lr},
+	})
+
+	codeRef := testutils.RandomRef()
+	classRef := testutils.RandomRef()
+	dataRef := testutils.RandomRef()
+	am.Codes[codeRef] = &goplugintestutils.TestCodeDescriptor{ARef: codeRef, AMachineType: core.MachineTypeGoPlugin}
+	_, err = am.ActivateClass(core.RecordRef{}, classRef, codeRef, "")
+	assert.NoError(t, err)
+	_, err = am.ActivateObject(core.RecordRef{}, dataRef, classRef, core.RecordRef{}, goplugintestutils.CBORMarshal(t, "v1"))
+	assert.NoError(t, err)
+
+	me := &migrationExecutor{t: t, migrations: make(map[core.RecordRef]string)}
+	err = lr.RegisterExecutor(core.MachineTypeGoPlugin, me)
+	assert.NoError(t, err)
+
+	var state *core.RecordID
+	for _, name := range []string{"v2", "v3"} {
+		migrationRef := testutils.RandomRef()
+		me.migrations[migrationRef] = name
+		state, err = am.UpdateClass(core.RecordRef{}, core.RecordRef{}, classRef, codeRef, []core.RecordRef{migrationRef})
+		assert.NoError(t, err)
+	}
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Get", Immutable: true})
+	assert.NoError(t, err)
+	assert.Equal(t, goplugintestutils.CBORMarshal(t, "v1"), am.Objects[dataRef].Data, "immutable call shouldn't save migrated object")
+
+	resp, err := lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Get"})
+	assert.NoError(t, err)
+	expected := goplugintestutils.CBORMarshal(t, "v1 v2 v3")
+	assert.Equal(t, expected, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, expected, am.Objects[dataRef].Data)
+	assert.Equal(t, state, am.Objects[dataRef].ClassState)
+
+	_, err = lr.Execute(&message.CallMethod{ObjectRef: dataRef, Method: "Get"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v2", "v3", "v2", "v3"}, me.migrated, "migrated object shouldn't be migrated again")
+}
+
 func TestContractCallingContract(t *testing.T) {
 	if parallel {
 		t.Parallel()
@@ -313,14 +1042,23 @@ type One struct {
 
 func (r *One) Hello(s string) string {
 	holder := two.New()
-	friend := holder.AsChild(r.GetReference())
-	res := friend.Hello(s)
+	friend, err := holder.AsChild(r.GetReference())
+	if err != nil {
+		panic(err)
+	}
+	res, err := friend.Hello(s)
+	if err != nil {
+		panic(err)
+	}
 	r.Friend = friend.GetReference()
 	return "Hi, " + s + "! Two said: " + res
 }
 
 func (r *One) Again(s string) string {
-	res := two.GetObject(r.Friend).Hello(s)
+	res, err := two.GetObject(r.Friend).Hello(s)
+	if err != nil {
+		panic(err)
+	}
 	return "Hi, " + s + "! Two said: " + res
 }
 
@@ -434,16 +1172,29 @@ type One struct {
 
 func (r *One) Hello(s string) string {
 	holder := two.New()
-	friend := holder.AsDelegate(r.GetReference())
+	friend, err := holder.AsDelegate(r.GetReference())
+	if err != nil {
+		panic(err)
+	}
 
-	res := friend.Hello(s)
+	res, err := friend.Hello(s)
+	if err != nil {
+		panic(err)
+	}
 
 	return "Hi, " + s + "! Two said: " + res
 }
 
 func (r *One) HelloFromDelegate(s string) string {
-	friend := two.GetImplementationFrom(r.GetReference())
-	return friend.Hello(s)
+	friend, err := two.GetImplementationFrom(r.GetReference())
+	if err != nil {
+		panic(err)
+	}
+	res, err := friend.Hello(s)
+	if err != nil {
+		panic(err)
+	}
+	return res
 }
 `
 
@@ -525,8 +1276,14 @@ type One struct {
 
 func (r *One) Hello() {
 	holder := two.New()
-	friend := holder.AsDelegate(r.GetReference())
-	friend.HelloNoWait()
+	friend, err := holder.AsDelegate(r.GetReference())
+	if err != nil {
+		panic(err)
+	}
+	err = friend.HelloNoWait()
+	if err != nil {
+		panic(err)
+	}
 }
 `
 
@@ -725,7 +1482,10 @@ type Contract struct {
 func (c *Contract) NewChilds(cnt int) int {
 	s := 0
 	for i := 1; i < cnt; i++ {
-        child.New(i).AsChild(c.GetReference())
+		_, err := child.New(i).AsChild(c.GetReference())
+		if err != nil {
+			panic(err)
+		}
 		s += i
 	} 
 	return s
@@ -739,7 +1499,11 @@ func (c *Contract) SumChilds() int {
 	}
 	for _, chref := range childs {
 		o := child.GetObject(chref)
-		s += o.GetNum()
+		num, err := o.GetNum()
+		if err != nil {
+			panic(err)
+		}
+		s += num
 	}
 	return s
 }
@@ -810,6 +1574,104 @@ func New(n int) *Child {
 	assert.Equal(t, []interface{}([]interface{}{uint64(45)}), r)
 }
 
+func TestClassUpgrade(t *testing.T) {
+	if parallel {
+		t.Parallel()
+	}
+	goContract := `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type Contract struct {
+	foundation.BaseContract
+	Balance int
+}
+
+func (c *Contract) Get() int {
+	return c.Balance
+}
+`
+	goContractV2 := `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type Contract struct {
+	foundation.BaseContract
+	Amount   int
+	Currency string
+}
+
+func (c *Contract) Get() int {
+	return c.Amount
+}
+
+func (c *Contract) GetCurrency() string {
+	return c.Currency
+}
+`
+	goMigration := `
+package main
+
+import "github.com/insolar/insolar/logicrunner/goplugin/foundation"
+
+type OldContract struct {
+	Balance int
+}
+
+type Contract struct {
+	foundation.BaseContract
+	Amount   int
+	Currency string
+}
+
+func Migrate(old OldContract) *Contract {
+	return &Contract{Amount: old.Balance * 100, Currency: "cents"}
+}
+`
+	lr, am, cb, cleaner := PrepareLrAmCb(t)
+	defer cleaner()
+
+	err := cb.Build(map[string]string{"contract": goContract})
+	assert.NoError(t, err)
+
+	contract, err := am.RegisterRequest(&message.CallConstructor{ClassRef: testutils.RandomRef()})
+	assert.NoError(t, err)
+	_, err = am.ActivateObject(
+		core.RecordRef{}, *contract, *cb.Classes["contract"], *am.GenesisRef(),
+		goplugintestutils.CBORMarshal(t, map[string]int{"Balance": 5}),
+	)
+	assert.NoError(t, err, "create contract")
+
+	err = cb.Update("contract", goContractV2, goMigration)
+	assert.NoError(t, err)
+
+	resp, err := lr.Execute(&message.CallMethod{
+		ObjectRef: *contract,
+		Method:    "GetCurrency",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{}),
+	})
+	assert.NoError(t, err, "contract call")
+	r := goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, []interface{}{"cents"}, r)
+
+	objDesc, err := am.GetObject(*contract, nil)
+	assert.NoError(t, err)
+	classDesc, err := am.GetClass(*cb.Classes["contract"], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, classDesc.StateID(), objDesc.ClassStateID(), "migrated object should be stored with the new class state")
+
+	resp, err = lr.Execute(&message.CallMethod{
+		ObjectRef: *contract,
+		Method:    "Get",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{}),
+	})
+	assert.NoError(t, err, "contract call")
+	r = goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
+	assert.Equal(t, []interface{}{uint64(500)}, r)
+}
+
 func TestFailValidate(t *testing.T) {
 	if parallel {
 		t.Parallel()
@@ -873,17 +1735,37 @@ type One struct {
 
 func (r *One) AnError() error {
 	holder := two.New()
-	friend := holder.AsChild(r.GetReference())
+	friend, err := holder.AsChild(r.GetReference())
+	if err != nil {
+		return err
+	}
 
 	return friend.AnError()
 }
 
 func (r *One) NoError() error {
 	holder := two.New()
-	friend := holder.AsChild(r.GetReference())
+	friend, err := holder.AsChild(r.GetReference())
+	if err != nil {
+		return err
+	}
 
 	return friend.NoError()
 }
+
+func (r *One) FailedCallee() string {
+	holder := two.New()
+	friend, err := holder.AsChild(r.GetReference())
+	if err != nil {
+		return err.Error()
+	}
+
+	_, err = friend.Panic()
+	if err == nil {
+		return "no error"
+	}
+	return "handled: " + err.Error()
+}
 `
 
 	var contractTwoCode = `
@@ -907,6 +1789,9 @@ func (r *Two) AnError() error {
 func (r *Two) NoError() error {
 	return nil
 }
+func (r *Two) Panic() string {
+	panic("callee panicked")
+}
 `
 	lr, am, cb, cleaner := PrepareLrAmCb(t)
 	defer cleaner()
@@ -949,6 +1834,17 @@ func (r *Two) NoError() error {
 
 	r := goplugintestutils.CBORUnMarshal(t, resp.(*reply.CallMethod).Result)
 	assert.Equal(t, []interface{}([]interface{}{nil}), r)
+
+	resp, err = lr.Execute(&message.CallMethod{
+		ObjectRef: *contract,
+		Method:    "FailedCallee",
+		Arguments: goplugintestutils.CBORMarshal(t, []interface{}{}),
+	})
+	assert.NoError(t, err, "caller shouldn't fail when callee fails")
+
+	res2 := goplugintestutils.CBORUnMarshalToSlice(t, resp.(*reply.CallMethod).Result)
+	assert.Contains(t, res2[0], "handled: ")
+	assert.Contains(t, res2[0], "callee panicked")
 }
 
 func TestNilResult(t *testing.T) {
@@ -969,9 +1865,16 @@ type One struct {
 
 func (r *One) Hello() *string {
 	holder := two.New()
-	friend := holder.AsChild(r.GetReference())
+	friend, err := holder.AsChild(r.GetReference())
+	if err != nil {
+		panic(err)
+	}
 
-	return friend.Hello()
+	res, err := friend.Hello()
+	if err != nil {
+		panic(err)
+	}
+	return res
 }
 `
 
@@ -1027,7 +1930,9 @@ type Caller struct {
 	t      *testing.T
 }
 
-func (s *Caller) SignedCall(ref core.RecordRef, delegate core.RecordRef, method string, params []interface{}, resp []interface{}) {
+// SignedCall calls the method through the member and returns results of the method without the last one, the last
+// result is an error and it's checked to be nil
+func (s *Caller) SignedCall(ref core.RecordRef, delegate core.RecordRef, method string, params []interface{}) []interface{} {
 	seed := make([]byte, 32)
 	_, err := rand.Read(seed)
 	assert.NoError(s.t, err)
@@ -1044,13 +1949,17 @@ func (s *Caller) SignedCall(ref core.RecordRef, delegate core.RecordRef, method
 		Method:    "AuthorizedCall",
 		Arguments: goplugintestutils.CBORMarshal(s.t, []interface{}{ref, delegate, method, buf, seed, sign}),
 	})
-	assert.NoError(s.t, err, "contract call")
-	result := goplugintestutils.CBORUnMarshal(s.t, res.(*reply.CallMethod).Result).([]interface{})
-	assert.Nil(s.t, result[1])
-	if result[0] != nil {
-		ch := new(codec.CborHandle)
-		err = codec.NewDecoderBytes(result[0].([]byte), ch).Decode(&resp)
-	}
+	require.NoError(s.t, err, "contract call")
+	result := goplugintestutils.CBORUnMarshalToSlice(s.t, res.(*reply.CallMethod).Result)
+	require.Nil(s.t, result[1], "authorized call of %s", method)
+
+	var resp []interface{}
+	ch := new(codec.CborHandle)
+	err = codec.NewDecoderBytes(result[0].([]byte), ch).Decode(&resp)
+	require.NoError(s.t, err)
+	require.NotEmpty(s.t, resp, "results of %s", method)
+	require.Nil(s.t, resp[len(resp)-1], "error of %s", method)
+	return resp[:len(resp)-1]
 }
 
 func TestRootDomainContract(t *testing.T) {
@@ -1109,8 +2018,7 @@ func TestRootDomainContract(t *testing.T) {
 	member1PubKey, err := cryptoHelper.ExportPublicKey(&member1Key.PublicKey)
 	assert.NoError(t, err)
 
-	res1 := []interface{}{""}
-	root.SignedCall(*rootDomainRef, core.RecordRef{}, "CreateMember", []interface{}{"Member1", member1PubKey}, res1)
+	res1 := root.SignedCall(*rootDomainRef, core.RecordRef{}, "CreateMember", []interface{}{"Member1", member1PubKey})
 	member1Ref := res1[0].(string)
 	assert.NotEqual(t, "", member1Ref)
 
@@ -1120,25 +2028,22 @@ func TestRootDomainContract(t *testing.T) {
 	member2PubKey, err := cryptoHelper.ExportPublicKey(&member2Key.PublicKey)
 	assert.NoError(t, err)
 
-	res2 := []interface{}{""}
-	root.SignedCall(*rootDomainRef, core.RecordRef{}, "CreateMember", []interface{}{"Member2", member2PubKey}, res2)
+	res2 := root.SignedCall(*rootDomainRef, core.RecordRef{}, "CreateMember", []interface{}{"Member2", member2PubKey})
 	member2Ref := res2[0].(string)
 	assert.NotEqual(t, "", member2Ref)
 
 	// Transfer 1 coin from Member1 to Member2
 	member1 := Caller{member1Ref, member1Key, lr, t}
 	z := core.NewRefFromBase58(member2Ref)
-	member1.SignedCall(core.NewRefFromBase58(member1Ref), *cb.Classes["wallet"], "Transfer", []interface{}{1, &z}, nil)
+	member1.SignedCall(core.NewRefFromBase58(member1Ref), *cb.Classes["wallet"], "Transfer", []interface{}{1, &z})
 
 	// Verify Member1 balance
-	res3 := []interface{}{0}
-	root.SignedCall(core.NewRefFromBase58(member1Ref), *cb.Classes["wallet"], "GetTotalBalance", []interface{}{}, res3)
-	assert.Equal(t, 999, res3[0])
+	res3 := root.SignedCall(core.NewRefFromBase58(member1Ref), *cb.Classes["wallet"], "GetTotalBalance", []interface{}{})
+	assert.Equal(t, uint64(999), res3[0])
 
 	// Verify Member2 balance
-	res4 := []interface{}{0}
-	root.SignedCall(core.NewRefFromBase58(member2Ref), *cb.Classes["wallet"], "GetTotalBalance", []interface{}{}, res4)
-	assert.Equal(t, 1001, res4[0])
+	res4 := root.SignedCall(core.NewRefFromBase58(member2Ref), *cb.Classes["wallet"], "GetTotalBalance", []interface{}{})
+	assert.Equal(t, uint64(1001), res4[0])
 }
 
 func BenchmarkContractCall(b *testing.B) {
@@ -1153,9 +2058,13 @@ type Parent struct {
 	foundation.BaseContract
 }
 
-func (c *Parent) CCC(ref *core.RecordRef) int {	
-	o := child.GetObject(*ref)	
-	return o.GetNum()
+func (c *Parent) CCC(ref *core.RecordRef) int {
+	o := child.GetObject(*ref)
+	num, err := o.GetNum()
+	if err != nil {
+		panic(err)
+	}
+	return num
 }
 `
 	goChild := `
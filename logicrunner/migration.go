@@ -0,0 +1,77 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"github.com/insolar/insolar/core"
+	"github.com/pkg/errors"
+)
+
+// Class upgrade doesn't touch existing objects. Object memory is tagged with the class state it was written for and
+// is migrated lazily on the next call: migrations of every class state amended since then are applied in order and
+// the result is stored as a new object state tagged with the latest class state.
+
+// MigrationFunc is a name of the function in migration code that converts memory of the previous class state,
+// e.g. "func Migrate(old OldContract) *Contract"
+const MigrationFunc = "Migrate"
+
+// ErrMigrationNotFound is returned when object's class state is not in the history of its class
+var ErrMigrationNotFound = errors.New("object's class state is not in the class history")
+
+// pendingMigrations collects migrations required to bring object to the latest class state, oldest first
+func (lr *LogicRunner) pendingMigrations(objDesc core.ObjectDescriptor, classDesc core.ClassDescriptor) ([]core.RecordRef, error) {
+	objState := objDesc.ClassStateID()
+	if objState == nil {
+		return nil, nil
+	}
+
+	head := *classDesc.HeadRef()
+	var migrations []core.RecordRef
+	for *classDesc.StateID() != *objState {
+		migrations = append(append([]core.RecordRef{}, classDesc.Migrations()...), migrations...)
+
+		prev := classDesc.PrevStateID()
+		if prev == nil {
+			return nil, ErrMigrationNotFound
+		}
+		state := core.ComposeRecordRef(head.Domain(), *prev)
+		var err error
+		classDesc, err = lr.ArtifactManager.GetClass(head, &state)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't get previous class state")
+		}
+	}
+	return migrations, nil
+}
+
+// migrate runs migrations on object memory one by one
+func migrate(ctx core.LogicCallContext, executor core.MachineLogicExecutor, migrations []core.RecordRef, memory []byte) ([]byte, error) {
+	for _, migration := range migrations {
+		var err error
+		memory, err = executor.CallConstructor(&ctx, migration, MigrationFunc, migrationArgs(memory))
+		if err != nil {
+			return nil, errors.Wrapf(err, "migration %s failed", migration)
+		}
+	}
+	return memory, nil
+}
+
+// migrationArgs makes arguments list of the only argument, the memory is passed as is without decoding
+func migrationArgs(memory []byte) core.Arguments {
+	const cborArrayOfOne = 0x81
+	return append(core.Arguments{cborArrayOfOne}, memory...)
+}
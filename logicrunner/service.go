@@ -30,6 +30,7 @@ import (
 	"github.com/insolar/insolar/log"
 	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
 )
 
 // StartRPC starts RPC server for isolated executors to use
@@ -76,6 +77,21 @@ func (gpr *RPC) GetCode(req rpctypes.UpGetCodeReq, reply *rpctypes.UpGetCodeResp
 	return nil
 }
 
+// GetCodeHash is an RPC retrieving hash of a code by its reference, runners use it to verify cached code
+func (gpr *RPC) GetCodeHash(req rpctypes.UpGetCodeHashReq, reply *rpctypes.UpGetCodeHashResp) error {
+	codeDescriptor, err := gpr.lr.ArtifactManager.GetCode(req.Code)
+	if err != nil {
+		return err
+	}
+	code, err := codeDescriptor.Code()
+	if err != nil {
+		return err
+	}
+	hash := sha3.Sum224(code)
+	reply.Hash = hash[:]
+	return nil
+}
+
 var serial uint64 = 1
 
 // MakeBaseMessage makes base of logicrunner event from base of up request
@@ -83,11 +99,16 @@ func MakeBaseMessage(req rpctypes.UpBaseReq) message.BaseLogicMessage {
 	return message.BaseLogicMessage{
 		Caller: req.Me,
 		Nonce:  atomicLoadAndIncrementUint64(&serial),
+		Depth:  req.Depth + 1,
+		Trace:  req.Trace,
 	}
 }
 
 // RouteCall routes call from a contract to a contract through event bus.
 func (gpr *RPC) RouteCall(req rpctypes.UpRouteReq, rep *rpctypes.UpRouteResp) error {
+	if req.Immutable && !req.ImmutableMethod {
+		return ErrImmutableCallChangesState
+	}
 	cr, step := gpr.lr.getNextValidationStep(req.Me)
 	if step >= 0 { // validate
 		if core.CaseRecordTypeRouteCall != cr.Type {
@@ -115,6 +136,7 @@ func (gpr *RPC) RouteCall(req rpctypes.UpRouteReq, rep *rpctypes.UpRouteResp) er
 		ObjectRef:        req.Object,
 		Method:           req.Method,
 		Arguments:        req.Arguments,
+		Immutable:        req.ImmutableMethod,
 	}
 
 	res, err := gpr.lr.MessageBus.Send(msg)
@@ -122,8 +144,10 @@ func (gpr *RPC) RouteCall(req rpctypes.UpRouteReq, rep *rpctypes.UpRouteResp) er
 		return errors.Wrap(err, "couldn't dispatch event")
 	}
 
-	rep.Result = res.(*reply.CallMethod).Result
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{
+	re := res.(*reply.CallMethod)
+	rep.Result = re.Result
+	gpr.lr.appendPending(callOf(req.Me, req.Trace), re.Pending...)
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
 		Type:   core.CaseRecordTypeRouteCall,
 		ReqSig: HashInterface(req),
 		Resp:   rep.Result,
@@ -134,6 +158,9 @@ func (gpr *RPC) RouteCall(req rpctypes.UpRouteReq, rep *rpctypes.UpRouteResp) er
 
 // SaveAsChild is an RPC saving data as memory of a contract as child a parent
 func (gpr *RPC) SaveAsChild(req rpctypes.UpSaveAsChildReq, rep *rpctypes.UpSaveAsChildResp) error {
+	if req.Immutable {
+		return ErrImmutableCallChangesState
+	}
 	if gpr.lr.MessageBus == nil {
 		return errors.New("event bus was not set during initialization")
 	}
@@ -166,9 +193,11 @@ func (gpr *RPC) SaveAsChild(req rpctypes.UpSaveAsChildReq, rep *rpctypes.UpSaveA
 		return errors.Wrap(err, "couldn't save new object as child")
 	}
 
-	rep.Reference = res.(*reply.CallConstructor).Object
+	re := res.(*reply.CallConstructor)
+	rep.Reference = re.Object
+	gpr.lr.appendPending(callOf(req.Me, req.Trace), re.Pending...)
 
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
 		Type:   core.CaseRecordTypeSaveAsChild,
 		ReqSig: HashInterface(req),
 		Resp:   rep.Reference,
@@ -219,7 +248,7 @@ func (gpr *RPC) GetObjChildren(req rpctypes.UpGetObjChildrenReq, rep *rpctypes.U
 			rep.Children = append(rep.Children, *r)
 		}
 	}
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{ // bad idea, we can store gadzillion of children
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{ // bad idea, we can store gadzillion of children
 		Type:   core.CaseRecordTypeGetObjChildren,
 		ReqSig: HashInterface(req),
 		Resp:   rep.Children,
@@ -229,6 +258,9 @@ func (gpr *RPC) GetObjChildren(req rpctypes.UpGetObjChildrenReq, rep *rpctypes.U
 
 // SaveAsDelegate is an RPC saving data as memory of a contract as child a parent
 func (gpr *RPC) SaveAsDelegate(req rpctypes.UpSaveAsDelegateReq, rep *rpctypes.UpSaveAsDelegateResp) error {
+	if req.Immutable {
+		return ErrImmutableCallChangesState
+	}
 	cr, step := gpr.lr.getNextValidationStep(req.Me)
 	if step >= 0 { // validate
 		if core.CaseRecordTypeSaveAsDelegate != cr.Type {
@@ -258,8 +290,10 @@ func (gpr *RPC) SaveAsDelegate(req rpctypes.UpSaveAsDelegateReq, rep *rpctypes.U
 		return errors.Wrap(err, "couldn't save new object as delegate")
 	}
 
-	rep.Reference = res.(*reply.CallConstructor).Object
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{
+	re := res.(*reply.CallConstructor)
+	rep.Reference = re.Object
+	gpr.lr.appendPending(callOf(req.Me, req.Trace), re.Pending...)
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
 		Type:   core.CaseRecordTypeSaveAsDelegate,
 		ReqSig: HashInterface(req),
 		Resp:   rep.Reference,
@@ -289,7 +323,7 @@ func (gpr *RPC) GetDelegate(req rpctypes.UpGetDelegateReq, rep *rpctypes.UpGetDe
 		return err
 	}
 	rep.Object = *ref
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
 		Type:   core.CaseRecordTypeGetDelegate,
 		ReqSig: HashInterface(req),
 		Resp:   rep.Object,
@@ -299,6 +333,9 @@ func (gpr *RPC) GetDelegate(req rpctypes.UpGetDelegateReq, rep *rpctypes.UpGetDe
 
 // DeactivateObject is an RPC saving data as memory of a contract as child a parent
 func (gpr *RPC) DeactivateObject(req rpctypes.UpDeactivateObjectReq, rep *rpctypes.UpDeactivateObjectResp) error {
+	if req.Immutable {
+		return ErrImmutableCallChangesState
+	}
 	cr, step := gpr.lr.getNextValidationStep(req.Me)
 	if step >= 0 { // validate
 		if core.CaseRecordTypeDeactivateObject != cr.Type {
@@ -310,18 +347,41 @@ func (gpr *RPC) DeactivateObject(req rpctypes.UpDeactivateObjectReq, rep *rpctyp
 		}
 		return nil
 	}
-	am := gpr.lr.ArtifactManager
-	_, err := am.DeactivateObject(core.RecordRef{}, core.RecordRef{}, req.Object)
-	if err != nil {
-		return err
-	}
-	gpr.lr.addObjectCaseRecord(req.Me, core.CaseRecord{
+	// object is deactivated when the whole call tree is completed
+	gpr.lr.appendJournal(callOf(req.Me, req.Trace), core.JournalEntry{Type: core.JournalEntryDeactivate, Object: req.Object})
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
 		Type:   core.CaseRecordTypeDeactivateObject,
 		ReqSig: HashInterface(req),
 	})
 	return nil
 }
 
+// Emit is an RPC publishing event of a contract, events are stored when the whole call tree is completed
+func (gpr *RPC) Emit(req rpctypes.UpEmitReq, rep *rpctypes.UpEmitResp) error {
+	if req.Immutable {
+		return ErrImmutableCallChangesState
+	}
+	cr, step := gpr.lr.getNextValidationStep(req.Me)
+	if step >= 0 { // validate
+		if core.CaseRecordTypeEmit != cr.Type {
+			return errors.New("Wrong validation type on Emit")
+		}
+		sig := HashInterface(req)
+		if !bytes.Equal(cr.ReqSig, sig) {
+			return errors.New("Wrong validation sig on Emit")
+		}
+		return nil
+	}
+	gpr.lr.appendJournal(callOf(req.Me, req.Trace), core.JournalEntry{
+		Type: core.JournalEntryEmit, Object: req.Me, Name: req.Name, Payload: req.Payload,
+	})
+	gpr.lr.addCallCaseRecord(callOf(req.Me, req.Trace), core.CaseRecord{
+		Type:   core.CaseRecordTypeEmit,
+		ReqSig: HashInterface(req),
+	})
+	return nil
+}
+
 // atomicLoadAndIncrementUint64 performs CAS loop, increments counter and returns old value.
 func atomicLoadAndIncrementUint64(addr *uint64) uint64 {
 	for {
@@ -0,0 +1,231 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/log"
+	"github.com/insolar/insolar/metrics"
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+)
+
+// EventValidationRejected is a name of the event stored on an object when validators reject results of its executor.
+// Payload of the event is CBOR encoded ValidationRejection.
+const EventValidationRejected = "ValidationRejected"
+
+// ValidationRejection describes executor results rejected by validators.
+type ValidationRejection struct {
+	Pulse      core.PulseNumber // pulse the object was executed on
+	Executor   core.RecordRef
+	Steps      int // count of case records of executor results
+	Validators int
+	Errors     map[core.RecordRef]string // errors of validators which didn't pass all the steps
+}
+
+// validationResult is a result of case records validation by a single validator.
+type validationResult struct {
+	sender core.RecordRef
+	msg    *message.ValidationResults
+}
+
+// validation collects results of validators for an object executed in the previous pulse. Results can arrive
+// before executor results, so verdict is reached only when both are present.
+type validation struct {
+	executor  *message.ExecutorResults
+	sender    core.RecordRef // executor which sent results
+	results   []validationResult
+	done      bool
+	confirmed bool
+	age       int // count of pulses passed since validation began
+}
+
+// verdict returns confirmed as true if majority of validators confirmed executor results. Reached is false while
+// neither confirmations nor rejections reach majority and some of the validators have not replied yet.
+func (v *validation) verdict(validators int) (confirmed bool, reached bool) {
+	quorum := validators/2 + 1
+	steps := len(v.executor.CaseRecords)
+	var passed, failed int
+	for _, res := range v.results {
+		if res.msg.Error == "" && res.msg.PassedStepsCount == steps {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	switch {
+	case passed >= quorum:
+		return true, true
+	case failed >= quorum, passed+failed >= validators:
+		return false, true
+	}
+	return false, false
+}
+
+func (lr *LogicRunner) getValidation(ref core.RecordRef) *validation {
+	v, ok := lr.validations[ref]
+	if !ok {
+		v = &validation{}
+		lr.validations[ref] = v
+	}
+	return v
+}
+
+// ExecutorResults saves case records of the previous executor to compare validation results with. Results are
+// accepted only from the Executor of the object on the previous pulse.
+func (lr *LogicRunner) ExecutorResults(signed core.SignedMessage) (core.Reply, error) {
+	msg, ok := signed.Message().(*message.ExecutorResults)
+	if !ok {
+		return nil, errors.New("ExecutorResults( ! message.ExecutorResults )")
+	}
+	if err := lr.checkPreviousExecutor(msg.RecordRef, signed.GetSender()); err != nil {
+		return nil, err
+	}
+
+	lr.validationsMutex.Lock()
+	defer lr.validationsMutex.Unlock()
+	v := lr.getValidation(msg.RecordRef)
+	if v.executor != nil {
+		return nil, errors.Errorf("executor results of %s are already received", msg.RecordRef)
+	}
+	v.executor = msg
+	v.sender = signed.GetSender()
+	err := lr.checkValidation(msg.RecordRef, v)
+	if err != nil {
+		return nil, err
+	}
+	return &reply.OK{}, nil
+}
+
+// ProcessValidationResults collects result of a validator and reaches a verdict on executor results when majority
+// of validators agree. Message sender is checked to be a validator.
+func (lr *LogicRunner) ProcessValidationResults(signed core.SignedMessage) (core.Reply, error) {
+	msg, ok := signed.Message().(*message.ValidationResults)
+	if !ok {
+		return nil, errors.New("ProcessValidationResults( ! message.ValidationResults )")
+	}
+	sender := signed.GetSender()
+
+	lr.validationsMutex.Lock()
+	defer lr.validationsMutex.Unlock()
+	v := lr.getValidation(msg.RecordRef)
+	for _, res := range v.results {
+		if res.sender.Equal(sender) {
+			return nil, errors.Errorf("validation results of %s are already received from %s", msg.RecordRef, sender)
+		}
+	}
+	v.results = append(v.results, validationResult{sender: sender, msg: msg})
+	err := lr.checkValidation(msg.RecordRef, v)
+	if err != nil {
+		return nil, err
+	}
+	return &reply.OK{}, nil
+}
+
+// checkValidation reports verdict on executor results if it is reached. Results of senders which are not validators
+// for the executor results pulse are dropped.
+func (lr *LogicRunner) checkValidation(ref core.RecordRef, v *validation) error {
+	if v.executor == nil || v.done {
+		return nil
+	}
+
+	pulse := v.executor.Pulse.PulseNumber
+	validators, err := lr.JetCoordinator.QueryRole(core.RoleVirtualValidator, ref, pulse)
+	if err != nil {
+		return errors.Wrap(err, "failed to get validators")
+	}
+	if len(validators) == 0 {
+		v.done = true
+		log.Debugf("executor results of %s are not validated: no validators", ref)
+		return nil
+	}
+	authorized := v.results[:0]
+	for _, res := range v.results {
+		ok, err := lr.JetCoordinator.IsAuthorized(core.RoleVirtualValidator, ref, pulse, res.sender)
+		if err != nil {
+			return errors.Wrap(err, "failed to check validator")
+		}
+		if !ok {
+			log.Warnf("validation results of %s from %s are dropped: sender is not a validator", ref, res.sender)
+			continue
+		}
+		authorized = append(authorized, res)
+	}
+	v.results = authorized
+
+	confirmed, reached := v.verdict(len(validators))
+	if !reached {
+		return nil
+	}
+	v.done = true
+	v.confirmed = confirmed
+	if confirmed {
+		log.Debugf("executor results of %s are confirmed by validators", ref)
+		metrics.LogicRunnerValidationVerdictsTotal.WithLabelValues("confirmed").Inc()
+		return nil
+	}
+	log.Errorf(
+		"executor results of %s are rejected by validators: %d of %d validators replied",
+		ref, len(v.results), len(validators),
+	)
+	metrics.LogicRunnerValidationVerdictsTotal.WithLabelValues("rejected").Inc()
+	if err := lr.storeRejection(ref, v, len(validators)); err != nil {
+		log.Errorf("failed to store rejection of executor results of %s: %s", ref, err)
+	}
+	return nil
+}
+
+// storeRejection stores rejection of executor results as an event of the object, so it outlives the validation.
+func (lr *LogicRunner) storeRejection(ref core.RecordRef, v *validation, validators int) error {
+	rejection := ValidationRejection{
+		Pulse:      v.executor.Pulse.PulseNumber,
+		Executor:   v.sender,
+		Steps:      len(v.executor.CaseRecords),
+		Validators: validators,
+		Errors:     map[core.RecordRef]string{},
+	}
+	for _, res := range v.results {
+		if res.msg.Error != "" || res.msg.PassedStepsCount != rejection.Steps {
+			rejection.Errors[res.sender] = res.msg.Error
+		}
+	}
+	var payload []byte
+	err := codec.NewEncoderBytes(&payload, new(codec.CborHandle)).Encode(rejection)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode rejection")
+	}
+	_, err = lr.ArtifactManager.EmitEvent(core.RecordRef{}, core.RecordRef{}, ref, EventValidationRejected, payload)
+	return err
+}
+
+// refreshValidations drops validations which have not reached a verdict during a whole pulse.
+func (lr *LogicRunner) refreshValidations() {
+	lr.validationsMutex.Lock()
+	defer lr.validationsMutex.Unlock()
+	for ref, v := range lr.validations {
+		v.age++
+		if v.age < 2 {
+			continue
+		}
+		if !v.done {
+			log.Warnf("validation of %s is dropped without verdict: %d validators replied", ref, len(v.results))
+		}
+		delete(lr.validations, ref)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package logicrunner
+
+import (
+	"testing"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/logicrunner/goplugin/rpctypes"
+	"github.com/insolar/insolar/testutils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+type testJetCoordinator struct {
+	validators []core.RecordRef
+	executors  map[core.PulseNumber][]core.RecordRef
+}
+
+func (jc *testJetCoordinator) IsAuthorized(
+	role core.JetRole, obj core.RecordRef, pulse core.PulseNumber, node core.RecordRef,
+) (bool, error) {
+	if role == core.RoleVirtualExecutor && jc.executors != nil {
+		for _, e := range jc.executors[pulse] {
+			if e.Equal(node) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, v := range jc.validators {
+		if v.Equal(node) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (jc *testJetCoordinator) QueryRole(
+	role core.JetRole, obj core.RecordRef, pulse core.PulseNumber,
+) ([]core.RecordRef, error) {
+	if role == core.RoleVirtualExecutor && jc.executors != nil {
+		return jc.executors[pulse], nil
+	}
+	return jc.validators, nil
+}
+
+func (jc *testJetCoordinator) QueryRoleNodes(role core.JetRole, pulse core.PulseNumber) ([]core.RecordRef, error) {
+	return jc.validators, nil
+}
+
+// eventsArtifactManager records events emitted on objects
+type eventsArtifactManager struct {
+	core.ArtifactManager
+	events map[core.RecordRef][]core.Event
+}
+
+func (am *eventsArtifactManager) EmitEvent(
+	domain, request, obj core.RecordRef, name string, payload []byte,
+) (*core.RecordID, error) {
+	if am.events == nil {
+		am.events = map[core.RecordRef][]core.Event{}
+	}
+	am.events[obj] = append(am.events[obj], core.Event{Name: name, Payload: payload})
+	return &core.RecordID{}, nil
+}
+
+func TestLogicRunner_ValidationVerdict(t *testing.T) {
+	validators := []core.RecordRef{testutils.RandomRef(), testutils.RandomRef(), testutils.RandomRef()}
+	records := []core.CaseRecord{{Type: core.CaseRecordTypeStart}, {Type: core.CaseRecordTypeResult}}
+	executor := testutils.RandomRef()
+	newLR := func() *LogicRunner {
+		lr, err := NewLogicRunner(&configuration.LogicRunner{})
+		require.NoError(t, err)
+		lr.JetCoordinator = &testJetCoordinator{
+			validators: validators,
+			executors:  map[core.PulseNumber][]core.RecordRef{core.FirstPulseNumber: {executor}},
+		}
+		lr.ArtifactManager = &eventsArtifactManager{}
+		lr.refreshCaseBind(core.Pulse{PulseNumber: core.FirstPulseNumber})
+		return lr
+	}
+	executorResults := func(sender core.RecordRef, ref core.RecordRef) core.SignedMessage {
+		return &message.SignedMessage{
+			Msg:    &message.ExecutorResults{RecordRef: ref, CaseRecords: records},
+			Sender: sender,
+		}
+	}
+	results := func(sender core.RecordRef, ref core.RecordRef, steps int, err string) core.SignedMessage {
+		return &message.SignedMessage{
+			Msg:    &message.ValidationResults{RecordRef: ref, PassedStepsCount: steps, Error: err},
+			Sender: sender,
+		}
+	}
+
+	t.Run("confirmed by majority", func(t *testing.T) {
+		lr := newLR()
+		ref := testutils.RandomRef()
+		_, err := lr.ExecutorResults(executorResults(executor, ref))
+		require.NoError(t, err)
+
+		_, err = lr.ProcessValidationResults(results(validators[0], ref, 2, ""))
+		require.NoError(t, err)
+		assert.False(t, lr.validations[ref].done)
+		_, err = lr.ProcessValidationResults(results(validators[1], ref, 1, "result mismatch"))
+		require.NoError(t, err)
+		assert.False(t, lr.validations[ref].done)
+		_, err = lr.ProcessValidationResults(results(validators[2], ref, 2, ""))
+		require.NoError(t, err)
+		assert.True(t, lr.validations[ref].done)
+		assert.True(t, lr.validations[ref].confirmed)
+		assert.Empty(t, lr.ArtifactManager.(*eventsArtifactManager).events)
+	})
+
+	t.Run("rejected by majority before executor results", func(t *testing.T) {
+		lr := newLR()
+		ref := testutils.RandomRef()
+		_, err := lr.ProcessValidationResults(results(validators[0], ref, 1, "body mismatch"))
+		require.NoError(t, err)
+		_, err = lr.ProcessValidationResults(results(validators[1], ref, 2, "result mismatch"))
+		require.NoError(t, err)
+		assert.False(t, lr.validations[ref].done)
+
+		_, err = lr.ExecutorResults(executorResults(executor, ref))
+		require.NoError(t, err)
+		assert.True(t, lr.validations[ref].done)
+		assert.False(t, lr.validations[ref].confirmed)
+
+		events := lr.ArtifactManager.(*eventsArtifactManager).events[ref]
+		require.Len(t, events, 1, "rejection should be stored")
+		assert.Equal(t, EventValidationRejected, events[0].Name)
+		var rejection ValidationRejection
+		require.NoError(t, codec.NewDecoderBytes(events[0].Payload, new(codec.CborHandle)).Decode(&rejection))
+		assert.Equal(t, ValidationRejection{
+			Executor:   executor,
+			Steps:      len(records),
+			Validators: len(validators),
+			Errors:     map[core.RecordRef]string{validators[0]: "body mismatch", validators[1]: "result mismatch"},
+		}, rejection)
+	})
+
+	t.Run("unauthorized and duplicated results", func(t *testing.T) {
+		lr := newLR()
+		ref := testutils.RandomRef()
+		_, err := lr.ExecutorResults(executorResults(validators[0], ref))
+		assert.Error(t, err, "executor results are accepted only from previous executor")
+		_, err = lr.ExecutorResults(executorResults(executor, ref))
+		require.NoError(t, err)
+
+		_, err = lr.ProcessValidationResults(results(testutils.RandomRef(), ref, 2, ""))
+		require.NoError(t, err)
+		assert.Empty(t, lr.validations[ref].results)
+
+		_, err = lr.ProcessValidationResults(results(validators[0], ref, 2, ""))
+		require.NoError(t, err)
+		_, err = lr.ProcessValidationResults(results(validators[0], ref, 2, ""))
+		assert.Error(t, err)
+		assert.False(t, lr.validations[ref].done)
+	})
+
+	t.Run("dropped after pulse without verdict", func(t *testing.T) {
+		lr := newLR()
+		ref := testutils.RandomRef()
+		_, err := lr.ProcessValidationResults(results(validators[0], ref, 2, ""))
+		require.NoError(t, err)
+
+		lr.refreshValidations()
+		assert.Contains(t, lr.validations, ref)
+		lr.refreshValidations()
+		assert.NotContains(t, lr.validations, ref)
+	})
+}
+
+// executorDownBus fails to deliver executor results to the new executor and records sent messages
+type executorDownBus struct {
+	testMessageBus
+	sent []core.Message
+}
+
+func (b *executorDownBus) Send(msg core.Message) (core.Reply, error) {
+	b.sent = append(b.sent, msg)
+	if msg.Type() == core.TypeExecutorResults {
+		return nil, errors.New("new executor is unavailable")
+	}
+	return &reply.OK{}, nil
+}
+
+func TestLogicRunner_OnPulseNewExecutorUnavailable(t *testing.T) {
+	bus := &executorDownBus{}
+	lr, err := NewLogicRunner(&configuration.LogicRunner{})
+	require.NoError(t, err)
+	lr.MessageBus = bus
+	lr.JetCoordinator = &testJetCoordinator{}
+	lr.caseBind.Records[testutils.RandomRef()] = []core.CaseRecord{{Type: core.CaseRecordTypeStart}}
+	lr.caseBind.Records[testutils.RandomRef()] = []core.CaseRecord{{Type: core.CaseRecordTypeStart}}
+
+	err = lr.OnPulse(core.Pulse{PulseNumber: core.FirstPulseNumber})
+	assert.NoError(t, err)
+	assert.Len(t, bus.sent, 4, "results of every object should be sent")
+}
+
+// validatorsDownBus fails to deliver messages to validators and records messages to the new executor
+type validatorsDownBus struct {
+	testMessageBus
+	results []core.Message
+}
+
+func (b *validatorsDownBus) Send(msg core.Message) (core.Reply, error) {
+	if msg.Type() == core.TypeValidateCaseBind {
+		return nil, errors.New("validators are unavailable")
+	}
+	b.results = append(b.results, msg)
+	return &reply.OK{}, nil
+}
+
+func TestLogicRunner_OnPulseValidatorsUnavailable(t *testing.T) {
+	bus := &validatorsDownBus{}
+	lr, err := NewLogicRunner(&configuration.LogicRunner{})
+	require.NoError(t, err)
+	lr.MessageBus = bus
+	lr.JetCoordinator = &testJetCoordinator{}
+	lr.caseBind.Records[testutils.RandomRef()] = []core.CaseRecord{{Type: core.CaseRecordTypeStart}}
+
+	err = lr.OnPulse(core.Pulse{PulseNumber: core.FirstPulseNumber})
+	assert.NoError(t, err)
+	assert.Len(t, bus.results, 1, "executor results should be sent anyway")
+}
+
+func TestHashInterfaceIgnoresTrace(t *testing.T) {
+	obj := testutils.RandomRef()
+	req := rpctypes.UpRouteReq{
+		UpBaseReq: rpctypes.UpBaseReq{Me: obj, Trace: core.CallTrace{{Object: obj, Nonce: 1}}},
+		Method:    "Get",
+	}
+	replayed := req
+	replayed.Trace = core.CallTrace{{Object: obj, Nonce: 2}}
+	assert.Equal(t, HashInterface(req), HashInterface(replayed), "validators replay root calls with another nonce")
+
+	replayed.Method = "Set"
+	assert.NotEqual(t, HashInterface(req), HashInterface(replayed))
+}
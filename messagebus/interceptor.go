@@ -0,0 +1,118 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/log"
+	"github.com/insolar/insolar/metrics"
+)
+
+// SendFunc sends message and returns its reply. Interceptors wrap it.
+type SendFunc func(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error)
+
+// Interceptor wraps sending of messages and handling of delivered messages, e.g. for logging, metrics, tracing or
+// fault injection.
+type Interceptor interface {
+	// InterceptSend returns function which sends message through next.
+	InterceptSend(next SendFunc) SendFunc
+	// InterceptDeliver returns handler which handles delivered message through next.
+	InterceptDeliver(next core.MessageHandler) core.MessageHandler
+}
+
+// MetricsInterceptor collects Prometheus timings and errors of sent and delivered messages per message type.
+type MetricsInterceptor struct{}
+
+// InterceptSend implementation of Interceptor interface.
+func (MetricsInterceptor) InterceptSend(next SendFunc) SendFunc {
+	return func(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error) {
+		start := time.Now()
+		rep, err := next(ctx, msg, opts)
+		metrics.MessageBusSendTime.WithLabelValues(msg.Type().String()).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.MessageBusSendErrorsTotal.WithLabelValues(msg.Type().String()).Inc()
+		}
+		return rep, err
+	}
+}
+
+// InterceptDeliver implementation of Interceptor interface.
+func (MetricsInterceptor) InterceptDeliver(next core.MessageHandler) core.MessageHandler {
+	return func(msg core.SignedMessage) (core.Reply, error) {
+		start := time.Now()
+		rep, err := next(msg)
+		msgType := msg.Message().Type().String()
+		metrics.MessageBusDeliverTime.WithLabelValues(msgType).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.MessageBusDeliverErrorsTotal.WithLabelValues(msgType).Inc()
+		}
+		return rep, err
+	}
+}
+
+// LoggingInterceptor logs sent and delivered messages with their type, target, duration and error.
+type LoggingInterceptor struct{}
+
+// InterceptSend implementation of Interceptor interface.
+func (LoggingInterceptor) InterceptSend(next SendFunc) SendFunc {
+	return func(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error) {
+		start := time.Now()
+		rep, err := next(ctx, msg, opts)
+		if err != nil {
+			log.Warnf(
+				"messagebus: send failed type=%s target=%s duration=%s error=%q",
+				msg.Type(), targetString(msg), time.Since(start), err,
+			)
+		} else {
+			log.Debugf(
+				"messagebus: sent type=%s target=%s duration=%s", msg.Type(), targetString(msg), time.Since(start),
+			)
+		}
+		return rep, err
+	}
+}
+
+// InterceptDeliver implementation of Interceptor interface.
+func (LoggingInterceptor) InterceptDeliver(next core.MessageHandler) core.MessageHandler {
+	return func(msg core.SignedMessage) (core.Reply, error) {
+		start := time.Now()
+		rep, err := next(msg)
+		if err != nil {
+			log.Warnf(
+				"messagebus: handling failed type=%s target=%s sender=%s duration=%s error=%q",
+				msg.Message().Type(), targetString(msg), msg.GetSender(), time.Since(start), err,
+			)
+		} else {
+			log.Debugf(
+				"messagebus: handled type=%s target=%s sender=%s duration=%s",
+				msg.Message().Type(), targetString(msg), msg.GetSender(), time.Since(start),
+			)
+		}
+		return rep, err
+	}
+}
+
+func targetString(msg core.Message) string {
+	target := msg.Target()
+	if target == nil {
+		return "all"
+	}
+	return target.String()
+}
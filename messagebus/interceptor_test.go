@@ -0,0 +1,89 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testInterceptor struct {
+	name  string
+	calls *[]string
+	fail  bool
+}
+
+func (i *testInterceptor) InterceptSend(next SendFunc) SendFunc {
+	return func(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error) {
+		*i.calls = append(*i.calls, "send "+i.name)
+		return next(ctx, msg, opts)
+	}
+}
+
+func (i *testInterceptor) InterceptDeliver(next core.MessageHandler) core.MessageHandler {
+	return func(msg core.SignedMessage) (core.Reply, error) {
+		*i.calls = append(*i.calls, "deliver "+i.name)
+		if i.fail {
+			return nil, errors.New("injected fault")
+		}
+		return next(msg)
+	}
+}
+
+func TestMessageBus_Interceptors(t *testing.T) {
+	sender := testutils.RandomRef()
+	receiver := testutils.RandomRef()
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleVirtualExecutor: {sender},
+		core.RoleLightExecutor:   {receiver},
+	})
+	var calls []string
+	buses[receiver].MustRegister(core.TypeGetCode, func(core.SignedMessage) (core.Reply, error) {
+		calls = append(calls, "handler")
+		return &reply.OK{}, nil
+	})
+	buses[sender].AddInterceptor(&testInterceptor{name: "first", calls: &calls})
+	buses[sender].AddInterceptor(&testInterceptor{name: "second", calls: &calls})
+	failing := &testInterceptor{name: "receiver", calls: &calls}
+	buses[receiver].AddInterceptor(failing)
+
+	t.Run("interceptors are called in order", func(t *testing.T) {
+		calls = nil
+		rep, err := buses[sender].Send(&message.GetCode{})
+		require.NoError(t, err)
+		assert.Equal(t, &reply.OK{}, rep)
+		assert.Equal(t, []string{"send first", "send second", "deliver receiver", "handler"}, calls)
+	})
+
+	t.Run("interceptor can stop handling", func(t *testing.T) {
+		calls = nil
+		failing.fail = true
+		defer func() { failing.fail = false }()
+
+		_, err := buses[sender].Send(&message.GetCode{})
+		assert.Error(t, err)
+		assert.Equal(t, []string{"send first", "send second", "deliver receiver"}, calls)
+	})
+}
@@ -18,14 +18,19 @@ package messagebus
 
 import (
 	"bytes"
-	"encoding/gob"
-	"errors"
+	"context"
+	"crypto/ecdsa"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
+	ecdsa2 "github.com/insolar/insolar/cryptohelpers/ecdsa"
 	"github.com/insolar/insolar/log"
+	"github.com/pkg/errors"
 )
 
 const deliverRPCMethodName = "MessageBus.Deliver"
@@ -36,11 +41,38 @@ type MessageBus struct {
 	service  core.Network
 	ledger   core.Ledger
 	handlers map[core.MessageType]core.MessageHandler
+	conf     configuration.MessageBus
+	key      *ecdsa.PrivateKey
+
+	interceptors []Interceptor
+	recordFile   *os.File
+
+	deliveredMutex sync.Mutex
+	delivered      map[core.PulseNumber]map[deliveryKey]bool // messages delivered on recent pulses
+	pulse          core.PulseNumber                          // current pulse on the last delivery
+	prevPulse      core.PulseNumber
+}
+
+// deliveryKey identifies a delivered message of the sender
+type deliveryKey struct {
+	sender core.RecordRef
+	nonce  uint64
 }
 
 // NewMessageBus is a `MessageBus` constructor
-func NewMessageBus(configuration.Configuration) (*MessageBus, error) {
-	return &MessageBus{handlers: map[core.MessageType]core.MessageHandler{}}, nil
+func NewMessageBus(config configuration.Configuration) (*MessageBus, error) {
+	key, err := ecdsa2.ImportPrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to import private key")
+	}
+	return &MessageBus{
+		handlers: map[core.MessageType]core.MessageHandler{},
+		conf:     config.MessageBus,
+		key:      key,
+
+		interceptors: []Interceptor{LoggingInterceptor{}, MetricsInterceptor{}},
+		delivered:    map[core.PulseNumber]map[deliveryKey]bool{},
+	}, nil
 }
 
 // Start initializes message bus
@@ -49,11 +81,25 @@ func (mb *MessageBus) Start(c core.Components) error {
 	mb.service.RemoteProcedureRegister(deliverRPCMethodName, mb.deliver)
 	mb.ledger = c.Ledger
 
+	if mb.conf.RecordFile != "" {
+		f, err := os.OpenFile(mb.conf.RecordFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to open record file")
+		}
+		mb.recordFile = f
+		mb.AddInterceptor(NewRecorder(f, mb.ledger.GetPulseManager()))
+	}
+
 	return nil
 }
 
 // Stop releases resources and stops the bus
-func (mb *MessageBus) Stop() error { return nil }
+func (mb *MessageBus) Stop() error {
+	if mb.recordFile != nil {
+		return mb.recordFile.Close()
+	}
+	return nil
+}
 
 // Register sets a function as a hadler for particular message type,
 // only one handler per type is allowed
@@ -75,16 +121,143 @@ func (mb *MessageBus) MustRegister(p core.MessageType, handler core.MessageHandl
 	}
 }
 
-// Send an `Message` and get a `Reply` or error from remote host.
+// AddInterceptor appends interceptor to the chain wrapping sent and delivered messages. Interceptors added earlier
+// are called first. Logging and metrics interceptors are added by default.
+func (mb *MessageBus) AddInterceptor(interceptor Interceptor) {
+	mb.interceptors = append(mb.interceptors, interceptor)
+}
+
+func (mb *MessageBus) interceptSend(send SendFunc) SendFunc {
+	for i := len(mb.interceptors) - 1; i >= 0; i-- {
+		send = mb.interceptors[i].InterceptSend(send)
+	}
+	return send
+}
+
+func (mb *MessageBus) interceptDeliver(handler core.MessageHandler) core.MessageHandler {
+	for i := len(mb.interceptors) - 1; i >= 0; i-- {
+		handler = mb.interceptors[i].InterceptDeliver(handler)
+	}
+	return handler
+}
+
+// Send an `Message` and get a `Reply` or error from remote host. Message is signed with the node key.
+//
+// If message target is nil, message is delivered to all actors of its role and `reply.Aggregated` is returned.
+// If there are several actors for the target, message is delivered through the cascade, `reply.Aggregated` is returned
+// and error is returned if configured quorum of replies is not reached. Delivery is limited by configured timeout and
+// retries, see SendWithOptions.
 func (mb *MessageBus) Send(msg core.Message) (core.Reply, error) {
+	return mb.SendWithOptions(context.Background(), msg, nil)
+}
+
+// SendWithOptions sends an `Message` like Send does, but every delivery attempt is limited by options timeout and
+// failed delivery of idempotent message is retried with exponential backoff. Delivery is canceled with the context,
+// though remote node can still process the message. Options from configuration are used if `opts` is nil.
+func (mb *MessageBus) SendWithOptions(
+	ctx context.Context, msg core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	return mb.interceptSend(mb.sendWithRetries)(ctx, msg, opts)
+}
+
+func (mb *MessageBus) sendWithRetries(
+	ctx context.Context, msg core.Message, opts *core.MessageSendOptions,
+) (core.Reply, error) {
+	if opts == nil {
+		opts = mb.defaultOptions(msg)
+	}
+	retries := opts.Retries
+	if !message.IsIdempotent(msg) {
+		retries = 0
+	}
+
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		rep, err := mb.sendWithTimeout(ctx, msg, opts.Timeout)
+		if _, handled := errors.Cause(err).(*reply.HandlerError); handled {
+			return nil, err
+		}
+		if err == nil || attempt >= retries || ctx.Err() != nil {
+			return rep, err
+		}
+
+		log.Debugf("failed to send %s message, retrying in %s: %s", msg.Type(), delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "message sending is canceled")
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// defaultOptions returns send options from configuration, contract calls have their own timeout.
+func (mb *MessageBus) defaultOptions(msg core.Message) *core.MessageSendOptions {
+	timeout := mb.conf.SendTimeout
+	switch msg.(type) {
+	case *message.CallMethod, *message.CallConstructor:
+		timeout = mb.conf.CallTimeout
+	}
+	return &core.MessageSendOptions{
+		Timeout:    time.Duration(timeout) * time.Millisecond,
+		Retries:    mb.conf.SendRetries,
+		RetryDelay: time.Duration(mb.conf.SendRetryDelay) * time.Millisecond,
+	}
+}
+
+// sendWithTimeout makes a single delivery attempt. Zero timeout means no limit.
+func (mb *MessageBus) sendWithTimeout(
+	ctx context.Context, msg core.Message, timeout time.Duration,
+) (core.Reply, error) {
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "message sending is canceled")
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		rep core.Reply
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rep, err := mb.send(msg)
+		done <- result{rep: rep, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.rep, res.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "message sending is canceled")
+	}
+}
+
+// send signs message on the current pulse and routes it to the actors of its target role. Every attempt is signed
+// with a new nonce, so retries aren't rejected as duplicates.
+func (mb *MessageBus) send(msg core.Message) (core.Reply, error) {
 	jc := mb.ledger.GetJetCoordinator()
 	pm := mb.ledger.GetPulseManager()
 	pulse, err := pm.Current()
 	if err != nil {
 		return nil, err
 	}
+	signedMsg, err := message.NewSignedMessage(msg, mb.service.GetNodeID(), pulse.PulseNumber, mb.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	if msg.Target() == nil {
+		nodes, err := jc.QueryRoleNodes(msg.TargetRole(), pulse.PulseNumber)
+		if err != nil {
+			return nil, err
+		}
+		return mb.broadcast(nodes, signedMsg)
+	}
 
-	// TODO: send to all actors of the role if nil Target
 	nodes, err := jc.QueryRole(msg.TargetRole(), *msg.Target(), pulse.PulseNumber)
 	if err != nil {
 		return nil, err
@@ -94,34 +267,163 @@ func (mb *MessageBus) Send(msg core.Message) (core.Reply, error) {
 		cascade := core.Cascade{
 			NodeIds:           nodes,
 			Entropy:           pulse.Entropy,
-			ReplicationFactor: 2,
+			ReplicationFactor: mb.conf.CascadeReplicationFactor,
 		}
-		err := mb.service.SendCascadeMessage(cascade, deliverRPCMethodName, msg)
-		return nil, err
+		results, err := mb.service.SendCascadeMessage(cascade, deliverRPCMethodName, signedMsg)
+		if err != nil {
+			return nil, err
+		}
+		return mb.aggregateCascade(nodes, results)
+	}
+
+	return mb.sendToNode(nodes[0], signedMsg)
+}
+
+// broadcast delivers message to every provided node and collects their replies. Error is returned only if none of
+// the nodes replied, partial delivery should be checked on returned `reply.Aggregated`.
+func (mb *MessageBus) broadcast(nodes []core.RecordRef, msg core.Message) (core.Reply, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes to broadcast message to")
 	}
 
-	res, err := mb.service.SendMessage(nodes[0], deliverRPCMethodName, msg)
+	aggregated := &reply.Aggregated{
+		Replies: map[core.RecordRef]core.Reply{},
+		Errors:  map[core.RecordRef]string{},
+	}
+	for _, node := range nodes {
+		rep, err := mb.sendToNode(node, msg)
+		if err != nil {
+			aggregated.Errors[node] = err.Error()
+			continue
+		}
+		aggregated.Replies[node] = rep
+	}
+
+	if len(aggregated.Replies) == 0 {
+		return aggregated, aggregated.Err()
+	}
+	return aggregated, nil
+}
+
+// aggregateCascade collects cascade results into `reply.Aggregated`. Nodes missing in results are considered failed.
+// Error is returned if count of successful replies is less than configured quorum.
+func (mb *MessageBus) aggregateCascade(
+	nodes []core.RecordRef, results map[core.RecordRef]core.CascadeResult,
+) (core.Reply, error) {
+	aggregated := &reply.Aggregated{
+		Replies: map[core.RecordRef]core.Reply{},
+		Errors:  map[core.RecordRef]string{},
+	}
+	for _, node := range nodes {
+		res, ok := results[node]
+		if !ok {
+			aggregated.Errors[node] = "no reply from node"
+			continue
+		}
+		if res.Error != "" {
+			aggregated.Errors[node] = res.Error
+			continue
+		}
+		rep, err := reply.Deserialize(bytes.NewBuffer(res.Result))
+		if err != nil {
+			aggregated.Errors[node] = err.Error()
+			continue
+		}
+		if handlerErr, ok := rep.(*reply.HandlerError); ok {
+			aggregated.Errors[node] = handlerErr.Text
+			continue
+		}
+		aggregated.Replies[node] = rep
+	}
+
+	quorum := (len(nodes)*mb.conf.CascadeQuorum + 99) / 100
+	if len(aggregated.Replies) < quorum {
+		return aggregated, errors.Errorf(
+			"cascade quorum is not reached: %d of %d nodes replied, %d required: %s",
+			len(aggregated.Replies), len(nodes), quorum, aggregated.Err(),
+		)
+	}
+	return aggregated, nil
+}
+
+func (mb *MessageBus) sendToNode(node core.RecordRef, msg core.Message) (core.Reply, error) {
+	res, err := mb.service.SendMessage(node, deliverRPCMethodName, msg)
 	if err != nil {
 		return nil, err
 	}
 
-	return reply.Deserialize(bytes.NewBuffer(res))
+	rep, err := reply.Deserialize(bytes.NewBuffer(res))
+	if err != nil {
+		return nil, err
+	}
+	if handlerErr, ok := rep.(*reply.HandlerError); ok {
+		return nil, handlerErr
+	}
+	return rep, nil
 }
 
 // SendAsync sends a `Message` to remote host.
 func (mb *MessageBus) SendAsync(msg core.Message) {
 	go func() {
 		_, err := mb.Send(msg)
-		log.Errorln(err)
+		if err != nil {
+			log.Errorln(err)
+		}
 	}()
 }
 
-type serializableError struct {
-	S string
+// checkSign verifies message sign with the public key of the sender active node.
+func (mb *MessageBus) checkSign(msg *message.SignedMessage) error {
+	node := mb.service.GetActiveNode(msg.Sender)
+	if node == nil {
+		return errors.Errorf("sender %s is not an active node", msg.Sender)
+	}
+	if !msg.IsValid(string(node.PublicKey)) {
+		return errors.Errorf("invalid sign of message from %s", msg.Sender)
+	}
+	return nil
 }
 
-func (e *serializableError) Error() string {
-	return e.S
+// checkFresh rejects messages signed on pulses before the previous one and messages which are already delivered, so
+// captured messages can't be delivered again. Messages of the next pulses are accepted, as their senders can change
+// pulse earlier.
+func (mb *MessageBus) checkFresh(msg *message.SignedMessage) error {
+	pulse, err := mb.ledger.GetPulseManager().Current()
+	if err != nil {
+		return err
+	}
+
+	mb.deliveredMutex.Lock()
+	defer mb.deliveredMutex.Unlock()
+	if pulse.PulseNumber != mb.pulse {
+		mb.prevPulse, mb.pulse = mb.pulse, pulse.PulseNumber
+		if mb.prevPulse == 0 {
+			// previous pulse is unknown before the first delivery
+			mb.prevPulse = mb.pulse
+		}
+		for p := range mb.delivered {
+			if p < mb.prevPulse {
+				delete(mb.delivered, p)
+			}
+		}
+	}
+	if msg.Pulse < mb.prevPulse {
+		return errors.Errorf(
+			"message from %s is signed on pulse %d, current pulse is %d", msg.Sender, msg.Pulse, pulse.PulseNumber,
+		)
+	}
+
+	key := deliveryKey{sender: msg.Sender, nonce: msg.Nonce}
+	delivered, ok := mb.delivered[msg.Pulse]
+	if !ok {
+		delivered = map[deliveryKey]bool{}
+		mb.delivered[msg.Pulse] = delivered
+	}
+	if delivered[key] {
+		return errors.Errorf("message from %s is already delivered", msg.Sender)
+	}
+	delivered[key] = true
+	return nil
 }
 
 // Deliver method calls LogicRunner.Execute on local host
@@ -130,21 +432,32 @@ func (mb *MessageBus) deliver(args [][]byte) (result []byte, err error) {
 	if len(args) < 1 {
 		return nil, errors.New("need exactly one argument when mb.deliver()")
 	}
-	msg, err := message.Deserialize(bytes.NewBuffer(args[0]))
+	parsed, err := message.Deserialize(bytes.NewBuffer(args[0]))
 	if err != nil {
 		return nil, err
 	}
+	signedMsg, ok := parsed.(*message.SignedMessage)
+	if !ok {
+		return nil, errors.New("message is not signed")
+	}
+	err = mb.checkSign(signedMsg)
+	if err == nil {
+		err = mb.checkFresh(signedMsg)
+	}
+	if err != nil {
+		log.Warnf("message is dropped: %s", err)
+		return nil, err
+	}
 
-	handler, ok := mb.handlers[msg.Type()]
+	handler, ok := mb.handlers[signedMsg.Message().Type()]
 	if !ok {
 		return nil, errors.New("no handler for received message type")
 	}
 
-	resp, err := handler(msg)
+	resp, err := mb.interceptDeliver(handler)(signedMsg)
 	if err != nil {
-		return nil, &serializableError{
-			S: err.Error(),
-		}
+		// the message is delivered, so handler error is replied to tell it from delivery failure
+		resp = &reply.HandlerError{Text: err.Error()}
 	}
 	rd, err := reply.Serialize(resp)
 	if err != nil {
@@ -157,7 +470,3 @@ func (mb *MessageBus) deliver(args [][]byte) (result []byte, err error) {
 	}
 	return buf.Bytes(), nil
 }
-
-func init() {
-	gob.Register(&serializableError{})
-}
@@ -17,10 +17,22 @@
 package messagebus
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	ecdsa2 "github.com/insolar/insolar/cryptohelpers/ecdsa"
+	"github.com/insolar/insolar/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type req struct {
@@ -76,6 +88,459 @@ func TestNew(t *testing.T) {
 	//}
 }
 
+type testNetwork struct {
+	nodeID core.RecordRef
+	// nodes maps node reference to its message bus, nil value means dead node.
+	nodes  map[core.RecordRef]*MessageBus
+	active map[core.RecordRef]*core.ActiveNode
+	sent   []core.RecordRef
+	// failures is a count of next direct sends which fail.
+	failures int
+	// delay is a time of every direct send.
+	delay time.Duration
+}
+
+func (n *testNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Message) ([]byte, error) {
+	n.sent = append(n.sent, nodeID)
+	time.Sleep(n.delay)
+	if n.failures > 0 {
+		n.failures--
+		return nil, errors.New("network failure")
+	}
+	mb, ok := n.nodes[nodeID]
+	if !ok || mb == nil {
+		return nil, errors.New("node is unreachable")
+	}
+	return mb.deliver([][]byte{message.MustSerializeBytes(msg)})
+}
+
+func (n *testNetwork) SendCascadeMessage(
+	data core.Cascade, method string, msg core.Message,
+) (map[core.RecordRef]core.CascadeResult, error) {
+	results := map[core.RecordRef]core.CascadeResult{}
+	for _, node := range data.NodeIds {
+		n.sent = append(n.sent, node)
+		mb, ok := n.nodes[node]
+		if !ok {
+			continue
+		}
+		if mb == nil {
+			results[node] = core.CascadeResult{Error: "node is unreachable"}
+			continue
+		}
+		res, err := mb.deliver([][]byte{message.MustSerializeBytes(msg)})
+		if err != nil {
+			results[node] = core.CascadeResult{Error: err.Error()}
+			continue
+		}
+		results[node] = core.CascadeResult{Result: res}
+	}
+	return results, nil
+}
+
+func (n *testNetwork) GetAddress() string                                               { return "" }
+func (n *testNetwork) RemoteProcedureRegister(name string, method core.RemoteProcedure) {}
+func (n *testNetwork) GetNodeID() core.RecordRef                                        { return n.nodeID }
+func (n *testNetwork) GetActiveNode(ref core.RecordRef) *core.ActiveNode                { return n.active[ref] }
+
+type testJetCoordinator struct {
+	nodes map[core.JetRole][]core.RecordRef
+}
+
+func (jc *testJetCoordinator) IsAuthorized(
+	role core.JetRole, obj core.RecordRef, pulse core.PulseNumber, node core.RecordRef,
+) (bool, error) {
+	for _, n := range jc.nodes[role] {
+		if n == node {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (jc *testJetCoordinator) QueryRole(
+	role core.JetRole, obj core.RecordRef, pulse core.PulseNumber,
+) ([]core.RecordRef, error) {
+	return jc.nodes[role], nil
+}
+
+func (jc *testJetCoordinator) QueryRoleNodes(role core.JetRole, pulse core.PulseNumber) ([]core.RecordRef, error) {
+	return jc.nodes[role], nil
+}
+
+type testPulseManager struct {
+	pulse core.Pulse
+}
+
+func (pm *testPulseManager) Current() (*core.Pulse, error) { return &pm.pulse, nil }
+func (pm *testPulseManager) Set(pulse core.Pulse) error    { pm.pulse = pulse; return nil }
+
+type testLedger struct {
+	jc *testJetCoordinator
+	pm *testPulseManager
+}
+
+func (l *testLedger) GetArtifactManager() core.ArtifactManager { return nil }
+func (l *testLedger) GetJetCoordinator() core.JetCoordinator   { return l.jc }
+func (l *testLedger) GetPulseManager() core.PulseManager       { return l.pm }
+
+// newTestBuses creates message buses for provided nodes connected with each other through test network.
+func newTestBuses(t *testing.T, roles map[core.JetRole][]core.RecordRef) map[core.RecordRef]*MessageBus {
+	ld := &testLedger{
+		jc: &testJetCoordinator{nodes: roles},
+		pm: &testPulseManager{pulse: core.Pulse{PulseNumber: core.FirstPulseNumber}},
+	}
+	buses := map[core.RecordRef]*MessageBus{}
+	active := map[core.RecordRef]*core.ActiveNode{}
+	for _, nodes := range roles {
+		for _, node := range nodes {
+			key, err := ecdsa2.GeneratePrivateKey()
+			require.NoError(t, err)
+			pub, err := ecdsa2.ExportPublicKey(&key.PublicKey)
+			require.NoError(t, err)
+			active[node] = &core.ActiveNode{NodeID: node, PublicKey: []byte(pub)}
+
+			cfg := configuration.NewConfiguration()
+			cfg.PrivateKey, err = ecdsa2.ExportPrivateKey(key)
+			require.NoError(t, err)
+			mb, err := NewMessageBus(cfg)
+			require.NoError(t, err)
+			err = mb.Start(core.Components{
+				Network: &testNetwork{nodeID: node, nodes: buses, active: active},
+				Ledger:  ld,
+			})
+			require.NoError(t, err)
+			buses[node] = mb
+		}
+	}
+	return buses
+}
+
+func TestMessageBus_Send_Broadcast(t *testing.T) {
+	sender := testutils.RandomRef()
+	validators := []core.RecordRef{
+		testutils.RandomRef(),
+		testutils.RandomRef(),
+		testutils.RandomRef(),
+	}
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleLightExecutor:  {sender},
+		core.RoleLightValidator: validators,
+	})
+	delivered := map[core.RecordRef]int{}
+	for node, mb := range buses {
+		node := node
+		mb.MustRegister(core.TypeJetDrop, func(core.SignedMessage) (core.Reply, error) {
+			delivered[node]++
+			return &reply.OK{}, nil
+		})
+	}
+
+	t.Run("all nodes reply", func(t *testing.T) {
+		rep, err := buses[sender].Send(&message.JetDrop{})
+		require.NoError(t, err)
+		aggregated, ok := rep.(*reply.Aggregated)
+		require.True(t, ok)
+		assert.Equal(t, 3, aggregated.Nodes())
+		assert.False(t, aggregated.Partial())
+		assert.NoError(t, aggregated.Err())
+		for _, v := range validators {
+			assert.Equal(t, &reply.OK{}, aggregated.Replies[v])
+			assert.Equal(t, 1, delivered[v])
+		}
+		assert.Equal(t, 0, delivered[sender])
+	})
+
+	t.Run("partial delivery", func(t *testing.T) {
+		buses[validators[1]] = nil
+		defer func() { delete(buses, validators[1]) }()
+
+		rep, err := buses[sender].Send(&message.JetDrop{})
+		require.NoError(t, err)
+		aggregated := rep.(*reply.Aggregated)
+		assert.True(t, aggregated.Partial())
+		assert.Error(t, aggregated.Err())
+		assert.Len(t, aggregated.Replies, 2)
+		assert.Contains(t, aggregated.Errors, validators[1])
+	})
+
+	t.Run("no nodes reply", func(t *testing.T) {
+		roles := buses[sender].ledger.GetJetCoordinator().(*testJetCoordinator).nodes
+		roles[core.RoleLightValidator] = []core.RecordRef{testutils.RandomRef()}
+
+		_, err := buses[sender].Send(&message.JetDrop{})
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageBus_Send_Cascade(t *testing.T) {
+	executor := testutils.RandomRef()
+	validators := []core.RecordRef{
+		testutils.RandomRef(),
+		testutils.RandomRef(),
+		testutils.RandomRef(),
+	}
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleVirtualExecutor:  {executor},
+		core.RoleVirtualValidator: validators,
+	})
+	for _, mb := range buses {
+		mb.MustRegister(core.TypeValidateCaseBind, func(core.SignedMessage) (core.Reply, error) {
+			return &reply.OK{}, nil
+		})
+	}
+	msg := &message.ValidateCaseBind{RecordRef: testutils.RandomRef()}
+
+	t.Run("all nodes reply", func(t *testing.T) {
+		rep, err := buses[executor].Send(msg)
+		require.NoError(t, err)
+		aggregated := rep.(*reply.Aggregated)
+		assert.Len(t, aggregated.Replies, 3)
+		assert.NoError(t, aggregated.Err())
+	})
+
+	t.Run("quorum is reached", func(t *testing.T) {
+		buses[validators[0]] = nil
+		defer func() { delete(buses, validators[0]) }()
+
+		rep, err := buses[executor].Send(msg)
+		require.NoError(t, err)
+		aggregated := rep.(*reply.Aggregated)
+		assert.True(t, aggregated.Partial())
+		assert.Len(t, aggregated.Replies, 2)
+		assert.Equal(t, "node is unreachable", aggregated.Errors[validators[0]])
+	})
+
+	t.Run("quorum is not reached", func(t *testing.T) {
+		buses[validators[0]] = nil
+		delete(buses, validators[1])
+		defer func() { delete(buses, validators[0]) }()
+
+		rep, err := buses[executor].Send(msg)
+		assert.Error(t, err)
+		aggregated := rep.(*reply.Aggregated)
+		assert.Len(t, aggregated.Replies, 1)
+		assert.Equal(t, "no reply from node", aggregated.Errors[validators[1]])
+	})
+}
+
+func TestMessageBus_Deliver_Sign(t *testing.T) {
+	sender := testutils.RandomRef()
+	receiver := testutils.RandomRef()
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleVirtualExecutor: {sender},
+		core.RoleLightExecutor:   {receiver},
+	})
+	var delivered []core.SignedMessage
+	buses[receiver].MustRegister(core.TypeGetCode, func(msg core.SignedMessage) (core.Reply, error) {
+		delivered = append(delivered, msg)
+		return &reply.OK{}, nil
+	})
+	deliver := func(msg core.Message) error {
+		_, err := buses[receiver].deliver([][]byte{message.MustSerializeBytes(msg)})
+		return err
+	}
+
+	t.Run("signed message is delivered", func(t *testing.T) {
+		msg := &message.GetCode{Code: testutils.RandomRef()}
+		_, err := buses[sender].Send(msg)
+		require.NoError(t, err)
+		require.Len(t, delivered, 1)
+		assert.Equal(t, sender, delivered[0].GetSender())
+		assert.Equal(t, msg.Code, delivered[0].Message().(*message.GetCode).Code)
+		assert.NotEmpty(t, delivered[0].Message().GetSign())
+	})
+
+	t.Run("unsigned message is dropped", func(t *testing.T) {
+		err := deliver(&message.GetCode{Code: testutils.RandomRef()})
+		assert.Error(t, err)
+	})
+
+	t.Run("message from unknown node is dropped", func(t *testing.T) {
+		key, err := ecdsa2.GeneratePrivateKey()
+		require.NoError(t, err)
+		msg, err := message.NewSignedMessage(&message.GetCode{}, testutils.RandomRef(), core.FirstPulseNumber, key)
+		require.NoError(t, err)
+		assert.Error(t, deliver(msg))
+	})
+
+	t.Run("message with wrong sign is dropped", func(t *testing.T) {
+		key, err := ecdsa2.GeneratePrivateKey()
+		require.NoError(t, err)
+		msg, err := message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, key)
+		require.NoError(t, err)
+		assert.Error(t, deliver(msg))
+	})
+
+	t.Run("message which doesn't serialize back to the same bytes is delivered", func(t *testing.T) {
+		buses[receiver].MustRegister(core.TypeExecutorResults, func(msg core.SignedMessage) (core.Reply, error) {
+			return &reply.OK{}, nil
+		})
+		msg, err := message.NewSignedMessage(&message.ExecutorResults{
+			CaseRecords: []core.CaseRecord{{Resp: &message.CallMethod{Method: "Get", Arguments: []byte{1}}}},
+		}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		assert.NoError(t, deliver(msg))
+	})
+
+	t.Run("modified message is dropped", func(t *testing.T) {
+		msg, err := message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		msg.Msg.(*message.GetCode).Code = testutils.RandomRef()
+		assert.Error(t, deliver(msg))
+	})
+
+	t.Run("message with modified pulse is dropped", func(t *testing.T) {
+		msg, err := message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		msg.Pulse++
+		assert.Error(t, deliver(msg))
+	})
+
+	t.Run("message is delivered once", func(t *testing.T) {
+		msg, err := message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		require.NoError(t, deliver(msg))
+		assert.EqualError(t, deliver(msg), fmt.Sprintf("message from %s is already delivered", sender))
+	})
+
+	t.Run("message of old pulse is dropped", func(t *testing.T) {
+		msg, err := message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		pm := buses[receiver].ledger.GetPulseManager()
+		defer pm.Set(core.Pulse{PulseNumber: core.FirstPulseNumber})
+		pm.Set(core.Pulse{PulseNumber: core.FirstPulseNumber + 1})
+		require.NoError(t, deliver(msg), "message of previous pulse is delivered")
+		pm.Set(core.Pulse{PulseNumber: core.FirstPulseNumber + 2})
+		msg, err = message.NewSignedMessage(&message.GetCode{}, sender, core.FirstPulseNumber, buses[sender].key)
+		require.NoError(t, err)
+		assert.Error(t, deliver(msg))
+	})
+
+	assert.Len(t, delivered, 3)
+}
+
+func TestMessageBus_SendWithOptions(t *testing.T) {
+	sender := testutils.RandomRef()
+	receiver := testutils.RandomRef()
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleVirtualExecutor: {sender},
+		core.RoleLightExecutor:   {receiver},
+	})
+	handler := func(core.SignedMessage) (core.Reply, error) {
+		return &reply.OK{}, nil
+	}
+	buses[receiver].MustRegister(core.TypeGetCode, handler)
+	buses[receiver].MustRegister(core.TypeDeclareType, handler)
+	buses[receiver].MustRegister(core.TypeGetObject, func(core.SignedMessage) (core.Reply, error) {
+		return nil, errors.New("object not found")
+	})
+	network := buses[sender].service.(*testNetwork)
+	reset := func() {
+		network.sent = nil
+		network.failures = 0
+		network.delay = 0
+	}
+	opts := &core.MessageSendOptions{Retries: 2, RetryDelay: time.Millisecond}
+
+	t.Run("idempotent message is retried", func(t *testing.T) {
+		defer reset()
+		network.failures = 2
+
+		rep, err := buses[sender].SendWithOptions(context.Background(), &message.GetCode{}, opts)
+		require.NoError(t, err)
+		assert.Equal(t, &reply.OK{}, rep)
+		assert.Len(t, network.sent, 3)
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		defer reset()
+		network.failures = 3
+
+		_, err := buses[sender].SendWithOptions(context.Background(), &message.GetCode{}, opts)
+		assert.Error(t, err)
+		assert.Len(t, network.sent, 3)
+	})
+
+	t.Run("handler error is not retried", func(t *testing.T) {
+		defer reset()
+
+		_, err := buses[sender].SendWithOptions(context.Background(), &message.GetObject{}, opts)
+		assert.EqualError(t, err, "object not found")
+		assert.Len(t, network.sent, 1)
+	})
+
+	t.Run("mutating message is not retried", func(t *testing.T) {
+		defer reset()
+		network.failures = 1
+
+		_, err := buses[sender].SendWithOptions(context.Background(), &message.DeclareType{}, opts)
+		assert.Error(t, err)
+		assert.Len(t, network.sent, 1)
+	})
+
+	t.Run("attempt is timed out", func(t *testing.T) {
+		defer reset()
+		network.delay = 100 * time.Millisecond
+
+		start := time.Now()
+		_, err := buses[sender].SendWithOptions(
+			context.Background(), &message.DeclareType{}, &core.MessageSendOptions{Timeout: 10 * time.Millisecond},
+		)
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < network.delay)
+		time.Sleep(network.delay)
+	})
+
+	t.Run("canceled context stops sending", func(t *testing.T) {
+		defer reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := buses[sender].SendWithOptions(ctx, &message.GetCode{}, opts)
+		assert.Error(t, err)
+	})
+
+	t.Run("configured options are used by default", func(t *testing.T) {
+		defer reset()
+		bus := buses[sender]
+		defer func(conf configuration.MessageBus) { bus.conf = conf }(bus.conf)
+		bus.conf.SendTimeout = 10
+		bus.conf.SendRetries = 1
+		bus.conf.SendRetryDelay = 1
+
+		network.failures = 1
+		_, err := bus.Send(&message.GetCode{})
+		require.NoError(t, err)
+		assert.Len(t, network.sent, 2)
+
+		network.delay = 100 * time.Millisecond
+		start := time.Now()
+		_, err = bus.Send(&message.DeclareType{})
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < network.delay)
+		time.Sleep(network.delay)
+	})
+}
+
+func TestAggregated_Serialize(t *testing.T) {
+	node := testutils.RandomRef()
+	failed := testutils.RandomRef()
+	rep := &reply.Aggregated{
+		Replies: map[core.RecordRef]core.Reply{node: &reply.ID{ID: core.RecordID{1, 2, 3}}},
+		Errors:  map[core.RecordRef]string{failed: "node is unreachable"},
+	}
+
+	r, err := reply.Serialize(rep)
+	require.NoError(t, err)
+	buf, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	decoded, err := reply.Deserialize(bytes.NewBuffer(buf))
+	require.NoError(t, err)
+	assert.Equal(t, rep, decoded)
+}
+
 // TODO: fix network interaction
 // func TestRoute(t *testing.T) {
 // 	r := new(runner)
@@ -0,0 +1,234 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebustest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	ecdsa2 "github.com/insolar/insolar/cryptohelpers/ecdsa"
+	"github.com/insolar/insolar/ledger"
+	"github.com/insolar/insolar/ledger/artifactmanager"
+	"github.com/insolar/insolar/ledger/jetcoordinator"
+	"github.com/insolar/insolar/ledger/pulsemanager"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/messagebus"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// pulseDelta is a difference between numbers of consequent pulses generated by harness.
+const pulseDelta = 10
+
+// Role describes virtual nodes of jet role: how many nodes are candidates and how many of them act on every pulse.
+type Role struct {
+	Candidates int
+	Actors     int
+
+	// LogicRunner creates logic runner for every node of the role. Logic runners implementing core.Component are
+	// started with node's components and stopped with harness. Nodes get a stub which executes nothing if it's nil.
+	LogicRunner func(ref core.RecordRef) core.LogicRunner
+}
+
+// Node is a virtual node hosted by Harness.
+type Node struct {
+	Ref         core.RecordRef
+	Network     *Endpoint
+	Bus         *messagebus.MessageBus
+	Ledger      *ledger.Ledger
+	LogicRunner core.LogicRunner
+}
+
+// Harness hosts virtual nodes with real message buses and ledgers connected through simulated network.
+type Harness struct {
+	Network *Network
+	Nodes   map[core.RecordRef]*Node
+	Roles   map[core.JetRole][]core.RecordRef
+
+	rand       *rand.Rand
+	components []core.Component
+	cleaners   []func()
+}
+
+// NewHarness creates and starts virtual nodes for provided roles. Node references and pulse entropy are derived from
+// seed, so two harnesses with the same seed behave the same way.
+func NewHarness(t testing.TB, seed int64, roles map[core.JetRole]Role) *Harness {
+	h := &Harness{
+		Network: NewNetwork(seed),
+		Nodes:   map[core.RecordRef]*Node{},
+		Roles:   map[core.JetRole][]core.RecordRef{},
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+
+	conf := configuration.NewLedger()
+	conf.JetCoordinator.RoleCandidates = map[int][]string{}
+	conf.JetCoordinator.RoleCounts = map[int]int{}
+	for role := core.RoleVirtualExecutor; role <= core.RoleHeavyExecutor; role++ {
+		r, ok := roles[role]
+		if !ok {
+			continue
+		}
+		for i := 0; i < r.Candidates; i++ {
+			var ref core.RecordRef
+			h.rand.Read(ref[:])
+			h.Roles[role] = append(h.Roles[role], ref)
+			conf.JetCoordinator.RoleCandidates[int(role)] = append(
+				conf.JetCoordinator.RoleCandidates[int(role)], ref.String(),
+			)
+		}
+		conf.JetCoordinator.RoleCounts[int(role)] = r.Actors
+	}
+
+	for role, refs := range h.Roles {
+		for _, ref := range refs {
+			var lr core.LogicRunner = &logicRunner{}
+			if roles[role].LogicRunner != nil {
+				lr = roles[role].LogicRunner(ref)
+			}
+			h.Nodes[ref] = h.startNode(t, ref, conf, lr)
+		}
+	}
+	return h
+}
+
+func (h *Harness) startNode(t testing.TB, ref core.RecordRef, conf configuration.Ledger, lr core.LogicRunner) *Node {
+	key, err := ecdsa2.GeneratePrivateKey()
+	require.NoError(t, err)
+	publicKey, err := ecdsa2.ExportPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	cfg := configuration.NewConfiguration()
+	cfg.PrivateKey, err = ecdsa2.ExportPrivateKey(key)
+	require.NoError(t, err)
+
+	db, cleaner := storagetest.TmpDB(t, "")
+	h.cleaners = append(h.cleaners, cleaner)
+	am, err := artifactmanager.NewArtifactManger(db)
+	require.NoError(t, err)
+	jc, err := jetcoordinator.NewJetCoordinator(db, conf.JetCoordinator)
+	require.NoError(t, err)
+	pm, err := pulsemanager.NewPulseManager(db)
+	require.NoError(t, err)
+	handler, err := artifactmanager.NewMessageHandler(db, jc, conf)
+	require.NoError(t, err)
+	bus, err := messagebus.NewMessageBus(cfg)
+	require.NoError(t, err)
+
+	node := &Node{
+		Ref:         ref,
+		Network:     h.Network.AddNode(ref, publicKey),
+		Bus:         bus,
+		Ledger:      ledger.NewTestLedger(db, am, pm, jc, handler),
+		LogicRunner: lr,
+	}
+	components := core.Components{
+		Network:     node.Network,
+		Ledger:      node.Ledger,
+		MessageBus:  node.Bus,
+		LogicRunner: node.LogicRunner,
+	}
+	require.NoError(t, node.Bus.Start(components))
+	require.NoError(t, node.Ledger.Start(components))
+	if c, ok := lr.(core.Component); ok {
+		require.NoError(t, c.Start(components))
+		h.components = append(h.components, c)
+	}
+	return node
+}
+
+// Role returns nodes which are candidates for provided role.
+func (h *Harness) Role(role core.JetRole) []*Node {
+	var nodes []*Node
+	for _, ref := range h.Roles[role] {
+		nodes = append(nodes, h.Nodes[ref])
+	}
+	return nodes
+}
+
+// SetPulse sets new pulse on all nodes in lockstep, in order of roles and candidates.
+func (h *Harness) SetPulse(pulse core.Pulse) error {
+	for role := core.RoleVirtualExecutor; role <= core.RoleHeavyExecutor; role++ {
+		for _, node := range h.Role(role) {
+			err := node.Ledger.GetPulseManager().Set(pulse)
+			if err != nil {
+				return errors.Wrapf(err, "failed to set pulse on node %s", node.Ref)
+			}
+		}
+	}
+	return nil
+}
+
+// NextPulse generates next pulse with seeded entropy and sets it on all nodes.
+func (h *Harness) NextPulse() (*core.Pulse, error) {
+	var current *core.Pulse
+	for _, node := range h.Nodes {
+		var err error
+		current, err = node.Ledger.GetPulseManager().Current()
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if current == nil {
+		return nil, errors.New("harness has no nodes")
+	}
+
+	pulse := core.Pulse{
+		PulseNumber:     current.PulseNumber + pulseDelta,
+		NextPulseNumber: current.PulseNumber + 2*pulseDelta,
+	}
+	h.rand.Read(pulse.Entropy[:])
+	return &pulse, h.SetPulse(pulse)
+}
+
+// Stop stops all nodes and removes their storages.
+func (h *Harness) Stop() {
+	for _, c := range h.components {
+		c.Stop() // nolint: errcheck
+	}
+	for _, cleaner := range h.cleaners {
+		cleaner()
+	}
+}
+
+// logicRunner is a logic runner stub for nodes which do not execute contracts.
+type logicRunner struct{}
+
+func (*logicRunner) Execute(core.Message) (core.Reply, error) {
+	return nil, errors.New("contracts execution is not supported by harness node")
+}
+
+func (*logicRunner) ValidateCaseBind(core.Message) (core.Reply, error) {
+	return nil, errors.New("validation is not supported by harness node")
+}
+
+func (*logicRunner) ProcessValidationResults(core.SignedMessage) (core.Reply, error) {
+	return nil, errors.New("validation is not supported by harness node")
+}
+
+func (*logicRunner) ExecutorResults(core.SignedMessage) (core.Reply, error) {
+	return nil, errors.New("validation is not supported by harness node")
+}
+
+func (*logicRunner) Validate(core.RecordRef, core.Pulse, []core.CaseRecord) (int, error) {
+	return 0, errors.New("validation is not supported by harness node")
+}
+
+func (*logicRunner) OnPulse(core.Pulse) error {
+	return nil
+}
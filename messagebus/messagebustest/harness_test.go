@@ -0,0 +1,113 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebustest
+
+import (
+	"testing"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRoles = map[core.JetRole]Role{
+	core.RoleVirtualExecutor: {Candidates: 1, Actors: 1},
+	core.RoleLightExecutor:   {Candidates: 1, Actors: 1},
+	core.RoleLightValidator:  {Candidates: 3, Actors: 3},
+}
+
+func jetDropDeliveries(deliveries []Delivery) []Delivery {
+	var drops []Delivery
+	for _, d := range deliveries {
+		if d.Type == core.TypeJetDrop {
+			drops = append(drops, d)
+		}
+	}
+	return drops
+}
+
+func TestHarness_NextPulse_ReplicatesJetDrop(t *testing.T) {
+	h := NewHarness(t, 42, testRoles)
+	defer h.Stop()
+
+	_, err := h.NextPulse()
+	require.NoError(t, err)
+
+	drops := jetDropDeliveries(h.Network.Deliveries())
+	assert.Len(t, drops, len(h.Nodes)*len(h.Roles[core.RoleLightValidator]))
+	for _, d := range drops {
+		assert.False(t, d.Dropped)
+		assert.Contains(t, h.Roles[core.RoleLightValidator], d.To)
+	}
+	for _, node := range h.Nodes {
+		pulse, err := node.Ledger.GetPulseManager().Current()
+		require.NoError(t, err)
+		assert.Equal(t, core.FirstPulseNumber+pulseDelta, int(pulse.PulseNumber))
+	}
+}
+
+func TestHarness_IsDeterministic(t *testing.T) {
+	run := func() []Delivery {
+		h := NewHarness(t, 7, testRoles)
+		defer h.Stop()
+		h.Network.SetDropRate(0.3)
+		for i := 0; i < 3; i++ {
+			_, err := h.NextPulse()
+			require.NoError(t, err)
+		}
+		return h.Network.Deliveries()
+	}
+
+	assert.Equal(t, run(), run())
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	h := NewHarness(t, 42, testRoles)
+	defer h.Stop()
+	executor := h.Role(core.RoleVirtualExecutor)[0]
+	validators := h.Roles[core.RoleLightValidator]
+
+	h.Network.Partition([]core.RecordRef{validators[0]})
+	rep, err := executor.Bus.Send(&message.JetDrop{})
+	require.NoError(t, err)
+	aggregated := rep.(*reply.Aggregated)
+	assert.True(t, aggregated.Partial())
+	assert.Contains(t, aggregated.Errors, validators[0])
+
+	h.Network.Heal()
+	rep, err = executor.Bus.Send(&message.JetDrop{})
+	require.NoError(t, err)
+	assert.False(t, rep.(*reply.Aggregated).Partial())
+}
+
+func TestNetwork_DropFilter(t *testing.T) {
+	h := NewHarness(t, 42, testRoles)
+	defer h.Stop()
+	executor := h.Role(core.RoleVirtualExecutor)[0]
+
+	h.Network.SetDropFilter(func(from, to core.RecordRef, msg core.Message) bool {
+		return msg.Type() == core.TypeGetCode
+	})
+	_, err := executor.Bus.Send(&message.GetCode{})
+	assert.Error(t, err)
+
+	h.Network.SetDropFilter(nil)
+	_, err = executor.Bus.Send(&message.GetCode{})
+	assert.Contains(t, err.Error(), "failed to retrieve code record")
+}
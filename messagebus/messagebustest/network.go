@@ -0,0 +1,251 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebustest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/pkg/errors"
+)
+
+// Delivery describes message passed through the simulated network.
+type Delivery struct {
+	From    core.RecordRef
+	To      core.RecordRef
+	Type    core.MessageType
+	Dropped bool
+}
+
+// Network is an in-memory network connecting virtual nodes in one process. Remote procedures are called
+// synchronously in the caller goroutine, so message flow is deterministic for the provided seed.
+type Network struct {
+	lock       sync.Mutex
+	rand       *rand.Rand
+	endpoints  map[core.RecordRef]*Endpoint
+	partitions map[core.RecordRef]int
+	dropRate   float64
+	dropFilter func(from, to core.RecordRef, msg core.Message) bool
+	latency    time.Duration
+	order      func(nodes []core.RecordRef) []core.RecordRef
+	deliveries []Delivery
+}
+
+// NewNetwork creates simulated network. Random drops and default cascade delivery order are derived from seed.
+func NewNetwork(seed int64) *Network {
+	return &Network{
+		rand:       rand.New(rand.NewSource(seed)),
+		endpoints:  map[core.RecordRef]*Endpoint{},
+		partitions: map[core.RecordRef]int{},
+	}
+}
+
+// AddNode connects virtual node with provided PEM encoded public key to the network.
+func (n *Network) AddNode(ref core.RecordRef, publicKey string) *Endpoint {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	endpoint := &Endpoint{
+		network:    n,
+		node:       core.ActiveNode{NodeID: ref, State: core.NodeActive, PublicKey: []byte(publicKey)},
+		procedures: map[string]core.RemoteProcedure{},
+	}
+	n.endpoints[ref] = endpoint
+	return endpoint
+}
+
+// Partition splits network into isolated groups of nodes. Nodes not mentioned in groups form one more group.
+func (n *Network) Partition(groups ...[]core.RecordRef) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.partitions = map[core.RecordRef]int{}
+	for i, group := range groups {
+		for _, node := range group {
+			n.partitions[node] = i + 1
+		}
+	}
+}
+
+// Heal removes all partitions.
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// SetDropRate sets probability of random message drop.
+func (n *Network) SetDropRate(rate float64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.dropRate = rate
+}
+
+// SetDropFilter sets function which decides whether message should be dropped. Signed messages are unwrapped before
+// passing to the filter. Nil filter drops nothing.
+func (n *Network) SetDropFilter(filter func(from, to core.RecordRef, msg core.Message) bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.dropFilter = filter
+}
+
+// SetLatency sets delay of every message delivery.
+func (n *Network) SetLatency(latency time.Duration) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.latency = latency
+}
+
+// SetOrder sets function which defines order of message delivery to cascade nodes. Nil order shuffles nodes with
+// the network seed.
+func (n *Network) SetOrder(order func(nodes []core.RecordRef) []core.RecordRef) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.order = order
+}
+
+// Deliveries returns log of all messages passed through the network.
+func (n *Network) Deliveries() []Delivery {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return append([]Delivery{}, n.deliveries...)
+}
+
+func (n *Network) ordered(nodes []core.RecordRef) []core.RecordRef {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.order != nil {
+		return n.order(nodes)
+	}
+	ordered := append([]core.RecordRef{}, nodes...)
+	n.rand.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+	return ordered
+}
+
+// route decides whether message reaches the target node and logs the delivery.
+func (n *Network) route(from, to core.RecordRef, msg core.Message) (*Endpoint, time.Duration, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if signed, ok := msg.(core.SignedMessage); ok {
+		msg = signed.Message()
+	}
+	target, ok := n.endpoints[to]
+	dropped := !ok ||
+		n.partitions[from] != n.partitions[to] ||
+		(n.dropFilter != nil && n.dropFilter(from, to, msg)) ||
+		n.rand.Float64() < n.dropRate
+	n.deliveries = append(n.deliveries, Delivery{From: from, To: to, Type: msg.Type(), Dropped: dropped})
+
+	if !ok {
+		return nil, 0, errors.Errorf("node %s is not found", to)
+	}
+	if dropped {
+		return nil, 0, errors.Errorf("message to node %s is dropped", to)
+	}
+	return target, n.latency, nil
+}
+
+func (n *Network) call(from, to core.RecordRef, method string, msg core.Message) ([]byte, error) {
+	target, latency, err := n.route(from, to, msg)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(latency)
+
+	procedure, err := target.procedure(method)
+	if err != nil {
+		return nil, err
+	}
+	data, err := message.ToBytes(msg)
+	if err != nil {
+		return nil, err
+	}
+	return procedure([][]byte{data})
+}
+
+// Endpoint is a core.Network implementation of a virtual node.
+type Endpoint struct {
+	network    *Network
+	node       core.ActiveNode
+	lock       sync.RWMutex
+	procedures map[string]core.RemoteProcedure
+}
+
+// SendMessage sends a message.
+func (e *Endpoint) SendMessage(nodeID core.RecordRef, method string, msg core.Message) ([]byte, error) {
+	return e.network.call(e.node.NodeID, nodeID, method, msg)
+}
+
+// SendCascadeMessage sends a message to every cascade node in the network delivery order.
+func (e *Endpoint) SendCascadeMessage(
+	data core.Cascade, method string, msg core.Message,
+) (map[core.RecordRef]core.CascadeResult, error) {
+	results := map[core.RecordRef]core.CascadeResult{}
+	for _, node := range e.network.ordered(data.NodeIds) {
+		res, err := e.network.call(e.node.NodeID, node, method, msg)
+		if err != nil {
+			results[node] = core.CascadeResult{Error: err.Error()}
+			continue
+		}
+		results[node] = core.CascadeResult{Result: res}
+	}
+	return results, nil
+}
+
+// GetAddress returns an origin address.
+func (e *Endpoint) GetAddress() string {
+	return e.node.NodeID.String()
+}
+
+// RemoteProcedureRegister registers procedure for remote call on this node.
+func (e *Endpoint) RemoteProcedureRegister(name string, method core.RemoteProcedure) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.procedures[name] = method
+}
+
+// GetNodeID returns current node id.
+func (e *Endpoint) GetNodeID() core.RecordRef {
+	return e.node.NodeID
+}
+
+// GetActiveNode returns active node by its reference. Returns nil if node is not found.
+func (e *Endpoint) GetActiveNode(ref core.RecordRef) *core.ActiveNode {
+	e.network.lock.Lock()
+	defer e.network.lock.Unlock()
+
+	endpoint, ok := e.network.endpoints[ref]
+	if !ok {
+		return nil
+	}
+	node := endpoint.node
+	return &node
+}
+
+func (e *Endpoint) procedure(name string) (core.RemoteProcedure, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	procedure, ok := e.procedures[name]
+	if !ok {
+		return nil, errors.Errorf("procedure %s is not registered on node %s", name, e.node.NodeID)
+	}
+	return procedure, nil
+}
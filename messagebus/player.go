@@ -0,0 +1,144 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/log"
+	"github.com/pkg/errors"
+)
+
+// Divergence describes replayed message which result differs from the recorded one.
+type Divergence struct {
+	// Index is the position of the record in the session.
+	Index  int
+	Record Record
+	Reply  []byte
+	Error  string
+}
+
+// Player is an offline MessageBus for a single node. Every sent message is handled by locally registered handlers on
+// behalf of the node, so LogicRunner and ledger can reproduce execution of the recorded session without network.
+type Player struct {
+	node     core.RecordRef
+	ledger   core.Ledger
+	handlers map[core.MessageType]core.MessageHandler
+}
+
+// NewPlayer creates Player. Messages sent by local components are handled as sent by node.
+func NewPlayer(node core.RecordRef) *Player {
+	return &Player{
+		node:     node,
+		handlers: map[core.MessageType]core.MessageHandler{},
+	}
+}
+
+// Start implementation of Component interface.
+func (p *Player) Start(c core.Components) error {
+	p.ledger = c.Ledger
+	return nil
+}
+
+// Stop implementation of Component interface.
+func (p *Player) Stop() error { return nil }
+
+// Register sets a function as a handler for particular message type, only one handler per type is allowed.
+func (p *Player) Register(t core.MessageType, handler core.MessageHandler) error {
+	if _, ok := p.handlers[t]; ok {
+		return errors.New("handler for this type already exists")
+	}
+	p.handlers[t] = handler
+	return nil
+}
+
+// MustRegister is a Register wrapper that panics if an error was returned.
+func (p *Player) MustRegister(t core.MessageType, handler core.MessageHandler) {
+	err := p.Register(t, handler)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Send handles message with local handler.
+func (p *Player) Send(msg core.Message) (core.Reply, error) {
+	return p.handle(msg, p.node)
+}
+
+// SendWithOptions handles message with local handler. Options are ignored.
+func (p *Player) SendWithOptions(ctx context.Context, msg core.Message, opts *core.MessageSendOptions) (core.Reply, error) {
+	return p.Send(msg)
+}
+
+// SendAsync handles message with local handler synchronously to keep replay deterministic.
+func (p *Player) SendAsync(msg core.Message) {
+	_, err := p.Send(msg)
+	if err != nil {
+		log.Errorln(err)
+	}
+}
+
+func (p *Player) handle(msg core.Message, sender core.RecordRef) (core.Reply, error) {
+	handler, ok := p.handlers[msg.Type()]
+	if !ok {
+		return nil, errors.Errorf("no handler for %s message", msg.Type())
+	}
+	return handler(&message.SignedMessage{Msg: msg, Sender: sender})
+}
+
+// Play delivers recorded messages to local handlers in order and compares results with the recorded ones. Pulse is
+// switched with the ledger pulse manager when recorded pulse changes. Ledger should be in the same state as the
+// recording node was at the beginning of the session, e.g. both started from empty storage.
+func (p *Player) Play(records []Record) ([]Divergence, error) {
+	pm := p.ledger.GetPulseManager()
+	var divergences []Divergence
+	for i, rec := range records {
+		current, err := pm.Current()
+		if err != nil {
+			return divergences, err
+		}
+		if current.PulseNumber != rec.Pulse.PulseNumber {
+			err = pm.Set(rec.Pulse)
+			if err != nil {
+				return divergences, errors.Wrapf(err, "failed to set pulse %d", rec.Pulse.PulseNumber)
+			}
+		}
+
+		msg, err := message.Deserialize(bytes.NewBuffer(rec.Message))
+		if err != nil {
+			return divergences, errors.Wrapf(err, "failed to decode message of record %d", i)
+		}
+
+		div := Divergence{Index: i, Record: rec}
+		rep, err := p.handle(msg, rec.Sender)
+		if err != nil {
+			div.Error = err.Error()
+		} else if rep != nil {
+			div.Reply, err = replyToBytes(rep)
+			if err != nil {
+				return divergences, errors.Wrapf(err, "failed to encode reply of record %d", i)
+			}
+		}
+		if div.Error != rec.Error || !bytes.Equal(div.Reply, rec.Reply) {
+			divergences = append(divergences, div)
+		}
+	}
+	return divergences, nil
+}
@@ -0,0 +1,122 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"encoding/gob"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/log"
+	"github.com/pkg/errors"
+)
+
+// Record is a delivered message with its reply.
+type Record struct {
+	// Pulse is the current pulse of the node when message was delivered.
+	Pulse  core.Pulse
+	Sender core.RecordRef
+	// Message is encoded with message.Serialize.
+	Message []byte
+	// Reply is encoded with reply.Serialize, it is empty if handler returned an error.
+	Reply []byte
+	Error string
+}
+
+// Recorder is an Interceptor which writes every delivered message and its reply to the stream. Written session can be
+// read with ReadRecords and replayed with Player.
+type Recorder struct {
+	pm core.PulseManager
+
+	lock sync.Mutex
+	enc  *gob.Encoder
+}
+
+// NewRecorder creates Recorder writing records to w. Pulse manager provides pulse of recorded messages.
+func NewRecorder(w io.Writer, pm core.PulseManager) *Recorder {
+	return &Recorder{pm: pm, enc: gob.NewEncoder(w)}
+}
+
+// InterceptSend implementation of Interceptor interface. Sent messages are not recorded.
+func (r *Recorder) InterceptSend(next SendFunc) SendFunc {
+	return next
+}
+
+// InterceptDeliver implementation of Interceptor interface.
+func (r *Recorder) InterceptDeliver(next core.MessageHandler) core.MessageHandler {
+	return func(msg core.SignedMessage) (core.Reply, error) {
+		pulse, err := r.pm.Current()
+		if err != nil {
+			return nil, err
+		}
+		rep, err := next(msg)
+		if recErr := r.record(*pulse, msg, rep, err); recErr != nil {
+			log.Errorf("messagebus: failed to record %s message: %s", msg.Message().Type(), recErr)
+		}
+		return rep, err
+	}
+}
+
+func (r *Recorder) record(pulse core.Pulse, msg core.SignedMessage, rep core.Reply, handleErr error) error {
+	rec := Record{Pulse: pulse, Sender: msg.GetSender()}
+	var err error
+	rec.Message, err = message.ToBytes(msg.Message())
+	if err != nil {
+		return err
+	}
+	if handleErr != nil {
+		rec.Error = handleErr.Error()
+	} else if rep != nil {
+		rec.Reply, err = replyToBytes(rep)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.enc.Encode(&rec)
+}
+
+// ReadRecords reads all records written by Recorder from the stream.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	dec := gob.NewDecoder(r)
+	var records []Record
+	for {
+		var rec Record
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, errors.Wrap(err, "failed to read record")
+		}
+		records = append(records, rec)
+	}
+}
+
+func replyToBytes(rep core.Reply) ([]byte, error) {
+	rd, err := reply.Serialize(rep)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(rd)
+}
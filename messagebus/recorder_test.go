@@ -0,0 +1,99 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Player(t *testing.T) {
+	sender := testutils.RandomRef()
+	receiver := testutils.RandomRef()
+	buses := newTestBuses(t, map[core.JetRole][]core.RecordRef{
+		core.RoleVirtualExecutor: {sender},
+		core.RoleLightExecutor:   {receiver},
+	})
+	pm := buses[receiver].ledger.GetPulseManager()
+	handler := func(msg core.SignedMessage) (core.Reply, error) {
+		if msg.Message().(*message.GetCode).Code.Equal(core.RecordRef{}) {
+			return nil, errors.New("empty code")
+		}
+		return &reply.Code{Code: msg.Message().Target()[:]}, nil
+	}
+	buses[receiver].MustRegister(core.TypeGetCode, handler)
+
+	buf := &bytes.Buffer{}
+	buses[receiver].AddInterceptor(NewRecorder(buf, pm))
+
+	code := testutils.RandomRef()
+	_, err := buses[sender].Send(&message.GetCode{Code: code})
+	require.NoError(t, err)
+	err = pm.Set(core.Pulse{PulseNumber: core.FirstPulseNumber + 1})
+	require.NoError(t, err)
+	_, err = buses[sender].Send(&message.GetCode{})
+	require.Error(t, err)
+
+	records, err := ReadRecords(buf)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, core.PulseNumber(core.FirstPulseNumber), records[0].Pulse.PulseNumber)
+	assert.Equal(t, sender, records[0].Sender)
+	assert.Equal(t, message.MustSerializeBytes(&message.GetCode{Code: code}), records[0].Message)
+	assert.NotEmpty(t, records[0].Reply)
+	assert.Equal(t, core.PulseNumber(core.FirstPulseNumber+1), records[1].Pulse.PulseNumber)
+	assert.Equal(t, "empty code", records[1].Error)
+
+	t.Run("same results", func(t *testing.T) {
+		replayPM := &testPulseManager{pulse: core.Pulse{PulseNumber: core.FirstPulseNumber}}
+		player := NewPlayer(receiver)
+		err := player.Start(core.Components{Ledger: &testLedger{pm: replayPM}})
+		require.NoError(t, err)
+		player.MustRegister(core.TypeGetCode, handler)
+
+		divergences, err := player.Play(records)
+		require.NoError(t, err)
+		assert.Empty(t, divergences)
+		assert.Equal(t, core.PulseNumber(core.FirstPulseNumber+1), replayPM.pulse.PulseNumber)
+	})
+
+	t.Run("diverged results", func(t *testing.T) {
+		player := NewPlayer(receiver)
+		err := player.Start(core.Components{Ledger: &testLedger{
+			pm: &testPulseManager{pulse: core.Pulse{PulseNumber: core.FirstPulseNumber}},
+		}})
+		require.NoError(t, err)
+		player.MustRegister(core.TypeGetCode, func(msg core.SignedMessage) (core.Reply, error) {
+			return &reply.Code{}, nil
+		})
+
+		divergences, err := player.Play(records)
+		require.NoError(t, err)
+		require.Len(t, divergences, 2)
+		assert.Equal(t, 0, divergences[0].Index)
+		assert.Equal(t, 1, divergences[1].Index)
+		assert.Empty(t, divergences[1].Error)
+	})
+}
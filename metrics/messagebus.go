@@ -0,0 +1,53 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MessageBusSendTime is a time of message sending metric
+var MessageBusSendTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:      "send_time_seconds",
+	Help:      "Time of message sending including replies waiting",
+	Namespace: insolarNamespace,
+	Subsystem: "messagebus",
+}, []string{"messageType"})
+
+// MessageBusSendErrorsTotal is total number of failed message sendings metric
+var MessageBusSendErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name:      "send_errors_total",
+	Help:      "Total number of failed message sendings",
+	Namespace: insolarNamespace,
+	Subsystem: "messagebus",
+}, []string{"messageType"})
+
+// MessageBusDeliverTime is a time of delivered message handling metric
+var MessageBusDeliverTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:      "deliver_time_seconds",
+	Help:      "Time of delivered message handling",
+	Namespace: insolarNamespace,
+	Subsystem: "messagebus",
+}, []string{"messageType"})
+
+// MessageBusDeliverErrorsTotal is total number of failed delivered message handlings metric
+var MessageBusDeliverErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name:      "deliver_errors_total",
+	Help:      "Total number of failed delivered message handlings",
+	Namespace: insolarNamespace,
+	Subsystem: "messagebus",
+}, []string{"messageType"})
@@ -56,6 +56,11 @@ func NewMetrics(cfg configuration.Metrics) (*Metrics, error) {
 	m.registry.MustRegister(NetworkFutures)
 	m.registry.MustRegister(NetworkPacketSentTotal)
 	m.registry.MustRegister(NetworkPacketReceivedTotal)
+	m.registry.MustRegister(MessageBusSendTime)
+	m.registry.MustRegister(MessageBusSendErrorsTotal)
+	m.registry.MustRegister(MessageBusDeliverTime)
+	m.registry.MustRegister(MessageBusDeliverErrorsTotal)
+	m.registry.MustRegister(LogicRunnerValidationVerdictsTotal)
 
 	return &m, nil
 }
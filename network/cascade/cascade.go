@@ -35,11 +35,13 @@ func min(a, b int) int {
 
 // Cascade is struct to hold callback that sends cascade messages to next layers of cascade
 type Cascade struct {
-	SendMessage func(data core.Cascade, method string, args [][]byte) error
+	SendMessage func(data core.Cascade, method string, args [][]byte) (map[core.RecordRef]core.CascadeResult, error)
 }
 
-// SendToNextLayer sends data to callback.
-func (casc *Cascade) SendToNextLayer(data core.Cascade, method string, args [][]byte) error {
+// SendToNextLayer sends data to callback and returns results of all nodes of the next layers.
+func (casc *Cascade) SendToNextLayer(
+	data core.Cascade, method string, args [][]byte,
+) (map[core.RecordRef]core.CascadeResult, error) {
 	return casc.SendMessage(data, method, args)
 }
 
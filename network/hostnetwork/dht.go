@@ -27,7 +27,6 @@ import (
 
 	"github.com/huandu/xstrings"
 	"github.com/insolar/insolar/core"
-	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/log"
 	"github.com/insolar/insolar/metrics"
 	"github.com/insolar/insolar/network/consensus"
@@ -823,19 +822,6 @@ func (dht *DHT) handlePackets(start, stop chan bool) {
 func (dht *DHT) dispatchPacketType(ctx hosthandler.Context, msg *packet.Packet, ht *routing.HashTable) {
 	packetBuilder := packet.NewBuilder().Sender(ht.Origin).Receiver(msg.Sender).Type(msg.Type)
 
-	if msg.Type == packet.TypeRPC {
-		data := msg.Data.(*packet.RequestDataRPC)
-		signedMsg, err := message.Deserialize(bytes.NewBuffer(data.Args[0]))
-		if err != nil {
-			log.Error(err, "failed to parse incoming RPC")
-			return
-		}
-		if !message.SignIsCorrect(signedMsg, dht.GetNetworkCommonFacade().GetSignHandler().GetPrivateKey()) {
-			log.Warn("RPC message not signed")
-			return
-		}
-	}
-
 	response, err := ParseIncomingPacket(dht, ctx, msg, packetBuilder)
 	if err != nil {
 		log.Errorln(err)
@@ -995,7 +981,9 @@ func (dht *DHT) ConfirmNodeRole(roleKey string) bool {
 }
 
 // CascadeSendMessage sends a message to the next cascade layer.
-func (dht *DHT) CascadeSendMessage(data core.Cascade, targetID string, method string, args [][]byte) error {
+func (dht *DHT) CascadeSendMessage(
+	data core.Cascade, targetID string, method string, args [][]byte,
+) (map[core.RecordRef]core.CascadeResult, error) {
 	return CascadeSendMessage(dht, data, targetID, method, args)
 }
 
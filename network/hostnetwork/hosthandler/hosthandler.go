@@ -121,7 +121,7 @@ type HostHandler interface {
 	FindHost(ctx Context, targetID string) (*host.Host, bool, error)
 	RemoteProcedureRegister(name string, method core.RemoteProcedure)
 	InvokeRPC(sender *host.Host, method string, args [][]byte) ([]byte, error)
-	CascadeSendMessage(data core.Cascade, targetID string, method string, args [][]byte) error
+	CascadeSendMessage(data core.Cascade, targetID string, method string, args [][]byte) (map[core.RecordRef]core.CascadeResult, error)
 	Store(key store.Key, data []byte, replication time.Time, expiration time.Time, publisher bool) error
 	RemoteProcedureCall(ctx Context, targetID string, method string, args [][]byte) (result []byte, err error)
 
@@ -68,6 +68,8 @@ type ResponseDataRPC struct {
 type ResponseCascadeSend struct {
 	Success bool
 	Error   string
+	// Results contains results of the responding node and all nodes of its cascade subtree.
+	Results map[core.RecordRef]core.CascadeResult
 }
 
 // ResponsePulse is the response for a new pulse from a pulsar
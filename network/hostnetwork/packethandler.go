@@ -425,19 +425,26 @@ func processCascadeSend(hostHandler hosthandler.HostHandler, ctx hosthandler.Con
 	data := msg.Data.(*packet.RequestCascadeSend)
 
 	hostHandler.AddHost(ctx, routing.NewRouteHost(msg.Sender))
-	_, err := hostHandler.InvokeRPC(msg.Sender, data.RPC.Method, data.RPC.Args)
+	result, err := hostHandler.InvokeRPC(msg.Sender, data.RPC.Method, data.RPC.Args)
 	response := &packet.ResponseCascadeSend{
 		Success: true,
 		Error:   "",
 	}
+	own := core.CascadeResult{Result: result}
 	if err != nil {
 		response.Success = false
 		response.Error = err.Error()
+		own.Error = err.Error()
 	}
-	err = hostHandler.GetNetworkCommonFacade().GetCascade().SendToNextLayer(data.Data, data.RPC.Method, data.RPC.Args)
+	results, err := hostHandler.GetNetworkCommonFacade().GetCascade().SendToNextLayer(data.Data, data.RPC.Method, data.RPC.Args)
 	if err != nil {
-		log.Debug("failed to send message to next cascade layer")
+		log.Debug("failed to send message to next cascade layer: ", err)
 	}
+	if results == nil {
+		results = map[core.RecordRef]core.CascadeResult{}
+	}
+	results[hostHandler.GetNodeID()] = own
+	response.Results = results
 
-	return packetBuilder.Response(response).Build(), err
+	return packetBuilder.Response(response).Build(), nil
 }
@@ -46,8 +46,8 @@ type mockNetworkCommonFacade struct {
 
 func newMockNetworkCommonFacade() hosthandler.NetworkCommonFacade {
 	var c cascade.Cascade
-	c.SendMessage = func(data core.Cascade, method string, args [][]byte) error {
-		return nil
+	c.SendMessage = func(data core.Cascade, method string, args [][]byte) (map[core.RecordRef]core.CascadeResult, error) {
+		return nil, nil
 	}
 	return &mockNetworkCommonFacade{
 		cascade: &c,
@@ -157,8 +157,10 @@ func (hh *mockHostHandler) StoreRetrieve(key store.Key) ([]byte, bool) {
 	return nil, false
 }
 
-func (hh *mockHostHandler) CascadeSendMessage(data core.Cascade, targetID string, method string, args [][]byte) error {
-	return nil
+func (hh *mockHostHandler) CascadeSendMessage(
+	data core.Cascade, targetID string, method string, args [][]byte,
+) (map[core.RecordRef]core.CascadeResult, error) {
+	return nil, nil
 }
 
 func (hh *mockHostHandler) GetActiveNodesList() []*core.ActiveNode {
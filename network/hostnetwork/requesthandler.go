@@ -203,18 +203,20 @@ func RelayOwnershipRequest(hostHandler hosthandler.HostHandler, targetID string)
 	return checkResponse(hostHandler, future, targetID, request)
 }
 
-// CascadeSendMessage sends a message to the next cascade layer.
-func CascadeSendMessage(hostHandler hosthandler.HostHandler, data core.Cascade, targetID string, method string, args [][]byte) error {
+// CascadeSendMessage sends a message to the next cascade layer and returns results of target host's cascade subtree.
+func CascadeSendMessage(
+	hostHandler hosthandler.HostHandler, data core.Cascade, targetID string, method string, args [][]byte,
+) (map[core.RecordRef]core.CascadeResult, error) {
 	ctx, err := NewContextBuilder(hostHandler).SetDefaultHost().Build()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	targetHost, exist, err := hostHandler.FindHost(ctx, targetID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !exist {
-		return errors.New("cascadeSendMessage: couldn't find a target host")
+		return nil, errors.New("cascadeSendMessage: couldn't find a target host")
 	}
 
 	request := packet.NewBuilder().Sender(hostHandler.HtFromCtx(ctx).Origin).Receiver(targetHost).Type(packet.TypeCascadeSend).
@@ -228,10 +230,21 @@ func CascadeSendMessage(hostHandler hosthandler.HostHandler, data core.Cascade,
 
 	future, err := hostHandler.SendRequest(request)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return checkResponse(hostHandler, future, targetID, request)
+	rsp, err := future.GetResult(hostHandler.GetPacketTimeout())
+	if err != nil {
+		return nil, errors.Wrap(err, "cascadeSendMessage: failed to get response")
+	}
+	response, ok := rsp.Data.(*packet.ResponseCascadeSend)
+	if !ok {
+		return nil, errors.New("cascadeSendMessage: unexpected response type")
+	}
+	if response.Results == nil && !response.Success {
+		return nil, errors.New(response.Error)
+	}
+	return response.Results, nil
 }
 
 func CheckPublicKeyRequest(hostHandler hosthandler.HostHandler, targetID string) error {
@@ -122,7 +122,7 @@ func TestRelayOwnershipRequest(t *testing.T) {
 func TestCascadeSendMessage(t *testing.T) {
 	hh := newMockHostHandler()
 	data := core.Cascade{}
-	err := CascadeSendMessage(hh, data, "", "", nil)
+	_, err := CascadeSendMessage(hh, data, "", "", nil)
 	assert.NotNil(t, err)
 }
 
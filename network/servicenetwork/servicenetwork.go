@@ -18,7 +18,6 @@ package servicenetwork
 
 import (
 	"io/ioutil"
-	"strings"
 	"time"
 
 	"github.com/insolar/insolar/configuration"
@@ -56,7 +55,7 @@ func NewServiceNetwork(conf configuration.Configuration) (*ServiceNetwork, error
 	}
 
 	service := &ServiceNetwork{nodeNetwork: node, hostNetwork: dht}
-	f := func(data core.Cascade, method string, args [][]byte) error {
+	f := func(data core.Cascade, method string, args [][]byte) (map[core.RecordRef]core.CascadeResult, error) {
 		return service.initCascadeSendMessage(data, true, method, args)
 	}
 	cascade1.SendMessage = f
@@ -73,6 +72,16 @@ func (network *ServiceNetwork) GetNodeID() core.RecordRef {
 	return network.nodeNetwork.GetID()
 }
 
+// GetActiveNode returns active node by its reference. Returns nil if node is not found.
+func (network *ServiceNetwork) GetActiveNode(ref core.RecordRef) *core.ActiveNode {
+	for _, node := range network.hostNetwork.GetActiveNodesList() {
+		if node.NodeID == ref {
+			return node
+		}
+	}
+	return nil
+}
+
 // SendMessage sends a message from MessageBus.
 func (network *ServiceNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Message) ([]byte, error) {
 	start := time.Now()
@@ -80,17 +89,13 @@ func (network *ServiceNetwork) SendMessage(nodeID core.RecordRef, method string,
 		return nil, errors.New("message is nil")
 	}
 	hostID := nodenetwork.ResolveHostID(nodeID)
-	err := message.SignMessage(msg, network.nodeNetwork.GetPrivateKey())
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to sign a message")
-	}
 	buff, err := messageToBytes(msg)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to serialize event")
 	}
 
 	log.Debugf("SendMessage with nodeID = %s method = %s, message reference = %s", nodeID.String(),
-		method, msg.Target().String())
+		method, msg.Target())
 
 	metrics.NetworkMessageSentTotal.Inc()
 	res, err := network.hostNetwork.RemoteProcedureCall(createContext(network.hostNetwork), hostID, method, [][]byte{buff})
@@ -98,18 +103,17 @@ func (network *ServiceNetwork) SendMessage(nodeID core.RecordRef, method string,
 	return res, err
 }
 
-// SendCascadeMessage sends a message from MessageBus to a cascade of nodes. Message reference is ignored
-func (network *ServiceNetwork) SendCascadeMessage(data core.Cascade, method string, msg core.Message) error {
+// SendCascadeMessage sends a message from MessageBus to a cascade of nodes. Message reference is ignored.
+// Returns results of all reached nodes of the cascade.
+func (network *ServiceNetwork) SendCascadeMessage(
+	data core.Cascade, method string, msg core.Message,
+) (map[core.RecordRef]core.CascadeResult, error) {
 	if msg == nil {
-		return errors.New("message is nil")
-	}
-	err := message.SignMessage(msg, network.nodeNetwork.GetPrivateKey())
-	if err != nil {
-		return errors.Wrap(err, "failed to sign a message")
+		return nil, errors.New("message is nil")
 	}
 	buff, err := messageToBytes(msg)
 	if err != nil {
-		return errors.Wrap(err, "Failed to serialize event")
+		return nil, errors.Wrap(err, "Failed to serialize event")
 	}
 
 	return network.initCascadeSendMessage(data, false, method, [][]byte{buff})
@@ -192,13 +196,16 @@ func createContext(handler hosthandler.HostHandler) hosthandler.Context {
 	return ctx
 }
 
-// initCascadeSendMessage initiates the RPC call on target host and sends messages to next cascade layers
-func (network *ServiceNetwork) initCascadeSendMessage(data core.Cascade, findCurrentNode bool, method string, args [][]byte) error {
+// initCascadeSendMessage initiates the RPC call on target host and sends messages to next cascade layers.
+// Results of next layers are collected, unreachable nodes are reported with delivery error.
+func (network *ServiceNetwork) initCascadeSendMessage(
+	data core.Cascade, findCurrentNode bool, method string, args [][]byte,
+) (map[core.RecordRef]core.CascadeResult, error) {
 	if len(data.NodeIds) == 0 {
-		return errors.New("node IDs list should not be empty")
+		return nil, errors.New("node IDs list should not be empty")
 	}
 	if data.ReplicationFactor == 0 {
-		return errors.New("replication factor should not be zero")
+		return nil, errors.New("replication factor should not be zero")
 	}
 
 	var nextNodes []core.RecordRef
@@ -211,27 +218,24 @@ func (network *ServiceNetwork) initCascadeSendMessage(data core.Cascade, findCur
 		nextNodes, err = cascade.CalculateNextNodes(data, nil)
 	}
 	if err != nil {
-		return errors.Wrap(err, "Failed to CalculateNextNodes")
-	}
-	if len(nextNodes) == 0 {
-		return nil
+		return nil, errors.Wrap(err, "Failed to CalculateNextNodes")
 	}
 
-	var failedNodes []string
+	results := map[core.RecordRef]core.CascadeResult{}
 	for _, nextNode := range nextNodes {
 		hostID := nodenetwork.ResolveHostID(nextNode)
-		err = network.hostNetwork.CascadeSendMessage(data, hostID, method, args)
+		nodeResults, err := network.hostNetwork.CascadeSendMessage(data, hostID, method, args)
 		if err != nil {
 			log.Debugln("failed to send cascade message: ", err)
-			failedNodes = append(failedNodes, nextNode.String())
+			results[nextNode] = core.CascadeResult{Error: "failed to send cascade message: " + err.Error()}
+			continue
+		}
+		for node, res := range nodeResults {
+			results[node] = res
 		}
 	}
 
-	if len(failedNodes) > 0 {
-		return errors.New("failed to send cascade message to nodes: " + strings.Join(failedNodes, ", "))
-	}
-
-	return nil
+	return results, nil
 }
 
 // ToBytes deserialize a core.Message to bytes.
@@ -227,14 +227,14 @@ func TestServiceNetwork_SendCascadeMessage(t *testing.T) {
 
 	assert.True(t, success)
 
-	err := firstNode.SendCascadeMessage(c, "test", nil)
+	_, err := firstNode.SendCascadeMessage(c, "test", nil)
 	assert.NotNil(t, err)
 	c.ReplicationFactor = 0
-	err = firstNode.SendCascadeMessage(c, "test", e)
+	_, err = firstNode.SendCascadeMessage(c, "test", e)
 	assert.NotNil(t, err)
 	c.ReplicationFactor = 2
 	c.NodeIds = nil
-	err = firstNode.SendCascadeMessage(c, "test", e)
+	_, err = firstNode.SendCascadeMessage(c, "test", e)
 	assert.NotNil(t, err)
 }
 
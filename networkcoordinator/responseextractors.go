@@ -18,6 +18,7 @@ package networkcoordinator
 
 import (
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/logicrunner/goplugin/foundation"
 	"github.com/pkg/errors"
 )
 
@@ -39,9 +40,13 @@ func extractAuthorizeResponse(data []byte) (string, core.NodeRole, error) {
 
 func extractRegisterNodeResponse(data []byte) (*core.RecordRef, error) {
 	var nodeRef core.RecordRef
-	_, err := core.UnMarshalResponse(data, []interface{}{&nodeRef})
+	var fErr *foundation.Error
+	_, err := core.UnMarshalResponse(data, []interface{}{&nodeRef, &fErr})
 	if err != nil {
 		return nil, errors.Wrap(err, "[ extractRegisterNodeResponse ]")
 	}
+	if fErr != nil {
+		return nil, errors.Wrap(fErr, "[ extractRegisterNodeResponse ]")
+	}
 	return &nodeRef, nil
 }